@@ -1,17 +1,34 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/tui"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+	"go.uber.org/zap"
 )
 
 func main() {
+	debug := flag.Bool("debug", false, "trace every request to Jenkins in ~/.jenkins-tui/logs/jenkins-tui.log")
+	flag.Parse()
+
+	// Log to the log file only: the terminal belongs to the UI
+	level := utils.InfoLevel
+	if *debug {
+		level = utils.DebugLevel
+	}
+	logger := utils.InitLogger(level)
+	defer logger.Sync()
+	logger.Info("starting", zap.Bool("debug", *debug))
+
 	// Create a new instance of our application
 	app, err := tui.New()
 	if err != nil {
+		logger.Error("failed to create application", zap.Error(err))
+		logger.Sync()
 		fmt.Println("Error creating application:", err)
 		os.Exit(1)
 	}
@@ -24,6 +41,8 @@ func main() {
 
 	// Start the program
 	if _, err := program.Run(); err != nil {
+		logger.Error("failed to run program", zap.Error(err))
+		logger.Sync()
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -53,6 +54,12 @@ func GetStatusFromResult(result string, building bool) JobStatus {
 
 // NewClient creates a new JenkinsClient with the given config
 func NewClient(configPath string) (*JenkinsClient, error) {
+	return NewServerClient(configPath, "")
+}
+
+// NewServerClient creates a new JenkinsClient for the named server of the
+// given config, or for its current server if name is empty
+func NewServerClient(configPath, name string) (*JenkinsClient, error) {
 	if configPath == "" {
 		// Default to ~/.jenkins-cli.yaml if no config file is provided
 		homeDir, err := os.UserHomeDir()
@@ -73,17 +80,20 @@ func NewClient(configPath string) (*JenkinsClient, error) {
 		return nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
 
-	// Find the current server configuration
+	// Find the server configuration
+	if name == "" {
+		name = configFile.Current
+	}
 	var serverConfig *JenkinsConfig
 	for i, server := range configFile.JenkinsServers {
-		if server.Name == configFile.Current {
+		if server.Name == name {
 			serverConfig = &configFile.JenkinsServers[i]
 			break
 		}
 	}
 
 	if serverConfig == nil {
-		return nil, fmt.Errorf("no Jenkins server found in config")
+		return nil, fmt.Errorf("no Jenkins server %q found in config", name)
 	}
 
 	// Create an HTTP client with the appropriate settings
@@ -105,8 +115,10 @@ func NewClient(configPath string) (*JenkinsClient, error) {
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
+	// Requests report how they went, for the state of the connection
+	observed := &observedTransport{next: transport}
 	client := &http.Client{
-		Transport: transport,
+		Transport: observed,
 		Timeout:   30 * time.Second,
 	}
 
@@ -114,12 +126,13 @@ func NewClient(configPath string) (*JenkinsClient, error) {
 		client:     client,
 		config:     serverConfig,
 		configPath: configPath,
+		transport:  observed,
 	}, nil
 }
 
 func (c *JenkinsClient) GetNodes(ctx context.Context) ([]Node, error) {
 
-	apiURL := fmt.Sprintf("%s/api/json?tree=computer[displayName,description,numExecutors,offline,idle]", c.config.URL)
+	apiURL := fmt.Sprintf("%s/computer/api/json?tree=computer[displayName,description,numExecutors,offline,idle]", c.config.URL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
@@ -172,6 +185,98 @@ func (c *JenkinsClient) GetNodes(ctx context.Context) ([]Node, error) {
 
 }
 
+// GetExecutorUsage retrieves how many build executors are busy across all nodes
+func (c *JenkinsClient) GetExecutorUsage(ctx context.Context) (*ExecutorUsage, error) {
+	// Lock to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	apiURL := fmt.Sprintf("%s/computer/api/json?tree=busyExecutors,totalExecutors", c.config.URL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executors: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var usageResponse struct {
+		BusyExecutors  int `json:"busyExecutors"`
+		TotalExecutors int `json:"totalExecutors"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&usageResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &ExecutorUsage{Busy: usageResponse.BusyExecutors, Total: usageResponse.TotalExecutors}, nil
+}
+
+// GetQueue retrieves the builds waiting in the build queue
+func (c *JenkinsClient) GetQueue(ctx context.Context) ([]QueueItem, error) {
+	// Lock to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	apiURL := fmt.Sprintf("%s/queue/api/json?tree=items[task[name],why,inQueueSince,stuck,blocked]", c.config.URL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var queueResponse struct {
+		Items []struct {
+			Task struct {
+				Name string `json:"name"`
+			} `json:"task"`
+			Why          string `json:"why"`
+			InQueueSince int64  `json:"inQueueSince"`
+			Stuck        bool   `json:"stuck"`
+			Blocked      bool   `json:"blocked"`
+		} `json:"items"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&queueResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	var queue []QueueItem
+	for _, item := range queueResponse.Items {
+		queue = append(queue, QueueItem{
+			JobName:      item.Task.Name,
+			Why:          item.Why,
+			InQueueSince: item.InQueueSince,
+			Stuck:        item.Stuck,
+			Blocked:      item.Blocked,
+		})
+	}
+
+	return queue, nil
+}
+
 // GetServerInfo retrieves information about the Jenkins server
 func (c *JenkinsClient) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
 	// Lock to ensure thread safety
@@ -225,14 +330,81 @@ func (c *JenkinsClient) GetServerInfo(ctx context.Context) (*ServerInfo, error)
 	return serverInfo, nil
 }
 
-// GetJobs retrieves a list of all jobs from the Jenkins server
+// jobFields are the fields of a job requested when listing jobs
+const jobFields = "name,url,color,description,healthReport[score],property[parameterDefinitions[name]]," +
+	"lastBuild[number,url,building,result,timestamp,duration,estimatedDuration]," +
+	"lastSuccessfulBuild[number,url,result,timestamp,duration]," +
+	"lastFailedBuild[number,url,result,timestamp,duration]"
+
+// jobData is a job as listed by the Jenkins API; folders list their jobs
+type jobData struct {
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	Color               string     `json:"color"`
+	Description         string     `json:"description"`
+	Class               string     `json:"_class"`
+	LastBuild           *buildData `json:"lastBuild"`
+	LastSuccessfulBuild *buildData `json:"lastSuccessfulBuild"`
+	LastFailedBuild     *buildData `json:"lastFailedBuild"`
+	HealthReport        []struct {
+		Score int `json:"score"`
+	} `json:"healthReport"`
+	Property []struct {
+		ParameterDefinitions []struct {
+			Name string `json:"name"`
+		} `json:"parameterDefinitions"`
+	} `json:"property"`
+	Jobs []jobData `json:"jobs"` // nil unless the job is a folder
+}
+
+// buildData is a build as listed by the Jenkins API
+type buildData struct {
+	Number            int    `json:"number"`
+	URL               string `json:"url"`
+	Building          bool   `json:"building"`
+	Result            string `json:"result"`
+	Timestamp         int64  `json:"timestamp"`
+	Duration          int64  `json:"duration"`
+	EstimatedDuration int64  `json:"estimatedDuration"`
+}
+
+// build converts the build to our model, or returns nil if there is none
+func (b *buildData) build() *Build {
+	if b == nil {
+		return nil
+	}
+	return &Build{
+		Number:            b.Number,
+		URL:               b.URL,
+		Status:            string(GetStatusFromResult(b.Result, b.Building)),
+		StartTime:         b.Timestamp,
+		Duration:          b.Duration,
+		Result:            b.Result,
+		Building:          b.Building,
+		EstimatedDuration: b.EstimatedDuration,
+	}
+}
+
+// jobPath turns a job's full name into its URL path below /job/, e.g.
+// "team/app" into "team/job/app"
+func jobPath(jobName string) string {
+	segments := strings.Split(jobName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/job/")
+}
+
+// GetJobs retrieves all jobs from Jenkins. The jobs in folders, up to two
+// levels deep, are listed under their full names instead of the folders.
 func (c *JenkinsClient) GetJobs(ctx context.Context) ([]Job, error) {
 	// Lock to ensure thread safety
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	// Create API URL for jobs
-	apiURL := fmt.Sprintf("%s/api/json?tree=jobs[name,url,color,description]", c.config.URL)
+	tree := fmt.Sprintf("jobs[%s,jobs[%s,jobs[%s]]]", jobFields, jobFields, jobFields)
+	apiURL := fmt.Sprintf("%s/api/json?tree=%s", c.config.URL, url.QueryEscape(tree))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
@@ -257,47 +429,71 @@ func (c *JenkinsClient) GetJobs(ctx context.Context) ([]Job, error) {
 	}
 
 	var jobsResponse struct {
-		Jobs []struct {
-			Name        string `json:"name"`
-			URL         string `json:"url"`
-			Color       string `json:"color"`
-			Description string `json:"description"`
-			Class       string `json:"_class"`
-		} `json:"jobs"`
+		Jobs []jobData `json:"jobs"`
 	}
 
 	if err := json.Unmarshal(bodyBytes, &jobsResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %v", err)
 	}
 
-	// Convert the Jenkins API jobs to our model
+	return flattenJobs("", jobsResponse.Jobs), nil
+}
+
+// flattenJobs converts the Jenkins API jobs to our model, replacing folders
+// by the jobs in them
+func flattenJobs(folder string, items []jobData) []Job {
 	var jobs []Job
-	for _, jobData := range jobsResponse.Jobs {
+	for _, item := range items {
+		name := item.Name
+		if folder != "" {
+			name = folder + "/" + name
+		}
+		if item.Jobs != nil {
+			jobs = append(jobs, flattenJobs(name, item.Jobs)...)
+			continue
+		}
+
 		job := Job{
-			Name:        jobData.Name,
-			URL:         jobData.URL,
-			Class:       jobData.Class,
-			Color:       jobData.Color,
-			Description: jobData.Description,
+			Name:        name,
+			URL:         item.URL,
+			Class:       item.Class,
+			Color:       item.Color,
+			Description: item.Description,
+			Health:      -1,
+			LastBuild:   item.LastBuild.build(),
+			LastSuccess: item.LastSuccessfulBuild.build(),
+			LastFailure: item.LastFailedBuild.build(),
+		}
+
+		// The worst health report sets the score
+		for _, report := range item.HealthReport {
+			if job.Health < 0 || report.Score < job.Health {
+				job.Health = report.Score
+			}
+		}
+		for _, property := range item.Property {
+			for _, definition := range property.ParameterDefinitions {
+				job.Parameters = append(job.Parameters, definition.Name)
+			}
 		}
 
 		// Determine the job status based on the color
-		switch jobData.Color {
+		switch item.Color {
 		case "blue", "blue_anime":
 			job.Status = "success"
-			job.InProgress = jobData.Color == "blue_anime"
+			job.InProgress = item.Color == "blue_anime"
 		case "red", "red_anime":
 			job.Status = "failure"
-			job.InProgress = jobData.Color == "red_anime"
+			job.InProgress = item.Color == "red_anime"
 		case "yellow", "yellow_anime":
 			job.Status = "unstable"
-			job.InProgress = jobData.Color == "yellow_anime"
+			job.InProgress = item.Color == "yellow_anime"
 		case "grey", "grey_anime", "disabled", "disabled_anime":
 			job.Status = "disabled"
-			job.InProgress = jobData.Color == "grey_anime" || jobData.Color == "disabled_anime"
+			job.InProgress = item.Color == "grey_anime" || item.Color == "disabled_anime"
 		case "aborted", "aborted_anime":
 			job.Status = "aborted"
-			job.InProgress = jobData.Color == "aborted_anime"
+			job.InProgress = item.Color == "aborted_anime"
 		default:
 			job.Status = "unknown"
 			job.InProgress = false
@@ -306,7 +502,7 @@ func (c *JenkinsClient) GetJobs(ctx context.Context) ([]Job, error) {
 		jobs = append(jobs, job)
 	}
 
-	return jobs, nil
+	return jobs
 }
 
 // GetJobDetails retrieves detailed information about a specific job
@@ -316,7 +512,7 @@ func (c *JenkinsClient) GetJobDetails(ctx context.Context, jobName string) (*Job
 	defer c.mutex.Unlock()
 
 	// URL encode the job name
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
 	// Create API URL for job details
 	apiURL := fmt.Sprintf("%s/job/%s/api/json?depth=1", c.config.URL, encodedJobName)
@@ -344,15 +540,12 @@ func (c *JenkinsClient) GetJobDetails(ctx context.Context, jobName string) (*Job
 	}
 
 	var jobDetails struct {
-		Name        string `json:"name"`
-		URL         string `json:"url"`
-		Description string `json:"description"`
-		Buildable   bool   `json:"buildable"`
-		Builds      []struct {
-			Number int    `json:"number"`
-			URL    string `json:"url"`
-		} `json:"builds"`
-		LastBuild *struct {
+		Name        string      `json:"name"`
+		URL         string      `json:"url"`
+		Description string      `json:"description"`
+		Buildable   bool        `json:"buildable"`
+		Builds      []buildJSON `json:"builds"`
+		LastBuild   *struct {
 			Number int    `json:"number"`
 			URL    string `json:"url"`
 		} `json:"lastBuild"`
@@ -373,10 +566,7 @@ func (c *JenkinsClient) GetJobDetails(ctx context.Context, jobName string) (*Job
 
 	// Add builds
 	for _, build := range jobDetails.Builds {
-		job.Builds = append(job.Builds, Build{
-			Number: build.Number,
-			URL:    build.URL,
-		})
+		job.Builds = append(job.Builds, build.build())
 	}
 
 	// Set the last build info if available
@@ -390,6 +580,102 @@ func (c *JenkinsClient) GetJobDetails(ctx context.Context, jobName string) (*Job
 	return job, nil
 }
 
+// buildJSON is a build as listed by the JSON API
+type buildJSON struct {
+	Number            int    `json:"number"`
+	URL               string `json:"url"`
+	Building          bool   `json:"building"`
+	Result            string `json:"result"`
+	Timestamp         int64  `json:"timestamp"`
+	Duration          int64  `json:"duration"`
+	EstimatedDuration int64  `json:"estimatedDuration"`
+	Actions           []struct {
+		Causes []struct {
+			Class string `json:"_class"`
+		} `json:"causes"`
+		LastBuiltRevision *struct {
+			SHA1 string `json:"SHA1"`
+		} `json:"lastBuiltRevision"`
+	} `json:"actions"`
+}
+
+// buildTree selects the fields of buildJSON in a tree query
+const buildTree = "number,url,building,result,timestamp,duration,estimatedDuration,actions[causes[_class],lastBuiltRevision[SHA1]]"
+
+// build converts a listed build
+func (b buildJSON) build() Build {
+	build := Build{
+		Number:            b.Number,
+		URL:               b.URL,
+		Status:            string(GetStatusFromResult(b.Result, b.Building)),
+		StartTime:         b.Timestamp,
+		Duration:          b.Duration,
+		Result:            b.Result,
+		Building:          b.Building,
+		EstimatedDuration: b.EstimatedDuration,
+	}
+
+	// The Git plugin records the revision, the Rebuilder and Pipeline
+	// replay plugins the cause of a rebuild
+	for _, action := range b.Actions {
+		if action.LastBuiltRevision != nil {
+			build.Commit = action.LastBuiltRevision.SHA1
+		}
+		for _, cause := range action.Causes {
+			if strings.Contains(cause.Class, "RebuildCause") || strings.Contains(cause.Class, "ReplayCause") {
+				build.Rebuild = true
+			}
+		}
+	}
+	return build
+}
+
+// GetBuildHistory retrieves the builds of a job from index from up to index
+// to, most recent first. Unlike the builds listed with the job details,
+// which Jenkins limits to the last 100, these reach back to the first build.
+func (c *JenkinsClient) GetBuildHistory(ctx context.Context, jobName string, from, to int) ([]Build, error) {
+	// Lock to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// URL encode the job name
+	encodedJobName := jobPath(jobName)
+
+	// Create API URL for a page of the build history
+	tree := fmt.Sprintf("allBuilds[%s]{%d,%d}", buildTree, from, to)
+	apiURL := fmt.Sprintf("%s/job/%s/api/json?tree=%s", c.config.URL, encodedJobName, url.QueryEscape(tree))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build history: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var history struct {
+		AllBuilds []buildJSON `json:"allBuilds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	builds := make([]Build, len(history.AllBuilds))
+	for i, build := range history.AllBuilds {
+		builds[i] = build.build()
+	}
+	return builds, nil
+}
+
 // GetBuildDetails retrieves details about a specific build
 func (c *JenkinsClient) GetBuildDetails(ctx context.Context, jobName string, buildNumber int) (*BuildDetail, error) {
 	// Lock to ensure thread safety
@@ -397,7 +683,7 @@ func (c *JenkinsClient) GetBuildDetails(ctx context.Context, jobName string, bui
 	defer c.mutex.Unlock()
 
 	// URL encode the job name
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
 	// Create API URL for build details
 	apiURL := fmt.Sprintf("%s/job/%s/%d/api/json", c.config.URL, encodedJobName, buildNumber)
@@ -479,7 +765,7 @@ func (c *JenkinsClient) GetBuildLog(ctx context.Context, jobName string, buildNu
 	defer c.mutex.Unlock()
 
 	// URL encode the job name
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
 	// Create API URL for build log
 	apiURL := fmt.Sprintf("%s/job/%s/%d/consoleText", c.config.URL, encodedJobName, buildNumber)
@@ -509,20 +795,72 @@ func (c *JenkinsClient) GetBuildLog(ctx context.Context, jobName string, buildNu
 	return string(bodyBytes), nil
 }
 
-// TriggerBuild starts a build for a specific job
+// GetBuildLogRange retrieves the console output of a build from byte offset
+// start of its log on the server to the end of what has been written so
+// far. The log on the server holds annotations that are stripped from the
+// text, so offsets into it are not offsets into the text: the offset to
+// continue from is the one the server reports, never start plus the length
+// of the text.
+func (c *JenkinsClient) GetBuildLogRange(ctx context.Context, jobName string, buildNumber int, start int64) (*LogChunk, error) {
+	// Lock to ensure thread safety
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// URL encode the job name
+	encodedJobName := jobPath(jobName)
+
+	// The progressive API serves the log from any offset
+	apiURL := fmt.Sprintf("%s/job/%s/%d/logText/progressiveText?start=%d", c.config.URL, encodedJobName, buildNumber, start)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build log: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	next, err := strconv.ParseInt(resp.Header.Get("X-Text-Size"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log size %q: %v", resp.Header.Get("X-Text-Size"), err)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return &LogChunk{
+		Text:     string(bodyBytes),
+		Next:     next,
+		Building: resp.Header.Get("X-More-Data") == "true",
+	}, nil
+}
+
+// TriggerBuild starts a build for a specific job. Parameterized jobs need
+// non-nil parameters; an empty map builds them with their default values.
 func (c *JenkinsClient) TriggerBuild(ctx context.Context, jobName string, parameters map[string]string) error {
 	// Lock to ensure thread safety
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	// URL encode the job name
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
 	var apiURL string
 	var req *http.Request
 	var err error
 
-	if len(parameters) > 0 {
+	if parameters != nil {
 		// Create API URL for triggering a build with parameters
 		apiURL = fmt.Sprintf("%s/job/%s/buildWithParameters", c.config.URL, encodedJobName)
 
@@ -558,7 +896,8 @@ func (c *JenkinsClient) TriggerBuild(ctx context.Context, jobName string, parame
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	// Jenkins answers 201 Created with the location of the queued build
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		return fmt.Errorf("failed to trigger build, status code: %d", resp.StatusCode)
 	}
 
@@ -570,7 +909,7 @@ func (c *JenkinsClient) DeleteJob(ctx context.Context, jobName string) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
 	apiURL := fmt.Sprintf("%s/job/%s/doDelete", c.config.URL, encodedJobName)
 
@@ -596,14 +935,24 @@ func (c *JenkinsClient) DeleteJob(ctx context.Context, jobName string) error {
 
 }
 
+// StopMethod is how a running build is stopped, from the gentlest to the
+// most forceful
+type StopMethod string
+
+const (
+	StopAbort     StopMethod = "stop" // Abort the build, letting it clean up
+	StopTerminate StopMethod = "term" // Terminate a pipeline that ignored the abort
+	StopKill      StopMethod = "kill" // Kill a pipeline that ignored termination
+)
+
 // StopBuild stops a running build
-func (c *JenkinsClient) StopBuild(ctx context.Context, jobName string, buildNumber int) error {
+func (c *JenkinsClient) StopBuild(ctx context.Context, jobName string, buildNumber int, method StopMethod) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	encodedJobName := url.PathEscape(jobName)
+	encodedJobName := jobPath(jobName)
 
-	apiURL := fmt.Sprintf("%s/job/%s/%d/stop", c.config.URL, encodedJobName, buildNumber)
+	apiURL := fmt.Sprintf("%s/job/%s/%d/%s", c.config.URL, encodedJobName, buildNumber, method)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, nil)
 
@@ -625,3 +974,65 @@ func (c *JenkinsClient) StopBuild(ctx context.Context, jobName string, buildNumb
 	}
 	return nil
 }
+
+// DeleteBuild deletes a build of a job
+func (c *JenkinsClient) DeleteBuild(ctx context.Context, jobName string, buildNumber int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	encodedJobName := jobPath(jobName)
+
+	apiURL := fmt.Sprintf("%s/job/%s/%d/doDelete", c.config.URL, encodedJobName, buildNumber)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete build: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete build, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetJobEnabled enables or disables a job
+func (c *JenkinsClient) SetJobEnabled(ctx context.Context, jobName string, enabled bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	encodedJobName := jobPath(jobName)
+
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	apiURL := fmt.Sprintf("%s/job/%s/%s", c.config.URL, encodedJobName, action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.SetBasicAuth(c.config.Username, c.config.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s job: %v", action, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to %s job, status code: %d", action, resp.StatusCode)
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ type JenkinsClient struct {
 	config     *JenkinsConfig
 	configPath string
 	mutex      sync.Mutex
+	transport  *observedTransport
 }
 
 
@@ -54,13 +55,18 @@ type Node struct {
 
 // Job represents a Jenkins job
 type Job struct {
-	Name        string
+	Name        string // Full name, with the folders it is in, e.g. "team/app"
 	URL         string
 	Class       string
 	Color       string
 	Description string
 	Status      string
 	InProgress  bool
+	Parameters  []string // Names of the build parameters, nil if there are none
+	Health      int      // Health score 0-100, -1 when Jenkins reports none
+	LastBuild   *Build   // nil if never built
+	LastSuccess *Build   // nil if never succeeded
+	LastFailure *Build   // nil if never failed
 }
 
 // JobDetail represents detailed information about a Jenkins job
@@ -85,13 +91,32 @@ type JobParameter struct {
 
 // Build represents a Jenkins build
 type Build struct {
-	Number      int
-	URL         string
-	Status      string
-	StartTime   int64
-	Duration    int64
-	Result      string
-	Description string
+	Number            int
+	URL               string
+	Status            string
+	StartTime         int64
+	Duration          int64
+	Result            string
+	Description       string
+	Building          bool
+	EstimatedDuration int64  // -1 when Jenkins has no estimate
+	Commit            string // Git revision built, empty for jobs without Git
+	Rebuild           bool   // Started by rebuilding or replaying an earlier build
+}
+
+// QueueItem is a build waiting in the Jenkins build queue
+type QueueItem struct {
+	JobName      string
+	Why          string // Jenkins' reason for the wait
+	InQueueSince int64
+	Stuck        bool
+	Blocked      bool
+}
+
+// ExecutorUsage counts the busy build executors of all nodes
+type ExecutorUsage struct {
+	Busy  int
+	Total int
 }
 
 // BuildDetail represents detailed information about a Jenkins build
@@ -106,6 +131,14 @@ type BuildDetail struct {
 	Parameters  map[string]string
 }
 
+// LogChunk is the console output of a build from an offset of its log on
+// the server to the end of what has been written so far
+type LogChunk struct {
+	Text     string
+	Next     int64 // Offset of the log on the server to continue from
+	Building bool  // The build is still running, so the log will grow
+}
+
 // GetStatusFromColor converts a Jenkins color to a status string
 func GetStatusFromColor(color string) (status string, inProgress bool) {
 	switch color {
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outcome is what a request tells about the connection to the server
+type Outcome int
+
+const (
+	OutcomeOK           Outcome = iota // The server answered
+	OutcomeServerError                 // The server answered with a 5xx error
+	OutcomeUnauthorized                // The server rejected the credentials
+	OutcomeUnreachable                 // The server could not be reached
+)
+
+// observedTransport reports the outcome of every request it sends and
+// traces it at debug level
+type observedTransport struct {
+	next    http.RoundTripper
+	observe func(Outcome)
+	logger  *zap.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.trace(req, resp, err, start)
+	if t.observe == nil {
+		return resp, err
+	}
+
+	switch {
+	case err != nil:
+		// A request given up on says nothing about the server
+		if !errors.Is(err, context.Canceled) {
+			t.observe(OutcomeUnreachable)
+		}
+	case resp.StatusCode == http.StatusUnauthorized:
+		t.observe(OutcomeUnauthorized)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.observe(OutcomeServerError)
+	default:
+		t.observe(OutcomeOK)
+	}
+	return resp, err
+}
+
+// Observe calls fn with the outcome of every request sent from now on. It
+// must be called before the client is used.
+func (c *JenkinsClient) Observe(fn func(Outcome)) {
+	c.transport.observe = fn
+}
+
+// Trace logs every request sent from now on at debug level: its method,
+// path, status, latency and the size of the response. It must be called
+// before the client is used.
+func (c *JenkinsClient) Trace(logger *zap.Logger) {
+	c.transport.logger = logger
+}
+
+// trace logs a request once its response has been read, or at once if it
+// failed
+func (t *observedTransport) trace(req *http.Request, resp *http.Response, err error, start time.Time) {
+	if t.logger == nil || !t.logger.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+
+	// The fields asked for are left out of the query, and the tokens it may
+	// hold are redacted by the logger
+	path := req.URL.Path
+	query := req.URL.Query()
+	query.Del("tree")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	fields := []zap.Field{zap.String("method", req.Method), zap.String("path", path)}
+
+	if err != nil {
+		t.logger.Debug("request failed", append(fields, zap.Duration("latency", time.Since(start)), zap.Error(err))...)
+		return
+	}
+
+	resp.Body = &tracedBody{ReadCloser: resp.Body, done: func(read int64) {
+		t.logger.Debug("request", append(fields,
+			zap.Int("status", resp.StatusCode),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int64("bytes", read))...)
+	}}
+}
+
+// tracedBody counts the bytes of a response as they are read, and reports
+// them when it is closed
+type tracedBody struct {
+	io.ReadCloser
+	read int64
+	once sync.Once
+	done func(read int64)
+}
+
+// Read implements io.Reader
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.read) })
+	return err
+}
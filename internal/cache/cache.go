@@ -0,0 +1,300 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the size limit of the cache when none is configured
+const DefaultMaxSize = 500 << 20
+
+// Cache keeps the logs and metadata of finished builds on disk, under a
+// directory per server, job and build. Logs are stored compressed. Reading
+// an entry marks it as recently used, and once the cache grows past its
+// size limit the least recently used files are removed.
+type Cache struct {
+	dir     string
+	maxSize int64
+	mutex   sync.Mutex
+}
+
+// New opens the cache in dir, creating it if needed
+func New(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Cache{dir: dir, maxSize: maxSize}, nil
+}
+
+// Dir returns the directory holding the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Log returns the cached console output of a build
+func (c *Cache) Log(server, job string, build int) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	path := c.path(server, job, strconv.Itoa(build), "log.gz")
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return "", false
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", false
+	}
+
+	touch(path)
+	return string(data), true
+}
+
+// PutLog stores the console output of a finished build. Logs that would
+// take up more than a quarter of the cache once compressed are not stored.
+func (c *Cache) PutLog(server, job string, build int, log string) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(log)); err != nil {
+		return fmt.Errorf("failed to compress log: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress log: %v", err)
+	}
+	if int64(buf.Len()) > c.maxSize/4 {
+		return nil
+	}
+
+	return c.put(c.path(server, job, strconv.Itoa(build), "log.gz"), buf.Bytes())
+}
+
+// Get decodes the cached metadata stored under name for a server, job or
+// build into v; job is empty for server-wide entries and build 0 for
+// job-wide ones
+func (c *Cache) Get(server, job string, build int, name string, v any) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	path := c.metaPath(server, job, build, name)
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, v) != nil {
+		return false
+	}
+
+	touch(path)
+	return true
+}
+
+// Put stores metadata under name for a server, job or build, see Get
+func (c *Cache) Put(server, job string, build int, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", name, err)
+	}
+	return c.put(c.metaPath(server, job, build, name), data)
+}
+
+// Remove removes every entry of a build, or of a job and all its builds
+// when build is 0
+func (c *Cache) Remove(server, job string, build int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	dir := c.path(server, job, "")
+	if build != 0 {
+		dir = c.path(server, job, strconv.Itoa(build), "")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove cache entry: %v", err)
+	}
+	removeEmptyDirs(filepath.Dir(dir), c.dir)
+	return nil
+}
+
+// Size returns the total size of the cached files
+func (c *Cache) Size() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var size int64
+	for _, entry := range c.entries() {
+		size += entry.size
+	}
+	return size
+}
+
+// Clear removes every cached entry
+func (c *Cache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %v", err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clear cache: %v", err)
+		}
+	}
+	return nil
+}
+
+// metaPath returns the path of a metadata entry, see Get
+func (c *Cache) metaPath(server, job string, build int, name string) string {
+	switch {
+	case job == "":
+		return c.path(server, name+".json")
+	case build == 0:
+		return c.path(server, job, name+".json")
+	}
+	return c.path(server, job, strconv.Itoa(build), name+".json")
+}
+
+// path joins the cache directory with safe forms of the key parts
+func (c *Cache) path(parts ...string) string {
+	elems := []string{c.dir}
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			part = safeName(part)
+		}
+		elems = append(elems, part)
+	}
+	return filepath.Join(elems...)
+}
+
+// put writes an entry atomically and evicts old entries if the cache has
+// grown past its limit
+func (c *Cache) put(path string, data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	// Write to a temporary file first so that readers never see half an entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+
+	c.evict()
+	return nil
+}
+
+// cacheEntry is a cached file with its size and last use
+type cacheEntry struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// entries lists every cached file
+func (c *Cache) entries() []cacheEntry {
+	var entries []cacheEntry
+	filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			entries = append(entries, cacheEntry{path: path, size: info.Size(), lastUse: info.ModTime()})
+		}
+		return nil
+	})
+	return entries
+}
+
+// evict removes the least recently used files until the cache is back
+// under its limit, then removes the directories left empty
+func (c *Cache) evict() {
+	entries := c.entries()
+
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+	if size <= c.maxSize {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUse.Before(entries[j].lastUse)
+	})
+	for _, entry := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if os.Remove(entry.path) == nil {
+			size -= entry.size
+			removeEmptyDirs(filepath.Dir(entry.path), c.dir)
+		}
+	}
+}
+
+// removeEmptyDirs removes dir and its parents up to root while they are empty
+func removeEmptyDirs(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// touch marks a file as recently used
+func touch(path string) {
+	now := time.Now()
+	os.Chtimes(path, now, now)
+}
+
+// safeName turns a server URL or job name into a directory name: readable
+// characters are kept, and a hash of the original tells apart names that
+// only differ in the characters replaced
+func safeName(name string) string {
+	safe := []byte(name)
+	for i, c := range safe {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			safe[i] = '_'
+		}
+	}
+	if len(safe) > 64 {
+		safe = safe[:64]
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", strings.Trim(string(safe), "."), hash.Sum32())
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const server = "https://ci.example.com"
+
+// age marks the entry of a build as last used some time ago
+func age(t *testing.T, c *Cache, job string, build int, ago time.Duration) {
+	t.Helper()
+	when := time.Now().Add(-ago)
+	if err := os.Chtimes(c.metaPath(server, job, build, "detail"), when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	value := strings.Repeat("x", 400) // About 400 bytes once encoded
+
+	tests := []struct {
+		name string
+		read int   // Build read after all were aged, 0 for none
+		want []int // Builds left once build 3 is stored
+	}{
+		{"least recently stored", 0, []int{2, 3}},
+		{"reading keeps an entry", 1, []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(t.TempDir(), 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for build := 1; build <= 2; build++ {
+				if err := c.Put(server, "deploy", build, "detail", value); err != nil {
+					t.Fatal(err)
+				}
+				age(t, c, "deploy", build, time.Duration(3-build)*time.Hour)
+			}
+			if tt.read != 0 {
+				var v string
+				if !c.Get(server, "deploy", tt.read, "detail", &v) || v != value {
+					t.Fatalf("build %d not read back", tt.read)
+				}
+			}
+			if err := c.Put(server, "deploy", 3, "detail", value); err != nil {
+				t.Fatal(err)
+			}
+
+			var left []int
+			for build := 1; build <= 3; build++ {
+				var v string
+				if c.Get(server, "deploy", build, "detail", &v) {
+					left = append(left, build)
+				}
+			}
+			if len(left) != len(tt.want) || left[0] != tt.want[0] || left[1] != tt.want[1] {
+				t.Errorf("builds %v left, want %v", left, tt.want)
+			}
+			if size := c.Size(); size > 1000 {
+				t.Errorf("cache holds %d bytes, over its limit", size)
+			}
+		})
+	}
+}
+
+func TestEvictionRemovesEmptyDirs(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := strings.Repeat("x", 400)
+	if err := c.Put(server, "old", 1, "detail", value); err != nil {
+		t.Fatal(err)
+	}
+	age(t, c, "old", 1, time.Hour)
+	if err := c.Put(server, "new", 1, "detail", value); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(c.path(server, "old", "")); !os.IsNotExist(err) {
+		t.Errorf("directory of the evicted job left behind: %v", err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	c, err := New(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := strings.Repeat("[INFO] Building\n", 100)
+	if err := c.PutLog(server, "team/app", 7, log); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Log(server, "team/app", 7); !ok || got != log {
+		t.Errorf("log not read back: %v", ok)
+	}
+	if _, ok := c.Log(server, "team/app", 8); ok {
+		t.Error("log of another build found")
+	}
+	if _, ok := c.Log("https://other.example.com", "team/app", 7); ok {
+		t.Error("log of another server found")
+	}
+
+	// Logs too large for the cache are not kept
+	small, err := New(t.TempDir(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := small.PutLog(server, "app", 1, log); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := small.Log(server, "app", 1); ok {
+		t.Error("log larger than a quarter of the cache stored")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c, err := New(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for build := 1; build <= 2; build++ {
+		if err := c.PutLog(server, "app", build, "log"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Put(server, "app", 0, "detail", "job"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Remove(server, "app", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Log(server, "app", 1); ok {
+		t.Error("removed build still cached")
+	}
+	if _, ok := c.Log(server, "app", 2); !ok {
+		t.Error("other build removed too")
+	}
+
+	if err := c.Remove(server, "app", 0); err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	if _, ok := c.Log(server, "app", 2); ok || c.Get(server, "app", 0, "detail", &v) {
+		t.Error("removed job still cached")
+	}
+	if c.Size() != 0 {
+		t.Errorf("%d bytes left", c.Size())
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"team/app", "team_app"},
+		{"https://ci.example.com", "https___ci.example.com"},
+		{"..", "__"},
+	}
+
+	for _, tt := range tests {
+		na, nb := safeName(tt.a), safeName(tt.b)
+		if na == nb {
+			t.Errorf("%q and %q share the name %q", tt.a, tt.b, na)
+		}
+		if strings.ContainsAny(na, "/\\:") || strings.HasPrefix(na, ".") {
+			t.Errorf("unsafe name %q for %q", na, tt.a)
+		}
+	}
+}
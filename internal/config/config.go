@@ -16,6 +16,7 @@ type JenkinsServer struct {
 	Token              string `yaml:"token"`
 	Proxy              string `yaml:"proxy"`
 	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+	Protected          bool   `yaml:"protected,omitempty"` // Deletions ask for the job name to be typed
 }
 
 // UISettings represents the UI configuration
@@ -24,24 +25,138 @@ type UISettings struct {
 	RefreshInterval int    `yaml:"refreshInterval"`
 	MaxLogLines     int    `yaml:"maxLogLines"`
 	CompactMode     bool   `yaml:"compactMode"`
+	ThemeFile       string `yaml:"themeFile,omitempty"` // Custom themes, defaults to ~/.jenkins-tui/themes.yaml
+	FilterBefore    int    `yaml:"filterBefore"`        // Context lines shown before filtered log lines
+	FilterAfter     int    `yaml:"filterAfter"`         // Context lines shown after filtered log lines
+	TrendBuilds     int    `yaml:"trendBuilds"`         // Builds shown in a job's duration sparkline
+	JobTable        bool   `yaml:"jobTable"`            // Show the jobs as a table instead of a list
+	Layout          Layout `yaml:"layout,omitempty"`
 }
 
-// KeyBindings represents custom keybindings
+// Layout arranges views in split panes, shown together while one of them is
+// open
+type Layout struct {
+	Split bool     `yaml:"split,omitempty"` // Show the panes; one view at a time otherwise
+	Panes []string `yaml:"panes,omitempty"` // Views in the split: jobs, job and log (default all three)
+	Sizes []int    `yaml:"sizes,omitempty"` // Share of each pane in percent, equal by default
+	Stack bool     `yaml:"stack,omitempty"` // Stack the panes top to bottom instead of side by side
+}
+
+// KeyBindings represents custom keybindings. Each entry maps an action name
+// to a comma-separated list of keys, e.g. `down: "j,down"`. Global bindings
+// apply to every view; Views overrides them for a single view.
 type KeyBindings struct {
-	Quit      string `yaml:"quit"`
-	Help      string `yaml:"help"`
-	Dashboard string `yaml:"dashboard"`
-	Jobs      string `yaml:"jobs"`
-	Builds    string `yaml:"builds"`
-	Nodes     string `yaml:"nodes"`
+	Global map[string]string            `yaml:",inline"`
+	Views  map[string]map[string]string `yaml:"views,omitempty"`
+}
+
+// legacyKeyBindings are the defaults written by versions that ignored the
+// keybindings section; they conflict with list navigation and are dropped.
+var legacyKeyBindings = map[string]string{
+	"quit":      "q",
+	"help":      "?",
+	"dashboard": "d",
+	"jobs":      "j",
+	"builds":    "b",
+	"nodes":     "n",
+}
+
+// HighlightRule colours build log text matching a regular expression
+type HighlightRule struct {
+	Pattern string `yaml:"pattern"`
+	Style   string `yaml:"style"`           // Theme role (error, warning, success, info, muted, accent) or a colour
+	Scope   string `yaml:"scope,omitempty"` // "line" (default) colours the whole line, "match" only the match
+	Bold    bool   `yaml:"bold,omitempty"`
+}
+
+// LogHighlighting configures how build logs are coloured. Rules are tried
+// in order before the rules of the listed presets; when Presets is omitted
+// every built-in preset is used.
+type LogHighlighting struct {
+	Presets []string        `yaml:"presets,omitempty"`
+	Rules   []HighlightRule `yaml:"rules,omitempty"`
+}
+
+// NormalizeRule rewrites text matching a regular expression before two
+// build logs are compared; Replace may refer to groups as $1
+type NormalizeRule struct {
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// LogDiff configures the comparison of two build logs. Normalize rules run
+// before the built-in rules for timestamps, build numbers, temporary paths,
+// durations and hexadecimal IDs.
+type LogDiff struct {
+	Normalize          []NormalizeRule `yaml:"normalize,omitempty"`
+	NoDefaultNormalize bool            `yaml:"noDefaultNormalize,omitempty"` // Only apply the configured rules
+	Layout             string          `yaml:"layout,omitempty"`             // "unified" (default) or "side-by-side"
+	Context            int             `yaml:"context,omitempty"`            // Unchanged lines shown around each hunk, default 3
+}
+
+// FailureSignature identifies a known cause of failure in a build log
+type FailureSignature struct {
+	Name    string `yaml:"name"` // Shown in the failure summary, e.g. "Flaky test"
+	Pattern string `yaml:"pattern"`
+}
+
+// FailureAnalysis configures the failure summary of failed builds.
+// Signatures are tried before the built-in ones for compiler errors, test
+// failures, stack traces, error steps, out-of-memory kills and timeouts.
+type FailureAnalysis struct {
+	Signatures          []FailureSignature `yaml:"signatures,omitempty"`
+	NoDefaultSignatures bool               `yaml:"noDefaultSignatures,omitempty"` // Only use the configured signatures
+}
+
+// CacheSettings configures the on-disk cache of finished builds' logs and
+// metadata, which is also used to browse recently viewed builds offline
+type CacheSettings struct {
+	Disabled bool   `yaml:"disabled,omitempty"`
+	Dir      string `yaml:"dir,omitempty"`     // Defaults to ~/.jenkins-tui/cache
+	MaxSize  int    `yaml:"maxSize,omitempty"` // In megabytes, default 500
+}
+
+// WatchSettings configures the notifications about watched jobs. Command is
+// run through the shell when a watched job starts, fails or recovers, with
+// the details in JENKINS_* environment variables.
+type WatchSettings struct {
+	Command string `yaml:"command,omitempty"`
+	NoBell  bool   `yaml:"noBell,omitempty"` // Don't ring the terminal bell
+}
+
+// StatsSettings configures the job reliability statistics
+type StatsSettings struct {
+	Window    string `yaml:"window,omitempty"`    // Window shown first: 7d, 14d, 30d (default), 90d or all
+	ExportDir string `yaml:"exportDir,omitempty"` // Where CSV exports are written, default the working directory
+}
+
+// DashboardWidget places a widget on the dashboard
+type DashboardWidget struct {
+	Type   string `yaml:"type"` // server, watched, status, executors, running, queue, failing or recent
+	Hidden bool   `yaml:"hidden,omitempty"`
+	Width  string `yaml:"width,omitempty"`  // "half" (default) shares a row on wide terminals, "full" takes the whole row
+	Height int    `yaml:"height,omitempty"` // Rows of content, for widgets listing jobs or builds
+}
+
+// Dashboard configures the widgets of the dashboard, in the order they are
+// shown; when Widgets is omitted every widget is shown in the default order
+type Dashboard struct {
+	Widgets []DashboardWidget `yaml:"widgets,omitempty"`
 }
 
 // Config represents the application configuration
 type Config struct {
-	Current        string          `yaml:"current"`
-	JenkinsServers []JenkinsServer `yaml:"jenkins_servers"`
-	UI             UISettings      `yaml:"ui"`
-	KeyBindings    KeyBindings     `yaml:"keybindings"`
+	Current         string          `yaml:"current"`
+	JenkinsServers  []JenkinsServer `yaml:"jenkins_servers"`
+	UI              UISettings      `yaml:"ui"`
+	Dashboard       Dashboard       `yaml:"dashboard,omitempty"`
+	KeyBindings     KeyBindings     `yaml:"keybindings"`
+	LogHighlighting LogHighlighting `yaml:"logHighlighting,omitempty"`
+	LogDiff         LogDiff         `yaml:"logDiff,omitempty"`
+	FailureAnalysis FailureAnalysis `yaml:"failureAnalysis,omitempty"`
+	Cache           CacheSettings   `yaml:"cache,omitempty"`
+	Watch           WatchSettings   `yaml:"watch,omitempty"`
+	Stats           StatsSettings   `yaml:"stats,omitempty"`
 }
 
 // Manager handles configuration loading and saving
@@ -69,14 +184,9 @@ func DefaultConfig() *Config {
 			RefreshInterval: 30,
 			MaxLogLines:     1000,
 			CompactMode:     false,
-		},
-		KeyBindings: KeyBindings{
-			Quit:      "q",
-			Help:      "?",
-			Dashboard: "d",
-			Jobs:      "j",
-			Builds:    "b",
-			Nodes:     "n",
+			FilterBefore:    2,
+			FilterAfter:     2,
+			TrendBuilds:     30,
 		},
 	}
 }
@@ -110,10 +220,29 @@ func (m *Manager) Load() error {
 		return fmt.Errorf("failed to parse config file: %v", err)
 	}
 
+	if isLegacyKeyBindings(config.KeyBindings) {
+		config.KeyBindings = KeyBindings{}
+	}
+
 	m.Config = config
 	return nil
 }
 
+// isLegacyKeyBindings reports whether kb is exactly the old default block
+func isLegacyKeyBindings(kb KeyBindings) bool {
+	if len(kb.Views) > 0 || len(kb.Global) != len(legacyKeyBindings) {
+		return false
+	}
+
+	for action, keys := range legacyKeyBindings {
+		if kb.Global[action] != keys {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Save saves the configuration to the file
 func (m *Manager) Save() error {
 	// Create the directory if it doesn't exist
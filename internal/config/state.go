@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State holds what the app remembers between runs, as opposed to what the
+// user configures. It is kept in its own file, by default
+// ~/.jenkins-tui/state.yaml, so that the config file is never rewritten.
+type State struct {
+	Watched  map[string][]string         `yaml:"watched,omitempty"`  // Watched job names by server URL
+	Visits   map[string]map[string]Visit `yaml:"visits,omitempty"`   // Command palette entries chosen, by server URL
+	Commands []string                    `yaml:"commands,omitempty"` // Command line history, oldest first
+	Layout   *PaneLayout                 `yaml:"layout,omitempty"`   // Split panes as last arranged
+
+	path string
+}
+
+// PaneLayout is the split as last arranged, which takes over from the one
+// set by ui.layout: whether it is shown and the sizes of its panes
+type PaneLayout struct {
+	Split bool  `yaml:"split"`
+	Sizes []int `yaml:"sizes,omitempty"`
+}
+
+// LoadState reads the state file at path; a missing file is an empty state
+func LoadState(path string) (*State, error) {
+	state := &State{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %v", err)
+	}
+
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %v", err)
+	}
+
+	return state, nil
+}
+
+// Save writes the state back to its file
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+
+	return nil
+}
+
+// IsWatched reports whether a job of a server is on the watchlist
+func (s *State) IsWatched(server, job string) bool {
+	for _, name := range s.Watched[server] {
+		if name == job {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleWatched adds a job of a server to the watchlist or removes it, and
+// reports whether the job is now watched
+func (s *State) ToggleWatched(server, job string) bool {
+	watched := s.Watched[server]
+	for i, name := range watched {
+		if name == job {
+			s.Watched[server] = append(watched[:i:i], watched[i+1:]...)
+			if len(s.Watched[server]) == 0 {
+				delete(s.Watched, server)
+			}
+			return false
+		}
+	}
+
+	if s.Watched == nil {
+		s.Watched = map[string][]string{}
+	}
+	watched = append(watched, job)
+	sort.Strings(watched)
+	s.Watched[server] = watched
+	return true
+}
+
+const (
+	// maxVisits is how many palette entries are remembered per server
+	maxVisits = 300
+	// maxCommands is how many command lines are remembered
+	maxCommands = 100
+)
+
+// Visit records how often and how recently a command palette entry was chosen
+type Visit struct {
+	Count int       `yaml:"count"`
+	Last  time.Time `yaml:"last"`
+}
+
+// Frecency scores a visit by how often and how recently it happened, so that
+// entries used a lot long ago rank below those used a few times lately
+func (v Visit) Frecency(now time.Time) float64 {
+	age := now.Sub(v.Last)
+	weight := 10.0
+	switch {
+	case age < 4*24*time.Hour:
+		weight = 100
+	case age < 14*24*time.Hour:
+		weight = 70
+	case age < 31*24*time.Hour:
+		weight = 50
+	case age < 90*24*time.Hour:
+		weight = 30
+	}
+	return float64(v.Count) * weight
+}
+
+// RecordVisit counts a choice of a palette entry on a server, forgetting the
+// lowest ranked entries once there are too many
+func (s *State) RecordVisit(server, entry string, now time.Time) {
+	if s.Visits == nil {
+		s.Visits = map[string]map[string]Visit{}
+	}
+	visits := s.Visits[server]
+	if visits == nil {
+		visits = map[string]Visit{}
+		s.Visits[server] = visits
+	}
+
+	visit := visits[entry]
+	visit.Count++
+	visit.Last = now
+	visits[entry] = visit
+
+	if len(visits) > maxVisits {
+		// The entry just chosen is kept even if it ranks lowest
+		entries := make([]string, 0, len(visits))
+		for name := range visits {
+			if name != entry {
+				entries = append(entries, name)
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return visits[entries[i]].Frecency(now) < visits[entries[j]].Frecency(now)
+		})
+		for _, name := range entries[:len(visits)-maxVisits] {
+			delete(visits, name)
+		}
+	}
+}
+
+// AddCommand appends a command line to the history, moving it to the end if
+// it was run before
+func (s *State) AddCommand(line string) {
+	for i, command := range s.Commands {
+		if command == line {
+			s.Commands = append(s.Commands[:i:i], s.Commands[i+1:]...)
+			break
+		}
+	}
+
+	s.Commands = append(s.Commands, line)
+	if len(s.Commands) > maxCommands {
+		s.Commands = s.Commands[len(s.Commands)-maxCommands:]
+	}
+}
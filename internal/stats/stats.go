@@ -0,0 +1,242 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// DefaultWindow is the name of the window shown when none is configured
+const DefaultWindow = "30d"
+
+// HistoryLimit is the number of builds Jenkins lists with a job at most,
+// the most recent ones; a longer history is fetched page by page
+const HistoryLimit = 100
+
+// MaxHistory bounds the builds of a job fetched for statistics. Windows
+// that reach back past them are reported as truncated.
+const MaxHistory = 2000
+
+// Window is a period of build history that statistics are computed over
+type Window struct {
+	Name   string
+	Period time.Duration // Zero for the whole history
+}
+
+// Windows lists the selectable windows, shortest first
+var Windows = []Window{
+	{Name: "7d", Period: 7 * 24 * time.Hour},
+	{Name: "14d", Period: 14 * 24 * time.Hour},
+	{Name: "30d", Period: 30 * 24 * time.Hour},
+	{Name: "90d", Period: 90 * 24 * time.Hour},
+	{Name: "all"},
+}
+
+// FindWindow returns the window with the given name
+func FindWindow(name string) (Window, bool) {
+	for _, w := range Windows {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// Next returns the window after w, wrapping around to the shortest
+func (w Window) Next() Window {
+	for i, window := range Windows {
+		if window.Name == w.Name {
+			return Windows[(i+1)%len(Windows)]
+		}
+	}
+	return Windows[0]
+}
+
+// Report holds the reliability statistics of a job over a window. Only
+// builds that succeeded or failed count; running, aborted and unstable
+// builds are left out, the last two counted on their own.
+type Report struct {
+	Window   Window
+	Builds   int // Succeeded or failed builds in the window
+	Failures int
+	Aborted  int
+	Unstable int // Builds that ran but whose tests failed
+
+	// The history stops short of the start of the window, so older builds
+	// are left out
+	Truncated bool
+
+	SuccessRate   float64       // Fraction of builds that succeeded
+	MTTR          time.Duration // Mean time from a failure to the next success
+	Recoveries    int           // Failing streaks that ended in a success
+	LongestStreak int           // Most consecutive failed builds
+
+	AvgDuration time.Duration
+	P95Duration time.Duration
+
+	// Builds whose result differs from the previous build of the same
+	// commit, or rebuilds that passed after a failure
+	FlakyBuilds int
+	Flakiness   float64 // Flaky builds per pair of consecutive builds
+}
+
+// Compute works out the statistics of a job's builds started within the
+// window before now; whole tells that builds reach back to the job's first
+// build, and otherwise the report is truncated unless they cover the window
+func Compute(builds []api.Build, whole bool, window Window, now time.Time) Report {
+	report := Report{Window: window}
+
+	// Without the first build, the history covers the window only when it
+	// holds a build started before the window
+	if !whole {
+		report.Truncated = true
+		for _, build := range builds {
+			if window.Period > 0 && time.UnixMilli(build.StartTime).Before(now.Add(-window.Period)) {
+				report.Truncated = false
+				break
+			}
+		}
+	}
+
+	var history []api.Build
+	for _, build := range builds {
+		started := time.UnixMilli(build.StartTime)
+		if window.Period > 0 && started.Before(now.Add(-window.Period)) {
+			continue
+		}
+		// The status of an unstable build says failed, although it built
+		if build.Result == "UNSTABLE" {
+			report.Unstable++
+			continue
+		}
+		switch api.JobStatus(build.Status) {
+		case api.StatusSuccess, api.StatusFailed:
+			history = append(history, build)
+		case api.StatusAborted:
+			report.Aborted++
+		}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Number < history[j].Number
+	})
+
+	report.Builds = len(history)
+	if report.Builds == 0 {
+		return report
+	}
+
+	durations := make([]float64, len(history))
+	var recoveryTime time.Duration
+	streak := 0
+	var firstFailure *api.Build
+	for i, build := range history {
+		durations[i] = float64(build.Duration)
+		failed := api.JobStatus(build.Status) == api.StatusFailed
+
+		if failed {
+			report.Failures++
+			streak++
+			report.LongestStreak = max(report.LongestStreak, streak)
+			if firstFailure == nil {
+				firstFailure = &history[i]
+			}
+		} else {
+			streak = 0
+			if firstFailure != nil {
+				recoveryTime += finished(build).Sub(finished(*firstFailure))
+				report.Recoveries++
+				firstFailure = nil
+			}
+		}
+
+		if i > 0 && isFlaky(history[i-1], build) {
+			report.FlakyBuilds++
+		}
+	}
+
+	report.SuccessRate = float64(report.Builds-report.Failures) / float64(report.Builds)
+	if report.Recoveries > 0 {
+		report.MTTR = recoveryTime / time.Duration(report.Recoveries)
+	}
+	report.AvgDuration = time.Duration(utils.Mean(durations)) * time.Millisecond
+	report.P95Duration = time.Duration(utils.Percentile(durations, 95)) * time.Millisecond
+	if len(history) > 1 {
+		report.Flakiness = float64(report.FlakyBuilds) / float64(len(history)-1)
+	}
+
+	return report
+}
+
+// isFlaky reports whether a build's result changed although nothing was
+// changed: the same commit was built again, or a failed build was rebuilt
+func isFlaky(previous, build api.Build) bool {
+	if previous.Status == build.Status {
+		return false
+	}
+	if build.Commit != "" && build.Commit == previous.Commit {
+		return true
+	}
+	return build.Rebuild && api.JobStatus(build.Status) == api.StatusSuccess
+}
+
+// finished returns when a build finished
+func finished(build api.Build) time.Time {
+	return time.UnixMilli(build.StartTime + build.Duration)
+}
+
+// csvHeader names the columns written by WriteCSV
+var csvHeader = []string{
+	"job", "window", "truncated", "builds", "failures", "aborted", "unstable", "success_rate",
+	"mttr_seconds", "recoveries", "longest_failing_streak",
+	"avg_duration_seconds", "p95_duration_seconds", "flaky_builds", "flakiness",
+}
+
+// WriteCSV writes one row per job and window, with the jobs in name order
+func WriteCSV(w io.Writer, reports map[string][]Report) error {
+	jobs := make([]string, 0, len(reports))
+	for job := range reports {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	for _, job := range jobs {
+		for _, r := range reports[job] {
+			row := []string{
+				job,
+				r.Window.Name,
+				strconv.FormatBool(r.Truncated),
+				strconv.Itoa(r.Builds),
+				strconv.Itoa(r.Failures),
+				strconv.Itoa(r.Aborted),
+				strconv.Itoa(r.Unstable),
+				strconv.FormatFloat(r.SuccessRate, 'f', 4, 64),
+				strconv.FormatFloat(r.MTTR.Seconds(), 'f', 0, 64),
+				strconv.Itoa(r.Recoveries),
+				strconv.Itoa(r.LongestStreak),
+				strconv.FormatFloat(r.AvgDuration.Seconds(), 'f', 1, 64),
+				strconv.FormatFloat(r.P95Duration.Seconds(), 'f', 1, 64),
+				strconv.Itoa(r.FlakyBuilds),
+				strconv.FormatFloat(r.Flakiness, 'f', 4, 64),
+			}
+			if err := out.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV: %v", err)
+			}
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return nil
+}
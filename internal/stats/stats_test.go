@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+)
+
+// builds returns a history with one build a minute from results such as
+// "SUCCESS", "FAILURE" or "UNSTABLE"; an empty result is a running build
+func builds(now time.Time, results ...string) []api.Build {
+	out := make([]api.Build, len(results))
+	for i, result := range results {
+		start := now.Add(time.Duration(i-len(results)) * time.Minute)
+		out[i] = api.Build{
+			Number:    i + 1,
+			Status:    string(api.GetStatusFromResult(result, result == "")),
+			Result:    result,
+			StartTime: start.UnixMilli(),
+			Duration:  int64(30 * time.Second / time.Millisecond),
+		}
+	}
+	return out
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Now()
+	all, _ := FindWindow("all")
+
+	tests := []struct {
+		name         string
+		results      []string
+		wantBuilds   int
+		wantFailures int
+		wantAborted  int
+		wantUnstable int
+		wantRate     float64
+		wantStreak   int
+		wantRecover  int
+	}{
+		{"no builds", nil, 0, 0, 0, 0, 0, 0, 0},
+		{"all passed", []string{"SUCCESS", "SUCCESS"}, 2, 0, 0, 0, 1, 0, 0},
+		{"failed and fixed", []string{"SUCCESS", "FAILURE", "FAILURE", "SUCCESS"}, 4, 2, 0, 0, 0.5, 2, 1},
+		{"unstable apart", []string{"SUCCESS", "UNSTABLE", "SUCCESS", "UNSTABLE"}, 2, 0, 0, 2, 1, 0, 0},
+		{"unstable within a streak", []string{"FAILURE", "UNSTABLE", "FAILURE"}, 2, 2, 0, 1, 0, 2, 0},
+		{"aborted and running left out", []string{"SUCCESS", "ABORTED", "FAILURE", ""}, 2, 1, 1, 0, 0.5, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Compute(builds(now, tt.results...), true, all, now)
+			if r.Builds != tt.wantBuilds || r.Failures != tt.wantFailures || r.Aborted != tt.wantAborted || r.Unstable != tt.wantUnstable {
+				t.Errorf("builds %d, failures %d, aborted %d, unstable %d; want %d, %d, %d, %d",
+					r.Builds, r.Failures, r.Aborted, r.Unstable, tt.wantBuilds, tt.wantFailures, tt.wantAborted, tt.wantUnstable)
+			}
+			if r.SuccessRate != tt.wantRate {
+				t.Errorf("success rate %v, want %v", r.SuccessRate, tt.wantRate)
+			}
+			if r.LongestStreak != tt.wantStreak || r.Recoveries != tt.wantRecover {
+				t.Errorf("streak %d, recoveries %d; want %d, %d", r.LongestStreak, r.Recoveries, tt.wantStreak, tt.wantRecover)
+			}
+		})
+	}
+}
+
+func TestComputeWindow(t *testing.T) {
+	now := time.Now()
+	week, _ := FindWindow("7d")
+
+	history := builds(now, "FAILURE", "SUCCESS")
+	history[0].StartTime = now.Add(-8 * 24 * time.Hour).UnixMilli()
+
+	if r := Compute(history, true, week, now); r.Builds != 1 || r.Failures != 0 {
+		t.Errorf("got %d builds, %d failures; want the build of last week only", r.Builds, r.Failures)
+	}
+}
+
+func TestComputeTruncated(t *testing.T) {
+	now := time.Now()
+	week, _ := FindWindow("7d")
+	all, _ := FindWindow("all")
+
+	recent := builds(now, "SUCCESS", "FAILURE")
+	older := builds(now, "SUCCESS", "FAILURE")
+	older[0].StartTime = now.Add(-8 * 24 * time.Hour).UnixMilli()
+
+	tests := []struct {
+		name    string
+		history []api.Build
+		whole   bool
+		window  Window
+		want    bool
+	}{
+		{"whole history", recent, true, all, false},
+		{"part of the history for all of it", older, false, all, true},
+		{"history reaching past the window", older, false, week, false},
+		{"history stopping inside the window", recent, false, week, true},
+		{"no builds fetched", nil, false, week, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := Compute(tt.history, tt.whole, tt.window, now); r.Truncated != tt.want {
+				t.Errorf("truncated %v, want %v", r.Truncated, tt.want)
+			}
+		})
+	}
+}
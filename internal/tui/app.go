@@ -2,12 +2,19 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/stats"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
 )
@@ -21,6 +28,9 @@ const (
 	JobDetailView
 	BuildLogView
 	HelpView
+	LogDiffView
+	BuildChartView
+	ErrorLogView
 )
 
 // Custom tea.Msg types for asynchronous operations
@@ -45,40 +55,125 @@ type fetchBuildDetailMsg struct {
 }
 
 type fetchBuildLogMsg struct {
-	buildLog string
+	jobName     string
+	buildNumber int
+	offset      int64
+	chunk       *LogRange
+	err         error
+}
+
+type fetchBuildHistoryMsg struct {
+	jobName string
+	builds  []api.Build
+	whole   bool
+	err     error
+}
+
+type fetchFailureSummaryMsg struct {
+	jobName     string
+	buildNumber int
+	hits        []utils.FailureHit
+	err         error
+}
+
+type fetchLoadMsg struct {
+	queue []api.QueueItem
+	usage *api.ExecutorUsage
+	err   error
+}
+
+type fetchLogDiffMsg struct {
+	jobName  string
+	oldBuild int
+	newBuild int
+	diff     components.LogDiff
 	err      error
 }
 
+// viewKeys maps each view to its section of the keybindings config
+var viewKeys = map[ViewType]string{
+	DashboardView:  components.DashboardKeys,
+	JobListView:    components.JobListKeys,
+	JobDetailView:  components.JobDetailKeys,
+	BuildLogView:   components.BuildLogKeys,
+	LogDiffView:    components.LogDiffKeys,
+	BuildChartView: components.BuildChartKeys,
+	HelpView:       components.HelpKeys,
+	ErrorLogView:   components.ErrorLogKeys,
+}
+
 // RefreshTickMsg is sent when it's time to refresh the UI
 type RefreshTickMsg time.Time
 
 // Model represents the state of our application
 type Model struct {
-	keys           components.KeyMap
-	help           help.Model
-	currentView    ViewType
-	width          int
-	height         int
-	connected      bool
-	serverURL      string
-	errorMsg       string
-	statusMessage  string
-	loadingMessage string
-	service        *JenkinsService
-	selectedJob    string
-	selectedBuild  int
+	keyMaps       components.KeyMaps
+	help          help.Model
+	currentView   ViewType
+	analyzer      *utils.FailureAnalyzer
+	width         int
+	height        int
+	conn          ConnState // As last seen, to tell when it changes
+	serverURL     string
+	errorMsg      string // Error of the last message, moved to the error log
+	statusMessage string
+	service       *JenkinsService
+	selectedJob   string
+	selectedBuild int
+
+	// Requests to the server in flight, oldest first, and the spinner shown
+	// while there are any
+	requests []string
+	spinner  spinner.Model
+	spinning bool
+
+	// Failed attempts to reconnect in a row, and the next one planned
+	retries int
+	retryID int
+	retryAt time.Time
+
+	// Whether the bell is in the view, and the last one rung
+	ringing bool
+	bellID  int
+
+	// Watchlist of favourite jobs and the job states of the last refresh
+	state     *config.State
+	jobs      []api.Job
+	jobStates map[string]api.Job
 
 	// View components
-	dashboard components.DashboardComponent
-	jobList   components.JobListComponent
-	jobDetail components.JobDetailComponent
-	buildLog  components.BuildLogComponent
-	helpView  components.HelpComponent
+	dashboard  components.DashboardComponent
+	jobList    components.JobListComponent
+	jobDetail  components.JobDetailComponent
+	buildLog   components.BuildLogComponent
+	logDiff    components.LogDiffComponent
+	buildChart components.BuildChartComponent
+	helpView   components.HelpComponent
+	toasts     components.ToastsComponent
+	errorLog   components.ErrorLogComponent
+
+	// Confirmation dialog and the action it guards
+	confirm       components.ConfirmComponent
+	pendingAction tea.Cmd
+
+	// How far the builds asked to stop were stopped, by buildKey
+	stopRequests map[string]api.StopMethod
+
+	// Command palette and command line
+	palette components.PaletteComponent
+	command components.CommandLineComponent
+
+	// Arrangement of the views under the tab bar
+	layout layout
+
+	// Places to go back and forward to, and whether the last message went
+	// to one of them
+	history   history
+	travelled bool
 }
 
 // New returns a new instance of our application model
 func New() (Model, error) {
-	keys := components.DefaultKeyMap()
 	h := help.New()
 	h.ShowAll = false
 
@@ -88,66 +183,309 @@ func New() (Model, error) {
 		return Model{}, fmt.Errorf("failed to initialize Jenkins service: %v", err)
 	}
 
+	cfg := service.GetConfig()
+
+	// Load custom themes and apply the configured one before building components
+	if err := loadTheme(cfg.UI); err != nil {
+		return Model{}, err
+	}
+
+	// Compile the build log highlighting rules
+	highlighter, err := utils.NewLogHighlighter(cfg.LogHighlighting)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid log highlighting: %v", err)
+	}
+	utils.SetLogHighlighter(highlighter)
+
+	// Compile the rules that mask volatile content when comparing logs
+	normalizer, err := utils.NewLogNormalizer(cfg.LogDiff)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid log diff settings: %v", err)
+	}
+	switch cfg.LogDiff.Layout {
+	case "", components.DiffUnified, components.DiffSideBySide:
+	default:
+		return Model{}, fmt.Errorf("invalid log diff settings: layout must be %q or %q, not %q",
+			components.DiffUnified, components.DiffSideBySide, cfg.LogDiff.Layout)
+	}
+
+	// Compile the failure signatures used to summarise failed builds
+	analyzer, err := utils.NewFailureAnalyzer(cfg.FailureAnalysis)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid failure analysis settings: %v", err)
+	}
+
+	// Build the per-view keybindings, rejecting conflicting configurations
+	keyMaps, err := components.NewKeyMaps(cfg.KeyBindings)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid keybindings: %v", err)
+	}
+
+	// Load the watchlist and other state kept between runs
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Model{}, fmt.Errorf("failed to get home directory: %v", err)
+	}
+	state, err := config.LoadState(filepath.Join(homeDir, ".jenkins-tui", "state.yaml"))
+	if err != nil {
+		return Model{}, err
+	}
+
+	// Lay out the dashboard widgets
+	dashboard, err := components.NewDashboard().WithWidgets(cfg.Dashboard)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid dashboard settings: %v", err)
+	}
+
+	layout, err := newLayout(cfg.UI.Layout)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid layout settings: %v", err)
+	}
+	if state.Layout != nil {
+		layout = layout.restore(*state.Layout)
+	}
+
+	jobDetail, err := components.NewJobDetail().
+		WithKeyMap(keyMaps.For(components.JobDetailKeys)).
+		WithTrendBuilds(cfg.UI.TrendBuilds).
+		WithStatsWindow(cfg.Stats.Window)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid stats settings: %v", err)
+	}
+
 	m := Model{
-		keys:           keys,
-		help:           h,
-		currentView:    DashboardView,
-		connected:      false,
-		serverURL:      "",
-		statusMessage:  "Welcome to Jenkins TUI",
-		loadingMessage: "",
-		dashboard:      components.NewDashboard(),
-		jobList:        components.NewJobList(),
-		jobDetail:      components.NewJobDetail(),
-		buildLog:       components.NewBuildLog(),
-		helpView:       components.NewHelp(),
-		service:        service,
+		keyMaps:       keyMaps,
+		help:          h,
+		currentView:   DashboardView,
+		analyzer:      analyzer,
+		serverURL:     "",
+		statusMessage: "Welcome to Jenkins TUI",
+		spinner:       newSpinner(),
+		dashboard:     dashboard,
+		jobList: components.NewJobList().
+			WithKeyMap(keyMaps.For(components.JobListKeys)).
+			WithTable(cfg.UI.JobTable),
+		jobDetail: jobDetail,
+		buildLog: components.NewBuildLog().
+			WithKeyMap(keyMaps.For(components.BuildLogKeys)).
+			WithFilterContext(cfg.UI.FilterBefore, cfg.UI.FilterAfter).
+			WithFailureAnalyzer(analyzer),
+		logDiff: components.NewLogDiff().
+			WithKeyMap(keyMaps.For(components.LogDiffKeys)).
+			WithNormalizer(normalizer).
+			WithLayout(cfg.LogDiff.Layout, cfg.LogDiff.Context),
+		buildChart: components.NewBuildChart().WithKeyMap(keyMaps.For(components.BuildChartKeys)),
+		helpView:   components.NewHelp().WithKeyMaps(keyMaps),
+		toasts:     components.NewToasts(),
+		errorLog:   components.NewErrorLog().WithKeyMap(keyMaps.For(components.ErrorLogKeys)),
+		confirm:    components.NewConfirm(),
+		palette:    components.NewPalette(),
+		command:    components.NewCommandLine(),
+		service:    service,
+		state:      state,
+		layout:     layout,
 	}
 
 	return m, nil
 }
 
+// loadTheme registers the user's custom themes and activates the configured theme
+func loadTheme(ui config.UISettings) error {
+	themeFile := ui.ThemeFile
+	if themeFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			themeFile = filepath.Join(homeDir, ".jenkins-tui", "themes.yaml")
+		}
+	}
+
+	if err := utils.LoadThemeFile(themeFile); err != nil {
+		return err
+	}
+
+	if err := utils.SetTheme(ui.Theme); err != nil {
+		return fmt.Errorf("invalid theme setting: %v", err)
+	}
+
+	return nil
+}
+
+// switchTheme activates the named theme and restyles all components
+func (m Model) switchTheme(name string) Model {
+	if err := utils.SetTheme(name); err != nil {
+		m.errorMsg = err.Error()
+		return m
+	}
+
+	m.jobList = m.jobList.RefreshStyles()
+	m.jobDetail = m.jobDetail.RefreshStyles()
+	m.buildLog = m.buildLog.RefreshStyles()
+	m.logDiff = m.logDiff.RefreshStyles()
+	m.errorLog = m.errorLog.RefreshStyles()
+	m.spinner.Style = lipgloss.NewStyle().Foreground(utils.ColorPrimary)
+	m.statusMessage = fmt.Sprintf("Theme: %s", name)
+	return m
+}
+
+// activeKeys returns the keybindings of the current view
+func (m Model) activeKeys() components.KeyMap {
+	return m.keyMaps.For(viewKeys[m.currentView])
+}
+
+// inputCaptured reports whether the current view is reading text input
+func (m Model) inputCaptured() bool {
+	switch m.currentView {
+	case JobListView:
+		return m.jobList.Capturing()
+	case JobDetailView:
+		return m.jobDetail.Capturing()
+	case BuildLogView:
+		return m.buildLog.Capturing()
+	}
+	return false
+}
+
 // Connect initiates a connection to the Jenkins server
 func (m Model) Connect() tea.Cmd {
-	return func() tea.Msg {
+	return m.fetch("Connecting", func() tea.Msg {
 		err := m.service.Connect()
 		if err != nil {
 			return connectMsg{err: err}
 		}
 		return connectMsg{serverInfo: m.service.GetServerInfo()}
-	}
+	})
+}
+
+// canFetch reports whether data can be requested from the service, either
+// from the server or, while offline, from the cache
+func (m Model) canFetch() bool {
+	return m.conn.Online() || m.service.HasCache()
 }
 
 // FetchJobs retrieves the list of Jenkins jobs
 func (m Model) FetchJobs() tea.Cmd {
-	return func() tea.Msg {
+	return m.fetch("Fetching jobs", func() tea.Msg {
 		jobs, err := m.service.GetJobs()
 		return fetchJobsMsg{jobs: jobs, err: err}
-	}
+	})
+}
+
+// FetchLoad retrieves the build queue and the usage of the executors
+func (m Model) FetchLoad() tea.Cmd {
+	return m.fetch("Fetching build queue", func() tea.Msg {
+		var msg fetchLoadMsg
+		msg.usage, msg.err = m.service.GetExecutorUsage()
+		if msg.err == nil {
+			msg.queue, msg.err = m.service.GetQueue()
+		}
+		return msg
+	})
 }
 
 // FetchJobDetail retrieves detailed information about a specific job
 func (m Model) FetchJobDetail(jobName string) tea.Cmd {
-	return func() tea.Msg {
+	return m.fetch("Fetching "+jobName, func() tea.Msg {
 		jobDetail, err := m.service.GetJobDetails(jobName)
 		return fetchJobDetailMsg{jobDetail: jobDetail, err: err}
-	}
+	})
+}
+
+// FetchBuildHistory retrieves the builds of a job started since a time for
+// its reliability statistics
+func (m Model) FetchBuildHistory(jobName string, since time.Time) tea.Cmd {
+	return m.fetch(fmt.Sprintf("Fetching build history of %s", jobName), func() tea.Msg {
+		builds, whole, err := m.service.GetBuildHistory(jobName, since)
+		return fetchBuildHistoryMsg{jobName: jobName, builds: builds, whole: whole, err: err}
+	})
 }
 
 // FetchBuildDetail retrieves detailed information about a specific build
 func (m Model) FetchBuildDetail(jobName string, buildNumber int) tea.Cmd {
-	return func() tea.Msg {
+	return m.fetch(fmt.Sprintf("Fetching build #%d", buildNumber), func() tea.Msg {
 		buildDetail, err := m.service.GetBuildDetails(jobName, buildNumber)
 		return fetchBuildDetailMsg{buildDetail: buildDetail, err: err}
-	}
+	})
 }
 
-// FetchBuildLog retrieves the console output for a specific build
+// FetchBuildLog retrieves the first range of the console output for a
+// specific build; the build log view asks for the rest as it needs it
 func (m Model) FetchBuildLog(jobName string, buildNumber int) tea.Cmd {
-	return func() tea.Msg {
-		log, err := m.service.GetBuildLog(jobName, buildNumber)
-		return fetchBuildLogMsg{buildLog: log, err: err}
+	return m.FetchBuildLogRange(jobName, buildNumber, 0)
+}
+
+// FetchBuildLogRange retrieves the console output for a specific build from
+// a byte offset on
+func (m Model) FetchBuildLogRange(jobName string, buildNumber int, offset int64) tea.Cmd {
+	return m.fetch(fmt.Sprintf("Fetching log of build #%d", buildNumber), func() tea.Msg {
+		chunk, err := m.service.GetBuildLogRange(jobName, buildNumber, offset)
+		return fetchBuildLogMsg{jobName: jobName, buildNumber: buildNumber, offset: offset, chunk: chunk, err: err}
+	})
+}
+
+// syncBuildStatus tells the build log the status of the selected build,
+// whose log is only searched for failures if it failed
+func (m Model) syncBuildStatus() Model {
+	if m.selectedJob == "" || m.selectedBuild == 0 {
+		return m
+	}
+	status := ""
+	if build := m.jobDetail.Build(m.selectedBuild); build != nil {
+		status = build.Status
 	}
+	m.buildLog = m.buildLog.WithBuildStatus(m.selectedJob, m.selectedBuild, status)
+	return m
+}
+
+// FetchFailureSummary scans the console output of a build for known
+// failure signatures, a range at a time so that long logs are never held
+// as lines
+func (m Model) FetchFailureSummary(jobName string, buildNumber int) tea.Cmd {
+	return m.fetch(fmt.Sprintf("Analyzing build #%d", buildNumber), func() tea.Msg {
+		msg := fetchFailureSummaryMsg{jobName: jobName, buildNumber: buildNumber}
+		var offset int64
+		first := 0
+		for {
+			r, err := m.service.GetBuildLogRange(jobName, buildNumber, offset)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+
+			// Ranges end on line breaks, except the last one
+			lines := strings.Split(strings.TrimSuffix(r.Text, "\n"), "\n")
+			for i, line := range lines {
+				lines[i] = utils.StripANSI(line)
+			}
+			msg.hits = m.analyzer.AnalyzeRange(msg.hits, lines, first)
+			first += len(lines)
+			offset = r.Next
+
+			if r.Complete {
+				return msg
+			}
+		}
+	})
+}
+
+// FetchLogDiff retrieves the console output of two builds and compares it,
+// away from the UI since long logs take a while to compare
+func (m Model) FetchLogDiff(jobName string, oldBuild, newBuild int) tea.Cmd {
+	logDiff := m.logDiff
+	return m.fetch(fmt.Sprintf("Comparing logs of builds #%d and #%d", oldBuild, newBuild), func() tea.Msg {
+		msg := fetchLogDiffMsg{jobName: jobName, oldBuild: oldBuild, newBuild: newBuild}
+		oldLog, err := m.service.GetBuildLog(jobName, oldBuild)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		newLog, err := m.service.GetBuildLog(jobName, newBuild)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.diff = logDiff.CompareLogs(oldLog, newLog)
+		return msg
+	})
 }
 
 // RefreshTick creates a command that will send a tick message after a duration
@@ -165,24 +503,76 @@ func (m Model) Init() tea.Cmd {
 		m.jobList.Init(),
 		m.jobDetail.Init(),
 		m.buildLog.Init(),
+		m.logDiff.Init(),
 		m.helpView.Init(),
 		m.Connect(),
 		RefreshTick(30*time.Second),
 	)
 }
 
-// Update implements bubbletea.Model
+// Update implements bubbletea.Model, remembering the places left for the
+// navigation history, following the state of the connection and reporting
+// the error of the message, if any
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	next, connCmd := next.recordMove(m).syncConnection()
+	next, errCmd := next.reportError()
+	return next, tea.Batch(cmd, connCmd, errCmd)
+}
+
+// update handles a message
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// Toasts expire whatever the current view
+	var toastCmd tea.Cmd
+	m.toasts, toastCmd = m.toasts.Update(msg)
+	cmds = append(cmds, toastCmd)
+
 	switch msg := msg.(type) {
+	case requestStartedMsg:
+		var cmd tea.Cmd
+		m, cmd = m.requestStarted(msg.label)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case requestDoneMsg:
+		// Handle the result as if it came on its own
+		var cmd tea.Cmd
+		m, cmd = m.requestDone(msg.label).update(msg.result)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case fetchedMsg:
+		if msg.session != m.service.Session() {
+			return m, tea.Batch(cmds...)
+		}
+		var cmd tea.Cmd
+		m, cmd = m.update(msg.result)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m, cmd = m.spin(msg)
+		return m, tea.Batch(append(cmds, cmd)...)
+
 	case connectMsg:
 		if msg.err != nil {
-			m.connected = false
 			m.errorMsg = fmt.Sprintf("Connection error: %v", msg.err)
 			m.statusMessage = "Connection failed"
+
+			// Keep trying unless the credentials are wrong
+			if state, _ := m.service.State(); state != StateAuthFailed {
+				var cmd tea.Cmd
+				m, cmd = m.scheduleReconnect()
+				cmds = append(cmds, cmd)
+			}
+
+			// Cached jobs and builds can still be browsed
+			if m.service.HasCache() {
+				m.statusMessage = "Offline: showing cached jobs and builds"
+				cmds = append(cmds, m.FetchJobs())
+			}
 		} else {
-			m.connected = true
+			m = m.cancelReconnect()
 			m.serverURL = msg.serverInfo.URL
 			m.statusMessage = "Connected to Jenkins"
 
@@ -198,27 +588,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.dashboard = m.dashboard.WithServerInfo(serverInfo)
 
-			// Fetch jobs
-			cmds = append(cmds, m.FetchJobs())
+			// Fetch jobs and the load of the server, and the job in view
+			cmds = append(cmds, m.FetchJobs(), m.FetchLoad())
+			if m.selectedJob != "" {
+				cmds = append(cmds, m.FetchJobDetail(m.selectedJob))
+			}
 		}
 
 	case fetchJobsMsg:
 		if msg.err != nil {
 			m.errorMsg = fmt.Sprintf("Failed to fetch jobs: %v", msg.err)
 		} else {
-			// Update the job list
-			var jobItems []components.JobListItem
-			for _, job := range msg.jobs {
-				jobItem := components.JobListItem{
-					Name:      job.Name,
-					Status:    string(job.Status),
-					LastBuild: time.Now().Add(-time.Hour), // This would come from the API
-					JobDesc:   job.Description,
-					URL:       job.URL,
+			// Notify what happened to watched jobs since the last refresh
+			if m.jobStates != nil {
+				for _, event := range watchEvents(m.jobStates, msg.jobs, m.isWatched) {
+					cmds = append(cmds, m.notify(event)...)
+					var cmd tea.Cmd
+					m.toasts, cmd = m.toasts.Push(event.message(), event.level())
+					cmds = append(cmds, cmd)
 				}
-				jobItems = append(jobItems, jobItem)
 			}
-			m.jobList = m.jobList.WithJobs(jobItems)
+			m.jobStates = make(map[string]api.Job, len(msg.jobs))
+			for _, job := range msg.jobs {
+				m.jobStates[job.Name] = job
+			}
+
+			m.jobs = msg.jobs
+			m = m.showJobs()
 		}
 
 	case fetchJobDetailMsg:
@@ -229,23 +625,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			jobDetail := msg.jobDetail
 			m.jobDetail = m.jobDetail.WithJobDetail(jobDetail.Name, jobDetail.Description, jobDetail.URL)
 
-			// If there are builds, add them
-			if len(jobDetail.Builds) > 0 {
-				var builds []components.BuildInfo
-				for _, build := range jobDetail.Builds {
-					buildInfo := components.BuildInfo{
-						Number:    build.Number,
-						Status:    string(build.Status),
-						StartTime: time.Unix(build.StartTime/1000, 0),
-						Duration:  time.Duration(build.Duration) * time.Millisecond,
-					}
-					builds = append(builds, buildInfo)
+			var builds []components.BuildInfo
+			for _, build := range jobDetail.Builds {
+				buildInfo := components.BuildInfo{
+					Number:    build.Number,
+					Status:    string(build.Status),
+					StartTime: time.Unix(build.StartTime/1000, 0),
+					Duration:  time.Duration(build.Duration) * time.Millisecond,
 				}
+				builds = append(builds, buildInfo)
+			}
+			m.buildChart = m.buildChart.WithBuilds(jobDetail.Name, builds)
+			// Jenkins lists the last builds only; the statistics window may
+			// need more of them
+			m.jobDetail = m.jobDetail.WithHistory(jobDetail.Builds, len(jobDetail.Builds) < stats.HistoryLimit)
+			var historyCmd tea.Cmd
+			m.jobDetail, historyCmd = m.jobDetail.RequestHistory()
+			cmds = append(cmds, historyCmd)
+
+			// If there are builds, add them
+			if len(builds) > 0 {
 				m.jobDetail = m.jobDetail.WithBuilds(builds)
+				m = m.syncBuildStatus()
 
-				// Fetch the last build details
+				// Fetch the last build details, keeping the build whose log
+				// is open if the job was opened at one of its builds
 				if jobDetail.LastBuild != nil {
-					m.selectedBuild = jobDetail.LastBuild.Number
+					if m.currentView != BuildLogView {
+						m.selectedBuild = jobDetail.LastBuild.Number
+					}
 					cmds = append(cmds, m.FetchBuildDetail(jobDetail.Name, jobDetail.LastBuild.Number))
 				}
 			}
@@ -257,29 +665,241 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			// Update the build detail
 			buildDetail := msg.buildDetail
+			status := api.GetStatusFromResult(buildDetail.Result, buildDetail.Building)
 			m.jobDetail = m.jobDetail.WithLastBuildInfo(components.Build{
 				Number:      buildDetail.Number,
-				Status:      string(api.GetStatusFromResult(buildDetail.Result, buildDetail.Building)),
+				Status:      string(status),
 				StartTime:   time.Unix(buildDetail.StartTime/1000, 0),
 				Duration:    time.Duration(buildDetail.Duration) * time.Millisecond,
 				Description: buildDetail.Description,
 				Parameters:  buildDetail.Parameters,
 			})
+			m = m.syncBuildStatus()
+
+			// Look for the cause of a failed build, once: its log does not
+			// change, and the build log may have analysed it already
+			if status == api.StatusFailed && m.selectedJob != "" && !m.jobDetail.HasFailureSummary(buildDetail.Number) {
+				if hits, ok := m.buildLog.FailureHits(m.selectedJob, buildDetail.Number); ok {
+					m.jobDetail = m.jobDetail.WithFailureSummary(buildDetail.Number, hits)
+				} else {
+					cmds = append(cmds, m.FetchFailureSummary(m.selectedJob, buildDetail.Number))
+				}
+			}
+		}
+
+	case fetchFailureSummaryMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to analyze build log: %v", msg.err)
+		} else if msg.jobName == m.selectedJob {
+			m.jobDetail = m.jobDetail.WithFailureSummary(msg.buildNumber, msg.hits)
 		}
 
 	case fetchBuildLogMsg:
 		if msg.err != nil {
 			m.errorMsg = fmt.Sprintf("Failed to fetch build log: %v", msg.err)
-		} else {
+		} else if msg.jobName == m.selectedJob && msg.buildNumber == m.selectedBuild {
+			// Update the job and build number for display purposes and for
+			// the requests of the following ranges
+			m = m.syncBuildStatus()
+			m.buildLog = m.buildLog.WithJobAndBuild(msg.jobName, msg.buildNumber)
 			// Update the build log
-			m.buildLog = m.buildLog.WithLog(msg.buildLog)
-			// Also update the job and build number for display purposes
-			m.buildLog = m.buildLog.WithJobAndBuild(m.selectedJob, m.selectedBuild)
+			m.buildLog = m.buildLog.WithLogRange(msg.offset, msg.chunk.Text, msg.chunk.Next, msg.chunk.Complete)
+
+			var cmd tea.Cmd
+			m.buildLog, cmd = m.buildLog.LoadMore()
+			cmds = append(cmds, cmd)
+		}
+
+	case components.BuildHistoryRequestMsg:
+		if m.canFetch() {
+			cmds = append(cmds, m.FetchBuildHistory(msg.JobName, msg.Since))
+		}
+
+	case fetchBuildHistoryMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to fetch build history: %v", msg.err)
+		} else if msg.jobName == m.selectedJob {
+			m.jobDetail = m.jobDetail.WithHistory(msg.builds, msg.whole)
+		}
+
+	case components.LogRangeRequestMsg:
+		if m.canFetch() {
+			cmds = append(cmds, m.FetchBuildLogRange(msg.JobName, msg.BuildNumber, msg.Offset))
+		}
+
+	case fetchLogDiffMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to fetch build logs for comparison: %v", msg.err)
+		} else {
+			m.logDiff = m.logDiff.WithBuilds(msg.jobName, msg.oldBuild, msg.newBuild).WithDiff(msg.diff)
+		}
+
+	case fetchLoadMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to fetch build queue: %v", msg.err)
+		} else {
+			queue := make([]components.QueueEntry, len(msg.queue))
+			for i, item := range msg.queue {
+				queue[i] = components.QueueEntry{
+					JobName: item.JobName,
+					Why:     item.Why,
+					Since:   time.UnixMilli(item.InQueueSince),
+					Stuck:   item.Stuck,
+				}
+			}
+			m.dashboard = m.dashboard.WithQueue(queue).WithExecutors(msg.usage.Busy, msg.usage.Total)
+		}
+
+	case components.DashboardTickMsg:
+		// Keep redrawing so that the progress of running builds moves
+		cmds = append(cmds, components.DashboardTick())
+
+	case bellMsg:
+		var cmd tea.Cmd
+		m, cmd = m.ring()
+		cmds = append(cmds, cmd)
+
+	case bellDoneMsg:
+		m = m.bellDone(msg)
+
+	case watchCommandMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Watch command failed: %v", msg.err)
 		}
 
+	case statsExportMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to export statistics: %v", msg.err)
+		} else {
+			text, level := fmt.Sprintf("Statistics of %d jobs saved to %s", msg.jobs, msg.path), components.ToastSuccess
+			if msg.failed > 0 {
+				text, level = fmt.Sprintf("%s (%d jobs could not be fetched)", text, msg.failed), components.ToastWarning
+			}
+			var cmd tea.Cmd
+			m.toasts, cmd = m.toasts.Push(text, level)
+			m.statusMessage = text
+			cmds = append(cmds, cmd)
+		}
+
+	case components.OpenMsg:
+		if m.canOpenSelected() {
+			var cmd tea.Cmd
+			m, cmd = m.openSelected()
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case components.OpenURLMsg:
+		if err := utils.OpenURL(msg.URL); err != nil {
+			m.errorMsg = err.Error()
+		} else {
+			m.statusMessage = fmt.Sprintf("Opened %s", msg.URL)
+		}
+		return m, tea.Batch(cmds...)
+
+	case tea.MouseMsg:
+		// Dialogs are driven by the keyboard
+		if m.confirm.Active() || m.palette.Active() || m.command.Active() {
+			return m, tea.Batch(cmds...)
+		}
+
+		if msg.Y < headerHeight {
+			if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+				if t, ok := m.tabAt(msg.X); ok && msg.Y == 0 {
+					var cmd tea.Cmd
+					m, cmd = m.openTab(t)
+					cmds = append(cmds, cmd)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Components place the mouse relative to their own view
+		msg.Y -= headerHeight
+		view := m.currentView
+		if m.layout.shows(m.currentView) {
+			p, ok := m.layout.paneAt(msg.X, msg.Y)
+			if !ok {
+				return m, tea.Batch(cmds...)
+			}
+
+			// Clicking a pane focuses it
+			view = p.view
+			if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+				m = m.focusPane(view)
+			}
+			msg.X -= p.x + 1
+			msg.Y -= p.y + 1
+			if msg.X < 0 || msg.Y < 0 || msg.X >= p.width-2 || msg.Y >= p.height-2 {
+				return m, tea.Batch(cmds...)
+			}
+		}
+		var cmd tea.Cmd
+		m, cmd = m.updateView(view, msg)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case components.PaletteMsg:
+		var cmd tea.Cmd
+		m, cmd = m.runPaletteEntry(msg.Entry)
+		cmds = append(cmds, cmd)
+
+	case components.CommandMsg:
+		m.state.AddCommand(msg.Line)
+		if err := m.state.Save(); err != nil {
+			m.errorMsg = err.Error()
+		}
+		var cmd tea.Cmd
+		m, cmd = m.runCommand(msg.Line)
+		cmds = append(cmds, cmd)
+
+	case fetchNodesMsg:
+		if msg.err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to fetch nodes: %v", msg.err)
+		} else {
+			m.statusMessage = nodesSummary(msg.nodes)
+		}
+
+	case components.ConfirmMsg:
+		if msg.Confirmed && m.pendingAction != nil {
+			cmds = append(cmds, request("Working", m.pendingAction))
+		}
+		m.pendingAction = nil
+
+	case bulkResultMsg:
+		text, level := msg.message()
+		var cmd tea.Cmd
+		m.toasts, cmd = m.toasts.Push(text, level)
+		cmds = append(cmds, cmd)
+		m.statusMessage = text
+		if len(msg.failed) > 0 {
+			m = m.logError(text, msg.errors())
+		} else {
+			m.jobList = m.jobList.ClearMarks()
+		}
+		cmds = append(cmds, m.FetchJobs())
+
+	case actionResultMsg:
+		var cmd tea.Cmd
+		m, cmd = m.actionDone(msg)
+		cmds = append(cmds, cmd)
+
+	case components.LogExportMsg:
+		switch {
+		case msg.Err != nil:
+			m.errorMsg = fmt.Sprintf("Failed to export build log: %v", msg.Err)
+		case msg.Action == "saved":
+			m.statusMessage = fmt.Sprintf("Build log saved to %s", msg.Path)
+		}
+
+	case reconnectMsg:
+		var cmd tea.Cmd
+		m, cmd = m.reconnect(msg)
+		cmds = append(cmds, cmd)
+
 	case RefreshTickMsg:
-		// Check if it's time to refresh
-		if m.service.ShouldRefresh() {
+		// Check if it's time to refresh; while offline, the attempts to
+		// reconnect take over
+		if m.service.ShouldRefresh() && m.conn.Online() {
 			cmds = append(cmds, m.Connect())
 		}
 
@@ -287,90 +907,175 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, RefreshTick(30*time.Second))
 
 	case tea.KeyMsg:
+		// An open dialog takes every key
+		if m.confirm.Active() {
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			return m, cmd
+		}
+		if m.palette.Active() {
+			var cmd tea.Cmd
+			m.palette, cmd = m.palette.Update(msg)
+			return m, cmd
+		}
+		if m.command.Active() {
+			var cmd tea.Cmd
+			m.command, cmd = m.command.Update(msg)
+			return m, cmd
+		}
+
+		// Text inputs get every key except the emergency exit
+		if m.inputCaptured() && msg.Type != tea.KeyCtrlC {
+			break
+		}
+
+		keys := m.activeKeys()
 		switch {
-		case key.Matches(msg, m.keys.Quit):
+		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
 
-		case key.Matches(msg, m.keys.Help):
+		case key.Matches(msg, keys.Help):
 			if m.currentView == HelpView {
 				// If we're already in help view, go back to previous view
-				m.currentView = DashboardView
-				m.statusMessage = "Dashboard View"
-			} else {
-				// Otherwise show help view
-				m.currentView = HelpView
-				m.statusMessage = "Help View"
+				return m.goBack()
 			}
+			m.currentView = HelpView
+			m.statusMessage = "Help View"
 			return m, nil
 
-		case key.Matches(msg, m.keys.Refresh):
+		case key.Matches(msg, keys.ErrorLog):
+			if m.currentView == ErrorLogView {
+				return m.goBack()
+			}
+			return m.openErrorLog(), nil
+
+		case key.Matches(msg, keys.Palette):
+			m.palette = m.palette.Open(m.paletteEntries())
+			return m, nil
+
+		case key.Matches(msg, keys.Command):
+			m.command = m.command.Open(m.commandCompletions(), m.state.Commands)
+			return m, nil
+
+		case key.Matches(msg, keys.ToggleSplit):
+			m.layout = m.layout.toggle()
+			if m.layout.split {
+				m.statusMessage = "Split panes on"
+			} else {
+				m.statusMessage = "Split panes off"
+			}
+			m = m.saveLayout()
+			return m.resize()
+
+		case key.Matches(msg, keys.NextPane) && m.layout.shows(m.currentView):
+			return m.focusPane(m.layout.next(m.currentView, 1)), nil
+
+		case key.Matches(msg, keys.GrowPane) && m.layout.shows(m.currentView):
+			return m.resizePane(resizeStep)
+
+		case key.Matches(msg, keys.ShrinkPane) && m.layout.shows(m.currentView):
+			return m.resizePane(-resizeStep)
+
+		case key.Matches(msg, keys.Refresh):
 			cmds = append(cmds, m.Connect())
 
-		case key.Matches(msg, m.keys.Dashboard):
+		case key.Matches(msg, keys.Theme):
+			return m.switchTheme(utils.NextThemeName()), nil
+
+		case key.Matches(msg, keys.Watch):
+			return m.toggleWatched(), nil
+
+		case key.Matches(msg, keys.Dashboard):
 			m.currentView = DashboardView
 			m.statusMessage = "Dashboard View"
 			return m, nil
 
-		case key.Matches(msg, m.keys.Jobs):
+		case key.Matches(msg, keys.Jobs):
 			m.currentView = JobListView
 			m.statusMessage = "Job List View"
 
 			// Refresh the job list when viewing it
-			if m.connected {
+			if m.canFetch() {
 				cmds = append(cmds, m.FetchJobs())
 			}
 
 			return m, tea.Batch(cmds...)
 
-		case key.Matches(msg, m.keys.Enter):
-			if m.currentView == JobListView {
-				// Get the selected job
-				selected := m.jobList.GetSelected()
-				if selected != nil {
-					m.selectedJob = selected.Name
-					m.currentView = JobDetailView
-					m.statusMessage = fmt.Sprintf("Job: %s", selected.Name)
-
-					// Fetch job details
-					if m.connected {
-						cmds = append(cmds, m.FetchJobDetail(selected.Name))
-					}
+		case key.Matches(msg, keys.Enter) && m.canOpenSelected():
+			var cmd tea.Cmd
+			m, cmd = m.openSelected()
+			return m, tea.Batch(append(cmds, cmd)...)
 
-					return m, tea.Batch(cmds...)
-				}
+		case key.Matches(msg, keys.BuildJobs):
+			return m.confirmBulk(bulkBuild), nil
+
+		case key.Matches(msg, keys.StopJobs):
+			return m.confirmBulk(bulkStop), nil
+
+		case key.Matches(msg, keys.EnableJobs):
+			return m.confirmBulk(bulkEnable), nil
+
+		case key.Matches(msg, keys.DisableJobs):
+			return m.confirmBulk(bulkDisable), nil
+
+		case key.Matches(msg, keys.StopBuild):
+			return m.runAction(actionStop)
+
+		case key.Matches(msg, keys.DeleteJob):
+			return m.runAction(actionDeleteJob)
+
+		case key.Matches(msg, keys.DeleteBuild):
+			return m.runAction(actionDeleteBuild)
+
+		case key.Matches(msg, keys.ExportStats):
+			if !m.canFetch() || len(m.jobs) == 0 {
+				m.statusMessage = "No jobs to export statistics for"
 				return m, nil
-			} else if m.currentView == JobDetailView {
-				// Get the selected build
-				selected := m.jobDetail.GetSelectedBuild()
-				if selected != nil {
-					m.selectedBuild = selected.Number
-					m.currentView = BuildLogView
-					m.statusMessage = fmt.Sprintf("Build #%d Logs", selected.Number)
-
-					// Fetch build logs
-					if m.connected && m.selectedJob != "" {
-						cmds = append(cmds, m.FetchBuildLog(m.selectedJob, selected.Number))
-					}
+			}
+			m.statusMessage = fmt.Sprintf("Exporting statistics of %d jobs...", len(m.jobs))
+			return m, m.ExportStats()
 
-					return m, tea.Batch(cmds...)
-				}
+		case key.Matches(msg, keys.Chart):
+			m.currentView = BuildChartView
+			m.statusMessage = fmt.Sprintf("Build Durations: %s", m.selectedJob)
+			return m, nil
+
+		case m.currentView == JobDetailView && key.Matches(msg, keys.NextFailure):
+			// Open the failed last build at its first failure
+			buildNumber := m.jobDetail.FailedBuild()
+			if buildNumber == 0 {
+				break
+			}
+
+			m.selectedBuild = buildNumber
+			m.currentView = BuildLogView
+			m.statusMessage = fmt.Sprintf("Build #%d Logs", buildNumber)
+			m.buildLog = m.buildLog.WithJumpToFailure()
+			if m.canFetch() && m.selectedJob != "" {
+				cmds = append(cmds, m.FetchBuildLog(m.selectedJob, buildNumber))
+			}
+			return m, tea.Batch(cmds...)
+
+		case key.Matches(msg, keys.CompareBuilds):
+			marked := m.jobDetail.MarkedBuilds()
+			if len(marked) != 2 {
+				m.statusMessage = fmt.Sprintf("Mark two builds with %s to compare their logs", keys.MarkBuild.Help().Key)
 				return m, nil
 			}
 
-		case key.Matches(msg, m.keys.Back):
-			// Handle navigation back
-			switch m.currentView {
-			case JobDetailView:
-				m.currentView = JobListView
-				m.statusMessage = "Job List View"
-			case BuildLogView:
-				m.currentView = JobDetailView
-				m.statusMessage = "Job Detail View"
-			case HelpView:
-				m.currentView = DashboardView
-				m.statusMessage = "Dashboard View"
+			m.currentView = LogDiffView
+			m.statusMessage = fmt.Sprintf("Comparing builds #%d and #%d", marked[0], marked[1])
+			m.logDiff = m.logDiff.WithBuilds(m.selectedJob, marked[0], marked[1])
+			if m.canFetch() && m.selectedJob != "" {
+				cmds = append(cmds, m.FetchLogDiff(m.selectedJob, marked[0], marked[1]))
 			}
-			return m, nil
+			return m, tea.Batch(cmds...)
+
+		case key.Matches(msg, keys.Back):
+			return m.goBack()
+
+		case key.Matches(msg, keys.Forward):
+			return m.goForward()
 		}
 
 	case tea.WindowSizeMsg:
@@ -378,81 +1083,187 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.help.Width = msg.Width
 
-		// Update component sizes
 		var cmd tea.Cmd
+		m, cmd = m.resize()
+		return m, tea.Batch(append(cmds, cmd)...)
+	}
 
-		m.dashboard, cmd = m.dashboard.Update(msg)
-		cmds = append(cmds, cmd)
+	// Handle component updates based on current view
+	var cmd tea.Cmd
+	m, cmd = m.updateView(m.currentView, msg)
+	cmds = append(cmds, cmd)
 
-		m.jobList, cmd = m.jobList.Update(msg)
-		cmds = append(cmds, cmd)
+	return m, tea.Batch(cmds...)
+}
 
-		m.jobDetail, cmd = m.jobDetail.Update(msg)
-		cmds = append(cmds, cmd)
+// resize gives every component its share of the screen: the area between
+// the tab bar and the status bar, or its pane when it is in the split
+func (m Model) resize() (Model, tea.Cmd) {
+	m.layout = m.layout.withSize(m.width, max(m.height-headerHeight-footerHeight, 0))
+	full := tea.WindowSizeMsg{Width: m.layout.width, Height: m.layout.height}
+	sizeOf := func(view ViewType) tea.WindowSizeMsg {
+		if p, ok := m.layout.pane(view); ok {
+			// Inside the border
+			return tea.WindowSizeMsg{Width: p.width - 2, Height: p.height - 2}
+		}
+		return full
+	}
 
-		m.buildLog, cmd = m.buildLog.Update(msg)
-		cmds = append(cmds, cmd)
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
 
-		m.helpView, cmd = m.helpView.Update(msg)
-		cmds = append(cmds, cmd)
+	m.dashboard, cmd = m.dashboard.Update(full)
+	cmds = append(cmds, cmd)
 
-		return m, tea.Batch(cmds...)
-	}
+	m.jobList, cmd = m.jobList.Update(sizeOf(JobListView))
+	cmds = append(cmds, cmd)
 
-	// Handle component updates based on current view
-	switch m.currentView {
+	m.jobDetail, cmd = m.jobDetail.Update(sizeOf(JobDetailView))
+	cmds = append(cmds, cmd)
+
+	m.buildLog, cmd = m.buildLog.Update(sizeOf(BuildLogView))
+	cmds = append(cmds, cmd)
+
+	m.logDiff, cmd = m.logDiff.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.buildChart, cmd = m.buildChart.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.confirm, cmd = m.confirm.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.palette, cmd = m.palette.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.command, cmd = m.command.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.helpView, cmd = m.helpView.Update(full)
+	cmds = append(cmds, cmd)
+
+	m.errorLog, cmd = m.errorLog.Update(full)
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// updateView passes a message to the component of a view
+func (m Model) updateView(view ViewType, msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch view {
 	case DashboardView:
-		var cmd tea.Cmd
 		m.dashboard, cmd = m.dashboard.Update(msg)
-		cmds = append(cmds, cmd)
 	case JobListView:
-		var cmd tea.Cmd
 		m.jobList, cmd = m.jobList.Update(msg)
-		cmds = append(cmds, cmd)
 	case JobDetailView:
-		var cmd tea.Cmd
 		m.jobDetail, cmd = m.jobDetail.Update(msg)
-		cmds = append(cmds, cmd)
 	case BuildLogView:
-		var cmd tea.Cmd
 		m.buildLog, cmd = m.buildLog.Update(msg)
-		cmds = append(cmds, cmd)
+	case LogDiffView:
+		m.logDiff, cmd = m.logDiff.Update(msg)
+	case BuildChartView:
+		m.buildChart, cmd = m.buildChart.Update(msg)
+	case ErrorLogView:
+		m.errorLog, cmd = m.errorLog.Update(msg)
 	}
-
-	return m, tea.Batch(cmds...)
+	return m, cmd
 }
 
 // View implements bubbletea.Model
 func (m Model) View() string {
-	// Status bar at the bottom
+	// Status bar at the bottom, after the state of the connection and the
+	// requests in flight, or the command line in command mode
 	statusBar := utils.StatusBar.Render(m.statusMessage)
+	if requests := m.requestStatus(); requests != "" {
+		statusBar = requests + " " + statusBar
+	}
+	statusBar = m.connectionStatus() + " " + statusBar
+	if m.command.Active() {
+		statusBar = m.command.View()
+	}
 
-	// Error message
+	// Errors not looked at yet
 	var errorView string
-	if m.errorMsg != "" {
-		errorStyle := utils.FailureText.Render(m.errorMsg)
-		errorView = fmt.Sprintf("\n%s", errorStyle)
+	if hint := m.errorHint(); hint != "" {
+		errorView = fmt.Sprintf("\n%s", hint)
 	}
 
 	// Help at the bottom
-	helpView := m.help.View(m.keys)
+	helpView := m.help.View(m.activeKeys())
 
-	// Main content
-	var content string
-	switch m.currentView {
+	// Main content: the view, or the split while one of its views is open
+	content := m.viewContent(m.currentView)
+	if m.layout.shows(m.currentView) {
+		content = m.layout.render(m.paneContent, m.currentView)
+	}
+
+	// An open dialog or the palette covers the view
+	if m.palette.Active() {
+		content = m.palette.View()
+	}
+	if m.confirm.Active() {
+		content = m.confirm.View()
+	}
+
+	// Notifications cover the bottom right of the content, whose size they
+	// leave as it is
+	if toasts := m.toasts.View(); toasts != "" {
+		content = overlayToasts(content, toasts, m.width, max(m.height-headerHeight-footerHeight, 0))
+	}
+
+	// Where the user is, and how old the data is while the server is away
+	breadcrumbs := m.breadcrumbs()
+	if note := m.staleNote(); note != "" {
+		breadcrumbs += "  " + note
+	}
+
+	// The bell goes at the end of the last line, which the renderer only
+	// draws again when it changes, so that it rings once
+	bell := ""
+	if m.ringing {
+		bell = "\a"
+	}
+
+	// Combine everything
+	return fmt.Sprintf("%s\n%s\n%s\n\n%s\n\n%s%s%s", m.tabBar(), breadcrumbs, content, statusBar, helpView, errorView, bell)
+}
+
+// overlayToasts draws the rows of the toasts over the last rows of content,
+// once it is cut or padded to the view area, aligned to its right edge
+func overlayToasts(content, toasts string, width, height int) string {
+	rows := strings.Split(fitBlock(content, width, height), "\n")
+	stack := strings.Split(toasts, "\n")
+	if len(stack) > len(rows) {
+		stack = stack[len(stack)-len(rows):]
+	}
+
+	for i, toast := range stack {
+		row := len(rows) - len(stack) + i
+		rows[row] = fitBlock(rows[row], width-lipgloss.Width(toast), 1) + toast
+	}
+	return strings.Join(rows, "\n")
+}
+
+// viewContent renders the component of a view
+func (m Model) viewContent(view ViewType) string {
+	switch view {
 	case DashboardView:
-		content = m.dashboard.View()
+		return m.dashboard.View()
 	case JobListView:
-		content = m.jobList.View()
+		return m.jobList.View()
 	case JobDetailView:
-		content = m.jobDetail.View()
+		return m.jobDetail.View()
 	case BuildLogView:
-		content = m.buildLog.View()
+		return m.buildLog.View()
+	case LogDiffView:
+		return m.logDiff.View()
+	case BuildChartView:
+		return m.buildChart.View()
 	case HelpView:
-		content = m.helpView.View()
+		return m.helpView.View()
+	case ErrorLogView:
+		return m.errorLog.View()
 	}
-
-	// Combine everything
-	return fmt.Sprintf("%s\n\n%s\n\n%s%s", content, statusBar, helpView, errorView)
+	return ""
 }
-
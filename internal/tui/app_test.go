@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOverlayToasts(t *testing.T) {
+	content := "jobs\nbuilds\nlog"
+	toasts := "   saved\nrefreshed"
+
+	tests := []struct {
+		name   string
+		height int
+		want   []string
+	}{
+		{"view area taller than the toasts", 4, []string{
+			"jobs        ",
+			"builds      ",
+			"log    saved",
+			"   refreshed",
+		}},
+		{"newest toasts kept when too many", 1, []string{
+			"jobrefreshed",
+		}},
+		{"content cut to the view area", 2, []string{
+			"jobs   saved",
+			"buirefreshed",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Split(overlayToasts(content, toasts, 12, tt.height), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+)
+
+// Actions that can be applied to many jobs at once
+const (
+	bulkBuild   = "build"
+	bulkStop    = "stop"
+	bulkEnable  = "enable"
+	bulkDisable = "disable"
+)
+
+// bulkResultMsg reports the outcome of a bulk action
+type bulkResultMsg struct {
+	action  string
+	done    []string
+	skipped []string // Jobs the action did not apply to
+	failed  []string // Jobs and their errors
+}
+
+// confirmBulk asks to confirm an action on the selected jobs, or on the job
+// under the cursor if none is selected
+func (m Model) confirmBulk(action string) Model {
+	return m.confirmJobs(action, m.jobList.Targets())
+}
+
+// confirmJobs asks to confirm an action on the given jobs
+func (m Model) confirmJobs(action string, jobs []string) Model {
+	if len(jobs) == 0 {
+		m.statusMessage = "No job selected"
+		return m
+	}
+
+	subject := fmt.Sprintf("%d jobs", len(jobs))
+	if len(jobs) == 1 {
+		subject = jobs[0]
+	}
+
+	var title string
+	lines := make([]string, len(jobs))
+	for i, job := range jobs {
+		lines[i] = "• " + job
+	}
+	switch action {
+	case bulkBuild:
+		title = fmt.Sprintf("Build %s?", subject)
+	case bulkStop:
+		title = fmt.Sprintf("Stop the running builds of %s?", subject)
+		for i, job := range jobs {
+			if build := m.runningBuild(job); build > 0 {
+				lines[i] += fmt.Sprintf(" #%d", build)
+			} else {
+				lines[i] += " (not running)"
+			}
+		}
+	case bulkEnable:
+		title = fmt.Sprintf("Enable %s?", subject)
+	case bulkDisable:
+		title = fmt.Sprintf("Disable %s?", subject)
+	}
+
+	m.confirm = m.confirm.Open(title, lines)
+	m.pendingAction = m.runBulk(action, jobs)
+	return m
+}
+
+// runningBuild returns the number of a job's running build, or 0
+func (m Model) runningBuild(job string) int {
+	state, ok := m.jobStates[job]
+	if !ok || state.LastBuild == nil || !state.LastBuild.Building {
+		return 0
+	}
+	return state.LastBuild.Number
+}
+
+// runBulk applies an action to the jobs one after the other, in order
+func (m Model) runBulk(action string, jobs []string) tea.Cmd {
+	states := make(map[string]api.Job, len(jobs))
+	for _, job := range jobs {
+		states[job] = m.jobStates[job]
+	}
+
+	return func() tea.Msg {
+		result := bulkResultMsg{action: action}
+		for _, job := range jobs {
+			var err error
+			switch action {
+			case bulkBuild:
+				// Parameterized jobs are built with their default values
+				var parameters map[string]string
+				if len(states[job].Parameters) > 0 {
+					parameters = map[string]string{}
+				}
+				err = m.service.TriggerBuild(job, parameters)
+			case bulkStop:
+				build := states[job].LastBuild
+				if build == nil || !build.Building {
+					result.skipped = append(result.skipped, job)
+					continue
+				}
+				err = m.service.StopBuild(job, build.Number, api.StopAbort)
+			case bulkEnable, bulkDisable:
+				err = m.service.SetJobEnabled(job, action == bulkEnable)
+			}
+
+			if err != nil {
+				result.failed = append(result.failed, fmt.Sprintf("%s: %v", job, err))
+			} else {
+				result.done = append(result.done, job)
+			}
+		}
+		return result
+	}
+}
+
+// message summarises the outcome for a toast
+func (r bulkResultMsg) message() (string, components.ToastLevel) {
+	verbs := map[string]string{
+		bulkBuild:   "Triggered builds of",
+		bulkStop:    "Stopped the builds of",
+		bulkEnable:  "Enabled",
+		bulkDisable: "Disabled",
+	}
+
+	subject := fmt.Sprintf("%d jobs", len(r.done))
+	if len(r.done) == 1 {
+		subject = r.done[0]
+	}
+	text := fmt.Sprintf("%s %s", verbs[r.action], subject)
+	if len(r.skipped) > 0 {
+		text += fmt.Sprintf(", %d not running", len(r.skipped))
+	}
+	if len(r.failed) > 0 {
+		return fmt.Sprintf("%s, %d failed", text, len(r.failed)), components.ToastError
+	}
+	return text, components.ToastSuccess
+}
+
+// errors lists the jobs the action failed for
+func (r bulkResultMsg) errors() string {
+	return strings.Join(r.failed, "; ")
+}
@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// commandNames lists the commands of the command line, completed with tab
+var commandNames = []string{
+	"job", "build", "jobs", "dashboard", "queue", "nodes", "server", "trigger",
+	"stop", "watch", "chart", "refresh", "theme", "export", "errors", "help", "quit",
+}
+
+// commandActions are the commands that run an action without arguments
+var commandActions = map[string]string{
+	"jobs":      actionJobs,
+	"dashboard": actionDashboard,
+	"queue":     actionQueue,
+	"stop":      actionStop,
+	"watch":     actionWatch,
+	"chart":     actionChart,
+	"refresh":   actionRefresh,
+	"export":    actionExportStats,
+	"errors":    actionErrors,
+	"help":      actionHelp,
+}
+
+// fetchNodesMsg carries the nodes listed for the nodes command
+type fetchNodesMsg struct {
+	nodes []api.Node
+	err   error
+}
+
+// commandCompletions returns the tab completion candidates of every command
+// from the data on screen
+func (m Model) commandCompletions() map[string][]string {
+	completions := map[string][]string{"": commandNames}
+
+	for _, job := range m.jobs {
+		completions["job"] = append(completions["job"], job.Name)
+	}
+
+	// Builds of the job in view, or the last build of every job
+	job := m.contextJob()
+	if job != "" && job == m.selectedJob {
+		for _, build := range m.jobDetail.History() {
+			completions["build"] = append(completions["build"], strconv.Itoa(build.Number))
+		}
+	}
+	if len(completions["build"]) == 0 {
+		for _, j := range m.jobs {
+			if j.LastBuild != nil {
+				completions["build"] = append(completions["build"], fmt.Sprintf("%s#%d", j.Name, j.LastBuild.Number))
+			}
+		}
+	}
+
+	servers, _ := m.service.Servers()
+	for _, server := range servers {
+		completions["server"] = append(completions["server"], server.Name)
+	}
+
+	completions["theme"] = utils.ThemeNames()
+
+	for _, name := range m.jobStates[job].Parameters {
+		completions["trigger"] = append(completions["trigger"], name+"=")
+	}
+
+	return completions
+}
+
+// runCommand parses and runs a line entered on the command line
+func (m Model) runCommand(line string) (Model, tea.Cmd) {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	if action, ok := commandActions[name]; ok {
+		return m.runAction(action)
+	}
+
+	switch name {
+	case "q", "quit":
+		return m, tea.Quit
+
+	case "job":
+		if len(args) != 1 {
+			m.errorMsg = "Usage: :job <name>"
+			return m, nil
+		}
+		if _, ok := m.jobStates[args[0]]; !ok && len(m.jobs) > 0 {
+			m.errorMsg = fmt.Sprintf("Unknown job %q", args[0])
+			return m, nil
+		}
+		return m.openJob(args[0])
+
+	case "build":
+		if len(args) != 1 {
+			m.errorMsg = "Usage: :build [job#]<number>"
+			return m, nil
+		}
+		job, number := m.contextJob(), args[0]
+		if i := strings.LastIndex(number, "#"); i >= 0 {
+			job, number = number[:i], number[i+1:]
+		}
+		buildNumber, err := strconv.Atoi(number)
+		if err != nil || buildNumber <= 0 {
+			m.errorMsg = fmt.Sprintf("Invalid build number %q", number)
+			return m, nil
+		}
+		if job == "" {
+			m.errorMsg = "No job selected: use :build <job>#<number>"
+			return m, nil
+		}
+		return m.openBuild(job, buildNumber)
+
+	case "server":
+		if len(args) != 1 {
+			m.errorMsg = "Usage: :server <name>"
+			return m, nil
+		}
+		return m.switchServer(args[0])
+
+	case "theme":
+		if len(args) == 0 {
+			return m.runAction(actionTheme)
+		}
+		return m.switchTheme(args[0]), nil
+
+	case "trigger":
+		return m.triggerCommand(args)
+
+	case "nodes":
+		if !m.conn.Online() {
+			m.errorMsg = "Not connected to Jenkins server"
+			return m, nil
+		}
+		return m, m.fetch("Fetching nodes", func() tea.Msg {
+			nodes, err := m.service.GetNodes()
+			return fetchNodesMsg{nodes: nodes, err: err}
+		})
+	}
+
+	m.errorMsg = fmt.Sprintf("Unknown command %q (commands: %s)", name, strings.Join(commandNames, ", "))
+	return m, nil
+}
+
+// triggerCommand builds the job in view with parameters given as NAME=value
+func (m Model) triggerCommand(args []string) (Model, tea.Cmd) {
+	job := m.contextJob()
+	if job == "" {
+		m.errorMsg = "No job selected"
+		return m, nil
+	}
+
+	// Parameterized jobs take their default values for parameters not given
+	var parameters map[string]string
+	if len(args) > 0 || len(m.jobStates[job].Parameters) > 0 {
+		parameters = make(map[string]string, len(args))
+	}
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			m.errorMsg = fmt.Sprintf("Invalid parameter %q: use NAME=value", arg)
+			return m, nil
+		}
+		parameters[name] = value
+	}
+
+	m.statusMessage = fmt.Sprintf("Triggering %s...", job)
+	return m, func() tea.Msg {
+		result := bulkResultMsg{action: bulkBuild}
+		if err := m.service.TriggerBuild(job, parameters); err != nil {
+			result.failed = append(result.failed, fmt.Sprintf("%s: %v", job, err))
+		} else {
+			result.done = append(result.done, job)
+		}
+		return result
+	}
+}
+
+// nodesSummary describes the nodes in one line: how many are online, and
+// the names of those that are not
+func nodesSummary(nodes []api.Node) string {
+	var offline []string
+	busy := 0
+	for _, node := range nodes {
+		switch {
+		case !node.Online:
+			offline = append(offline, node.Name)
+		case !node.Idle:
+			busy++
+		}
+	}
+	sort.Strings(offline)
+
+	summary := fmt.Sprintf("Nodes: %d online (%d busy) of %d", len(nodes)-len(offline), busy, len(nodes))
+	if len(offline) > 0 {
+		summary += "; offline: " + strings.Join(offline, ", ")
+	}
+	return summary
+}
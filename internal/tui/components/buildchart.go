@@ -0,0 +1,289 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// defaultTrendBuilds is how many builds the job's duration sparkline shows
+const defaultTrendBuilds = 30
+
+// sparkBlocks are the partial bar heights in eighths, lowest first
+var sparkBlocks = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// completedBuilds returns the builds that have finished, oldest first
+func completedBuilds(builds []BuildInfo) []BuildInfo {
+	var done []BuildInfo
+	for _, build := range builds {
+		if build.Status != "running" && build.Duration > 0 {
+			done = append(done, build)
+		}
+	}
+	sort.Slice(done, func(i, j int) bool {
+		return done[i].Number < done[j].Number
+	})
+	return done
+}
+
+// durationStats returns the average and 90th percentile of the durations
+func durationStats(builds []BuildInfo) (avg, p90 time.Duration) {
+	durations := make([]float64, len(builds))
+	for i, build := range builds {
+		durations[i] = float64(build.Duration)
+	}
+	return time.Duration(utils.Mean(durations)), time.Duration(utils.Percentile(durations, 90))
+}
+
+// durationSparkline draws the durations of the last n finished builds,
+// oldest first, each bar in the colour of the build's result
+func durationSparkline(builds []BuildInfo, n int) string {
+	if len(builds) > n {
+		builds = builds[len(builds)-n:]
+	}
+
+	var longest time.Duration
+	for _, build := range builds {
+		longest = max(longest, build.Duration)
+	}
+
+	var sb strings.Builder
+	for _, build := range builds {
+		level := int(float64(build.Duration)/float64(longest)*float64(len(sparkBlocks)-1) + 0.5)
+		sb.WriteString(utils.StatusStyle(build.Status).Render(sparkBlocks[level]))
+	}
+	return sb.String()
+}
+
+// BuildChartComponent charts the durations of a job's builds, one bar per
+// build coloured by its result, with the average and 90th percentile marked.
+// A bar can be selected to open that build.
+type BuildChartComponent struct {
+	jobName  string
+	builds   []BuildInfo // Finished builds, oldest first
+	selected int
+	width    int
+	height   int
+	keys     KeyMap
+}
+
+// NewBuildChart creates a new build chart component
+func NewBuildChart() BuildChartComponent {
+	return BuildChartComponent{
+		keys: DefaultKeyMap(),
+	}
+}
+
+// WithKeyMap sets the keybindings used by the chart
+func (c BuildChartComponent) WithKeyMap(keys KeyMap) BuildChartComponent {
+	c.keys = keys
+	return c
+}
+
+// WithBuilds sets the job and builds to chart; the selection stays on the
+// same build when the builds of the same job are refreshed, and starts at
+// the latest build otherwise
+func (c BuildChartComponent) WithBuilds(jobName string, builds []BuildInfo) BuildChartComponent {
+	selected := 0
+	if s := c.Selected(); s != nil && jobName == c.jobName {
+		selected = s.Number
+	}
+
+	c.jobName = jobName
+	c.builds = completedBuilds(builds)
+	c.selected = len(c.builds) - 1
+	for i, build := range c.builds {
+		if build.Number == selected {
+			c.selected = i
+		}
+	}
+	return c
+}
+
+// Selected returns the selected build, or nil if there are no builds
+func (c BuildChartComponent) Selected() *BuildInfo {
+	if c.selected < 0 || c.selected >= len(c.builds) {
+		return nil
+	}
+	build := c.builds[c.selected]
+	return &build
+}
+
+// Init initializes the build chart component
+func (c BuildChartComponent) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (c BuildChartComponent) Update(msg tea.Msg) (BuildChartComponent, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, c.keys.PrevBuild):
+			c.selected = max(c.selected-1, 0)
+		case key.Matches(msg, c.keys.NextBuild):
+			c.selected = min(c.selected+1, len(c.builds)-1)
+		}
+	}
+	return c, nil
+}
+
+// View renders the build chart component
+func (c BuildChartComponent) View() string {
+	var sb strings.Builder
+	sb.WriteString(c.header())
+
+	if len(c.builds) == 0 {
+		sb.WriteString(utils.MutedText.Render("No finished builds to chart"))
+		return sb.String()
+	}
+
+	sb.WriteString(c.chart())
+	sb.WriteString("\n\n")
+
+	// Details of the selected bar
+	if build := c.Selected(); build != nil {
+		status := utils.StatusStyle(build.Status).Render(build.Status)
+		sb.WriteString(fmt.Sprintf("Build #%d  %s  %s  started %s (%s)\n",
+			build.Number, status, utils.FormatDuration(build.Duration.Milliseconds()),
+			build.StartTime.Format("2006-01-02 15:04"), utils.FormatTimeAgo(build.StartTime)))
+	}
+	sb.WriteString(utils.MutedText.Render(fmt.Sprintf("%s/%s select a build • %s open its log",
+		c.keys.PrevBuild.Help().Key, c.keys.NextBuild.Help().Key, c.keys.Enter.Help().Key)))
+
+	return sb.String()
+}
+
+// header renders the title above the chart
+func (c BuildChartComponent) header() string {
+	return utils.TitleStyle.Render(fmt.Sprintf("Build Durations: %s", c.jobName)) + "\n\n"
+}
+
+// chart draws the bars of the builds that fit the width, keeping the
+// selected build in view, with the duration axis on the left and the
+// average and 90th percentile lines labelled on the right
+func (c BuildChartComponent) chart() string {
+	// Under the bars go the build axis, the marker and the numbers, then a
+	// blank line, the selected build and the hint
+	height := max(c.height-strings.Count(c.header(), "\n")-6, 5)
+
+	// Bars are two cells wide when there is room, with a gap between them
+	labelWidth := 10
+	plotWidth := max(c.width-2*labelWidth-4, 10)
+	barWidth := 1
+	if len(c.builds)*3 <= plotWidth {
+		barWidth = 2
+	}
+	slot := barWidth + 1
+	visible := min(len(c.builds), plotWidth/slot)
+
+	start := len(c.builds) - visible
+	if c.selected < start {
+		start = c.selected
+	}
+	builds := c.builds[start : start+visible]
+
+	avg, p90 := durationStats(builds)
+	var top time.Duration
+	for _, build := range builds {
+		top = max(top, build.Duration)
+	}
+
+	// Row of a duration on the chart, 0 at the bottom
+	rowOf := func(d time.Duration) int {
+		return min(int(float64(d)/float64(top)*float64(height)), height-1)
+	}
+	avgRow, p90Row := rowOf(avg), rowOf(p90)
+
+	lineStyle := utils.MutedText
+	markStyle := lipgloss.NewStyle().Foreground(utils.ColorWarning)
+
+	var rows []string
+	for row := height - 1; row >= 0; row-- {
+		var line strings.Builder
+
+		// Duration axis
+		switch row {
+		case height - 1:
+			line.WriteString(runewidth.FillLeft(shortDuration(top), labelWidth))
+		case height / 2:
+			line.WriteString(runewidth.FillLeft(shortDuration(top/2), labelWidth))
+		default:
+			line.WriteString(strings.Repeat(" ", labelWidth))
+		}
+		line.WriteString(" │")
+
+		// Empty cells on the average and percentile rows show their lines
+		blank := " "
+		switch row {
+		case p90Row:
+			blank = markStyle.Render("┄")
+		case avgRow:
+			blank = lineStyle.Render("─")
+		}
+
+		for _, build := range builds {
+			eighths := int(float64(build.Duration)/float64(top)*float64(height*8)+0.5) - row*8
+			cell := blank
+			switch {
+			case eighths >= 8:
+				cell = sparkBlocks[7]
+			case eighths > 0:
+				cell = sparkBlocks[eighths-1]
+			}
+			if cell != blank {
+				cell = utils.StatusStyle(build.Status).Render(strings.Repeat(cell, barWidth))
+			} else {
+				cell = strings.Repeat(cell, barWidth)
+			}
+			line.WriteString(cell + blank)
+		}
+
+		switch row {
+		case p90Row:
+			label := "p90 " + shortDuration(p90)
+			if p90Row == avgRow {
+				label += ", avg " + shortDuration(avg)
+			}
+			line.WriteString(" " + markStyle.Render(label))
+		case avgRow:
+			line.WriteString(" " + lineStyle.Render("avg "+shortDuration(avg)))
+		}
+		rows = append(rows, line.String())
+	}
+
+	// Build axis with a marker under the selected bar
+	rows = append(rows, strings.Repeat(" ", labelWidth)+" └"+strings.Repeat("─", visible*slot))
+	marker := strings.Repeat(" ", (c.selected-start)*slot) + utils.HeaderText.Render(strings.Repeat("▲", barWidth))
+	rows = append(rows, strings.Repeat(" ", labelWidth+2)+marker)
+
+	first := fmt.Sprintf("#%d", builds[0].Number)
+	last := fmt.Sprintf("#%d", builds[len(builds)-1].Number)
+	gap := max(visible*slot-len(first)-len(last), 1)
+	rows = append(rows, strings.Repeat(" ", labelWidth+2)+utils.MutedText.Render(first+strings.Repeat(" ", gap)+last))
+
+	return strings.Join(rows, "\n")
+}
+
+// shortDuration renders a duration for chart labels, e.g. "4m02s" or "1h05m"
+func shortDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
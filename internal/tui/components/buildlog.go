@@ -2,33 +2,102 @@ package components
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
 )
 
+// promptMode tells what the build log prompt is being used for
+type promptMode int
+
+const (
+	promptNone promptMode = iota
+	promptSearch
+	promptFilter
+	promptSave
+)
+
 // BuildLogComponent represents the build log view
 type BuildLogComponent struct {
-	jobName   string
-	buildNum  int
-	viewport  viewport.Model
-	width     int
-	height    int
-	ready     bool
-	keys      KeyMap
-	log       string
+	jobName  string
+	buildNum int
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+	keys     KeyMap
+
+	// buf holds the log line by line as its ranges arrive and offset is the
+	// first row on screen; only the rows on screen are ever rendered
+	buf    logBuffer
+	offset int
+
+	// A range of the log is on its way, and a key press that needs the
+	// whole log waits for it
+	fetching bool
+	pending  *tea.KeyMsg
+
+	// Pipeline stages, shown folded unless the raw log was requested
+	outline logOutline
+
+	// Search and filter state
+	search     logSearch
+	filter     logFilter
+	filtered   filteredLog
+	prompt     textinput.Model
+	promptMode promptMode
+
+	// Format used by the save prompt, and the existing file enter was
+	// pressed on once, which a second enter overwrites
+	exportFormat exportFormat
+	overwrite    string
+
+	// Known failure signatures found in the log, looked for once the build
+	// is known to have failed
+	analyzer   *utils.FailureAnalyzer
+	failed     logPosition
+	failures   failureSummary
+	jumpOnLoad bool
+
+	// Log line to scroll to once it has arrived, when coming back to a log
+	restore logPosition
+}
+
+// logPosition is a line of the log of a build
+type logPosition struct {
+	jobName  string
+	buildNum int
+	line     int
 }
 
 // NewBuildLog creates a new build log component
 func NewBuildLog() BuildLogComponent {
+	prompt := textinput.New()
+
 	return BuildLogComponent{
-		keys: DefaultKeyMap(),
+		keys:    DefaultKeyMap(),
+		buf:     newLogBuffer(),
+		outline: newOutline(),
+		prompt:  prompt,
+		filter:  logFilter{logPattern: logPattern{regex: true}},
 	}
 }
 
+// LogRangeRequestMsg asks for the range of a build log that starts at a
+// byte offset, sent as the log is scrolled towards the end of what arrived
+type LogRangeRequestMsg struct {
+	JobName     string
+	BuildNumber int
+	Offset      int64
+}
+
 // Init initializes the build log component
 func (b BuildLogComponent) Init() tea.Cmd {
 	return nil
@@ -36,13 +105,200 @@ func (b BuildLogComponent) Init() tea.Cmd {
 
 // WithLog adds log content to the build log component
 func (b BuildLogComponent) WithLog(log string) BuildLogComponent {
-	b.log = log
+	return b.WithLogRange(0, log, int64(len(log)), true)
+}
 
-	// If viewport is already initialized, update its content
-	if b.ready {
-		b.viewport.SetContent(b.formatLog())
+// WithLogRange adds a range of the log that starts at byte offset; offset 0
+// starts a new log. A range that does not continue the log shown belongs to
+// a log that was replaced in the meantime and is ignored.
+func (b BuildLogComponent) WithLogRange(offset int64, text string, next int64, complete bool) BuildLogComponent {
+	if offset == 0 {
+		b.resetLog()
+	} else if offset != b.buf.next || b.buf.complete {
+		return b
+	}
+	b.fetching = false
+
+	top := b.topLine()
+	lines, rows := len(b.buf.lines), b.rowCount()
+	if b.outlineActive() {
+		// The outline's rows are about to be rebuilt: take the matches back
+		// to the log lines they were found on
+		for i, m := range b.search.matches {
+			b.search.matches[i].line = b.outline.lineFor(m.line)
+		}
+	}
+	b.buf.append(text, next, complete)
+
+	// Carry everything derived from the log over to the new lines only
+	b.outline.feed(b.buf.lines, lines)
+	if b.analyzing() {
+		b.failures.hits = b.analyzer.AnalyzeFrom(b.failures.hits, b.buf.lines, lines)
+	}
+	if b.filter.enabled {
+		b.filter.extend(&b.filtered, b.buf.lines, lines)
+	}
+
+	b.outline.finish(complete)
+	if b.outlineActive() {
+		// The matches, found on log lines, move to the rebuilt rows
+		b.search.extend(b.buf.lines, lines)
+		b.outlineMatches(top)
+		b.scrollTo(b.rowFor(top))
+	} else {
+		b.search.extend(b.visibleLines(), rows)
+	}
+	b.resize()
+
+	if complete && b.jumpOnLoad {
+		b.jumpOnLoad = false
+		b.moveFailure(1)
+	}
+	b.restoreTopLine()
+
+	return b
+}
+
+// TopLine returns the log line at the top of the view
+func (b BuildLogComponent) TopLine() int {
+	return b.topLine()
+}
+
+// Shows reports whether the log of a build is shown
+func (b BuildLogComponent) Shows(jobName string, buildNum int) bool {
+	return b.jobName == jobName && b.buildNum == buildNum && !b.buf.empty()
+}
+
+// WithTopLine scrolls the log of a build to a line, straight away when the
+// line has arrived or else once it does
+func (b BuildLogComponent) WithTopLine(jobName string, buildNum, line int) BuildLogComponent {
+	b.restore = logPosition{jobName: jobName, buildNum: buildNum, line: line}
+	b.restoreTopLine()
+	return b
+}
+
+// restoreTopLine scrolls to the line asked for by WithTopLine once the log
+// holds a page from there
+func (b *BuildLogComponent) restoreTopLine() {
+	if b.restore.line == 0 || !b.Shows(b.restore.jobName, b.restore.buildNum) {
+		return
+	}
+	if b.restore.line+b.pageSize() > len(b.buf.lines) && !b.buf.complete {
+		return
+	}
+	b.scrollTo(b.rowFor(min(b.restore.line, len(b.buf.lines)-1)))
+	b.restore = logPosition{}
+}
+
+// resetLog clears the log before the first range of another one arrives,
+// keeping the user's search, filter and outline preferences
+func (b *BuildLogComponent) resetLog() {
+	b.buf = newLogBuffer()
+	b.offset = 0
+	b.fetching = false
+	b.pending = nil
+	if b.restore.jobName != b.jobName || b.restore.buildNum != b.buildNum {
+		b.restore = logPosition{}
+	}
+
+	// A new log invalidates any previous search and filter
+	b.search = logSearch{logPattern: logPattern{regex: b.search.regex, caseSensitive: b.search.caseSensitive}}
+	b.filter.enabled = false
+	b.filtered = filteredLog{}
+
+	enabled := b.outline.enabled
+	b.outline = newOutline()
+	b.outline.enabled = enabled
+
+	b.failures = failureSummary{current: -1, hidden: b.failures.hidden}
+}
+
+// LoadMore asks for the next range of the log once the view nears the end
+// of what has arrived, unless it ends in a folded stage, or straight away when a search, filter or pending
+// key press needs the whole log. When the log is complete it runs the key
+// press that was waiting for it.
+func (b BuildLogComponent) LoadMore() (BuildLogComponent, tea.Cmd) {
+	if b.buf.complete {
+		if b.pending == nil {
+			return b, nil
+		}
+		msg := *b.pending
+		b.pending = nil
+		return b.update(msg)
+	}
+
+	if b.fetching || b.buf.empty() {
+		return b, nil
+	}
+	needsAll := b.pending != nil || b.jumpOnLoad || b.restore.line > 0 || b.search.active() || b.filter.enabled
+	if !needsAll && (b.offset+2*b.pageSize() < b.rowCount() || b.outlineActive() && b.outline.endsFolded()) {
+		// More lines would not show yet
+		return b, nil
+	}
+
+	b.fetching = true
+	request := LogRangeRequestMsg{JobName: b.jobName, BuildNumber: b.buildNum, Offset: b.buf.next}
+	return b, func() tea.Msg {
+		return request
+	}
+}
+
+// waitForLog holds back a key press that needs the whole log until the rest
+// of it has arrived, and reports whether it did
+func (b *BuildLogComponent) waitForLog(msg tea.KeyMsg) bool {
+	if b.buf.complete {
+		return false
+	}
+	b.pending = &msg
+	return true
+}
+
+// WithFailureAnalyzer sets the analyzer used to summarise failures
+func (b BuildLogComponent) WithFailureAnalyzer(analyzer *utils.FailureAnalyzer) BuildLogComponent {
+	b.analyzer = analyzer
+	return b
+}
+
+// WithBuildStatus tells the status of a build. The log of a failed build
+// is searched for known failure signatures, which are summarised above it;
+// other logs are not.
+func (b BuildLogComponent) WithBuildStatus(jobName string, buildNum int, status string) BuildLogComponent {
+	was := b.analyzing()
+	if strings.EqualFold(status, string(api.StatusFailed)) {
+		b.failed = logPosition{jobName: jobName, buildNum: buildNum}
+	} else if b.failed.jobName == jobName && b.failed.buildNum == buildNum {
+		b.failed = logPosition{}
+	}
+	if b.analyzing() == was {
+		return b
+	}
+
+	// The build finished or failed after its log was shown
+	b.failures = failureSummary{current: -1, hidden: b.failures.hidden}
+	if b.analyzing() {
+		b.failures.hits = b.analyzer.AnalyzeFrom(nil, b.buf.lines, 0)
 	}
+	b.resize()
+	return b
+}
 
+// FailureHits returns the failure signatures found in the log of a build
+// when the whole of it is shown, so that it need not be analysed again
+func (b BuildLogComponent) FailureHits(jobName string, buildNum int) ([]utils.FailureHit, bool) {
+	if !b.Shows(jobName, buildNum) || !b.buf.complete || !b.analyzing() {
+		return nil, false
+	}
+	return b.failures.hits, true
+}
+
+// analyzing reports whether the log shown is searched for failures
+func (b BuildLogComponent) analyzing() bool {
+	return b.analyzer != nil && b.failed.jobName == b.jobName && b.failed.buildNum == b.buildNum
+}
+
+// WithJumpToFailure makes the next log jump to its first failure once loaded
+func (b BuildLogComponent) WithJumpToFailure() BuildLogComponent {
+	b.jumpOnLoad = true
 	return b
 }
 
@@ -53,13 +309,46 @@ func (b BuildLogComponent) WithJobAndBuild(jobName string, buildNum int) BuildLo
 	return b
 }
 
-// Update handles messages
+// WithKeyMap sets the keybindings used to scroll the log
+func (b BuildLogComponent) WithKeyMap(keys KeyMap) BuildLogComponent {
+	b.keys = keys
+	if b.ready {
+		applyViewportKeys(&b.viewport, keys)
+	}
+	return b
+}
+
+// WithFilterContext sets how many context lines the filter keeps around
+// each matching line
+func (b BuildLogComponent) WithFilterContext(before, after int) BuildLogComponent {
+	b.filter.before = max(0, before)
+	b.filter.after = max(0, after)
+	return b
+}
+
+// RefreshStyles re-applies the current theme to the viewport and log colours
+func (b BuildLogComponent) RefreshStyles() BuildLogComponent {
+	b.buf.restyle()
+	if b.ready {
+		b.viewport.Style = utils.LogStyle
+	}
+	return b
+}
+
+// Capturing reports whether the prompt is consuming key presses
+func (b BuildLogComponent) Capturing() bool {
+	return b.promptMode != promptNone
+}
+
+// Update handles messages and asks for more of the log when it is needed
 func (b BuildLogComponent) Update(msg tea.Msg) (BuildLogComponent, tea.Cmd) {
-	var (
-		cmd  tea.Cmd
-		cmds []tea.Cmd
-	)
+	b, cmd := b.update(msg)
+	b, more := b.LoadMore()
+	return b, tea.Batch(cmd, more)
+}
 
+// update handles messages
+func (b BuildLogComponent) update(msg tea.Msg) (BuildLogComponent, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		b.width = msg.Width
@@ -67,24 +356,541 @@ func (b BuildLogComponent) Update(msg tea.Msg) (BuildLogComponent, tea.Cmd) {
 
 		if !b.ready {
 			// Initialize viewport now that we know the terminal dimensions
-			b.viewport = viewport.New(msg.Width-4, msg.Height-10)
+			b.viewport = viewport.New(msg.Width-4, msg.Height)
 			b.viewport.Style = utils.LogStyle
-			b.viewport.SetContent(b.formatLog())
+			applyViewportKeys(&b.viewport, b.keys)
 			b.ready = true
+			b.resize()
 		} else {
 			// Resize the viewport
-			b.viewport.Width = msg.Width - 4
-			b.viewport.Height = msg.Height - 10
+			b.resize()
+		}
+
+	case tea.KeyMsg:
+		if b.promptMode != promptNone {
+			return b.updatePrompt(msg)
+		}
+
+		switch {
+		case key.Matches(msg, b.keys.Search):
+			return b, b.openPrompt(promptSearch, "/", "search log", b.search.query)
+
+		case key.Matches(msg, b.keys.NextMatch):
+			b.moveMatch(1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.PrevMatch):
+			b.moveMatch(-1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.ToggleRegex):
+			b.search.regex = !b.search.regex
+			b.runSearch()
+			return b, nil
+
+		case key.Matches(msg, b.keys.ToggleCase):
+			b.search.caseSensitive = !b.search.caseSensitive
+			b.runSearch()
+			return b, nil
+
+		case key.Matches(msg, b.keys.Filter):
+			return b, b.openPrompt(promptFilter, "&", "show lines matching", b.filter.query)
+
+		case key.Matches(msg, b.keys.ToggleSection):
+			b.toggleSection()
+			return b, nil
+
+		case key.Matches(msg, b.keys.NextSection):
+			b.moveSection(1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.PrevSection):
+			b.moveSection(-1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.ToggleOutline):
+			if len(b.outline.sections) > 0 {
+				top := b.topLine()
+				b.outline.enabled = !b.outline.enabled
+				b.search.run(b.visibleLines())
+				b.scrollTo(b.rowFor(top))
+			}
+			return b, nil
+
+		case key.Matches(msg, b.keys.NextFailure):
+			b.moveFailure(1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.PrevFailure):
+			b.moveFailure(-1)
+			return b, nil
+
+		case key.Matches(msg, b.keys.ToggleFailures):
+			b.failures.hidden = !b.failures.hidden
+			b.resize()
+			return b, nil
+
+		case key.Matches(msg, b.keys.SaveLog):
+			if b.buf.empty() || b.waitForLog(msg) {
+				return b, nil
+			}
+			b.overwrite = ""
+			return b, b.openPrompt(promptSave, "save as: ", "file name", b.defaultExportName(b.exportFormat))
+
+		case key.Matches(msg, b.keys.OpenPager):
+			if b.buf.empty() || b.waitForLog(msg) {
+				return b, nil
+			}
+			return b, b.openPager()
+
+		case key.Matches(msg, b.keys.OpenEditor):
+			if b.buf.empty() || b.waitForLog(msg) {
+				return b, nil
+			}
+			return b, b.openEditor()
+
+		case key.Matches(msg, b.keys.ToggleFilter):
+			if b.filter.query != "" {
+				b.setFilter(!b.filter.enabled)
+			}
+			return b, nil
+
+		case key.Matches(msg, b.keys.InvertFilter):
+			b.filter.invert = !b.filter.invert
+			b.setFilter(b.filter.enabled)
+			return b, nil
+
+		case key.Matches(msg, b.keys.MoreContext):
+			b.filter.before++
+			b.filter.after++
+			b.setFilter(b.filter.enabled)
+			return b, nil
+
+		case key.Matches(msg, b.keys.LessContext):
+			b.filter.before = max(0, b.filter.before-1)
+			b.filter.after = max(0, b.filter.after-1)
+			b.setFilter(b.filter.enabled)
+			return b, nil
+		}
+
+		if b.ready {
+			b.scroll(msg)
+		}
+
+	case tea.MouseMsg:
+		if !b.ready || msg.Action != tea.MouseActionPress {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			b.scrollTo(b.offset - b.viewport.MouseWheelDelta)
+		case tea.MouseButtonWheelDown:
+			b.scrollTo(b.offset + b.viewport.MouseWheelDelta)
+		case tea.MouseButtonLeft:
+			return b, openURL(b.View(), msg)
 		}
 	}
 
-	// Handle viewport messages
-	if b.ready {
-		b.viewport, cmd = b.viewport.Update(msg)
-		cmds = append(cmds, cmd)
+	return b, nil
+}
+
+// scroll moves the log for the scrolling keys, with the same bindings as a
+// viewport
+func (b *BuildLogComponent) scroll(msg tea.KeyMsg) {
+	keys := b.viewport.KeyMap
+	switch {
+	case key.Matches(msg, keys.PageDown):
+		b.scrollTo(b.offset + b.pageSize())
+	case key.Matches(msg, keys.PageUp):
+		b.scrollTo(b.offset - b.pageSize())
+	case key.Matches(msg, keys.HalfPageDown):
+		b.scrollTo(b.offset + b.pageSize()/2)
+	case key.Matches(msg, keys.HalfPageUp):
+		b.scrollTo(b.offset - b.pageSize()/2)
+	case key.Matches(msg, keys.Down):
+		b.scrollTo(b.offset + 1)
+	case key.Matches(msg, keys.Up):
+		b.scrollTo(b.offset - 1)
+	}
+}
+
+// scrollTo puts row at the top of the screen, stopping at either end
+func (b *BuildLogComponent) scrollTo(row int) {
+	b.offset = max(0, min(row, b.rowCount()-b.pageSize()))
+}
+
+// pageSize returns the number of rows on screen
+func (b BuildLogComponent) pageSize() int {
+	return max(1, b.viewport.Height-b.viewport.Style.GetVerticalFrameSize())
+}
+
+// rowCount returns the number of displayed rows
+func (b BuildLogComponent) rowCount() int {
+	switch {
+	case b.filter.enabled:
+		return len(b.filtered.rows)
+	case b.outlineActive():
+		return len(b.outline.rows)
+	}
+	return len(b.buf.lines)
+}
+
+// openPrompt shows the prompt for searching or filtering
+func (b *BuildLogComponent) openPrompt(mode promptMode, prompt, placeholder, value string) tea.Cmd {
+	b.promptMode = mode
+	b.prompt.Prompt = prompt
+	b.prompt.Placeholder = placeholder
+	b.prompt.SetValue(value)
+	b.prompt.CursorEnd()
+	return b.prompt.Focus()
+}
+
+// promptPattern returns the pattern the open prompt edits
+func (b *BuildLogComponent) promptPattern() *logPattern {
+	if b.promptMode == promptFilter {
+		return &b.filter.logPattern
+	}
+	return &b.search.logPattern
+}
+
+// updatePrompt handles key presses while the prompt is open
+func (b BuildLogComponent) updatePrompt(msg tea.KeyMsg) (BuildLogComponent, tea.Cmd) {
+	if b.promptMode == promptSave {
+		return b.updateSavePrompt(msg)
+	}
+
+	switch {
+	case msg.Type == tea.KeyEnter:
+		b.promptPattern().query = b.prompt.Value()
+		mode := b.promptMode
+		b.promptMode = promptNone
+		b.prompt.Blur()
+		if mode == promptFilter {
+			b.setFilter(b.filter.query != "")
+		} else {
+			b.runSearch()
+		}
+		return b, nil
+
+	case msg.Type == tea.KeyEsc:
+		b.promptMode = promptNone
+		b.prompt.Blur()
+		return b, nil
+
+	case key.Matches(msg, b.keys.ToggleRegex):
+		b.promptPattern().regex = !b.promptPattern().regex
+		return b, nil
+
+	case key.Matches(msg, b.keys.ToggleCase):
+		b.promptPattern().caseSensitive = !b.promptPattern().caseSensitive
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.prompt, cmd = b.prompt.Update(msg)
+	return b, cmd
+}
+
+// updateSavePrompt handles key presses while the save prompt is open; tab
+// cycles the export format and keeps the suggested extension in step
+func (b BuildLogComponent) updateSavePrompt(msg tea.KeyMsg) (BuildLogComponent, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		path := b.prompt.Value()
+		confirmed := b.confirmingOverwrite()
+		if !confirmed && exportExists(path) {
+			b.overwrite = path
+			return b, nil
+		}
+		b.promptMode = promptNone
+		b.prompt.Blur()
+		return b, b.saveLog(path, b.exportFormat, confirmed)
+
+	case tea.KeyEsc:
+		b.promptMode = promptNone
+		b.prompt.Blur()
+		return b, nil
+
+	case tea.KeyTab:
+		previous := b.exportFormat
+		b.exportFormat = exportFormats[(int(b.exportFormat)+1)%len(exportFormats)]
+		if value := b.prompt.Value(); strings.HasSuffix(value, previous.extension()) {
+			b.prompt.SetValue(strings.TrimSuffix(value, previous.extension()) + b.exportFormat.extension())
+			b.prompt.CursorEnd()
+		}
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.prompt, cmd = b.prompt.Update(msg)
+	return b, cmd
+}
+
+// setFilter applies or removes the filter while keeping the same part of
+// the log at the top of the viewport
+func (b *BuildLogComponent) setFilter(enabled bool) {
+	top := b.topLine()
+
+	b.filter.enabled = enabled
+	if enabled {
+		b.filtered = b.filter.apply(b.buf.lines)
+		if b.filter.err != nil {
+			b.filter.enabled = false
+		}
+	}
+
+	b.search.run(b.visibleLines())
+	b.scrollTo(b.rowFor(top))
+}
+
+// outlineActive reports whether rows come from the pipeline outline; the
+// filter takes precedence
+func (b BuildLogComponent) outlineActive() bool {
+	return !b.filter.enabled && b.outline.active()
+}
+
+// rowFor returns the displayed row of a log line, or the nearest row when
+// the line is hidden
+func (b BuildLogComponent) rowFor(line int) int {
+	switch {
+	case b.filter.enabled:
+		return b.filtered.rowFor(line)
+	case b.outlineActive():
+		return b.outline.rowFor(line)
+	}
+	return line
+}
+
+// reveal makes a log line visible, unfolding its sections or turning the
+// filter off if it hides the line, and returns its row
+func (b *BuildLogComponent) reveal(line int) int {
+	if b.filter.enabled && !containsInt(b.filtered.rows, line) {
+		b.setFilter(false)
+	}
+	if b.outlineActive() {
+		b.outline.reveal(line)
+		b.outline.build()
+		b.search.run(b.visibleLines())
+	}
+	return b.rowFor(line)
+}
+
+// toggleSection folds or unfolds the selected section, selecting the first
+// section on screen if there is none, and keeps its header in place
+func (b *BuildLogComponent) toggleSection() {
+	if !b.outlineActive() {
+		return
+	}
+
+	row := b.outline.headerRow(b.outline.selected)
+	if !b.onScreen(row) {
+		row = b.outline.nextHeader(b.offset-1, 1)
+		if row < 0 {
+			return
+		}
+		b.outline.selected = b.outline.rows[row].section
+	}
+
+	offset := row - b.offset
+	b.outline.selected.expanded = !b.outline.selected.expanded
+	b.outline.build()
+	b.search.run(b.visibleLines())
+	b.scrollTo(b.outline.headerRow(b.outline.selected) - offset)
+}
+
+// moveSection selects the next or previous visible section header and
+// scrolls it into view
+func (b *BuildLogComponent) moveSection(delta int) {
+	if !b.outlineActive() {
+		return
+	}
+
+	from := b.outline.headerRow(b.outline.selected)
+	if !b.onScreen(from) {
+		// Start from the screen when the selection has scrolled away
+		from = b.offset - 1
+		if delta < 0 {
+			from = b.offset + b.pageSize()
+		}
+	}
+
+	row := b.outline.nextHeader(from, delta)
+	if row < 0 {
+		return
+	}
+	b.outline.selected = b.outline.rows[row].section
+	if !b.onScreen(row) {
+		b.scrollTo(row - b.pageSize()/3)
+	}
+}
+
+// onScreen reports whether a displayed row is on screen
+func (b BuildLogComponent) onScreen(row int) bool {
+	return row >= b.offset && row < b.offset+b.pageSize()
+}
+
+// topLine returns the original line number shown at the top of the viewport
+func (b BuildLogComponent) topLine() int {
+	return b.lineAt(b.offset)
+}
+
+// lineAt returns the original line number shown on a displayed row
+func (b BuildLogComponent) lineAt(row int) int {
+	switch {
+	case b.filter.enabled:
+		return b.filtered.lineFor(row)
+	case b.outlineActive():
+		return b.outline.lineFor(row)
+	}
+	return row
+}
+
+// visibleLines returns the raw text of every displayed row; gaps and
+// section headers are empty
+func (b BuildLogComponent) visibleLines() []string {
+	switch {
+	case b.filter.enabled:
+		visible := make([]string, len(b.filtered.rows))
+		for row, line := range b.filtered.rows {
+			if line != gapRow {
+				visible[row] = b.buf.lines[line]
+			}
+		}
+		return visible
+
+	case b.outlineActive():
+		visible := make([]string, len(b.outline.rows))
+		for row, r := range b.outline.rows {
+			if r.section == nil {
+				visible[row] = b.buf.lines[r.line]
+			}
+		}
+		return visible
+	}
+	return b.buf.lines
+}
+
+// runSearch re-runs the search and jumps to the first match on or below
+// the top of the viewport
+func (b *BuildLogComponent) runSearch() {
+	b.findMatches()
+	b.jumpToMatch()
+}
+
+// findMatches re-runs the search over the displayed rows and makes the
+// first match on or below the top of the viewport the current one
+func (b *BuildLogComponent) findMatches() {
+	// Unfold every section with a match so that n/N can reach them all
+	if b.outlineActive() && b.search.query != "" {
+		if re, err := b.search.compile(); err == nil {
+			for i, line := range b.buf.lines {
+				if re.MatchString(line) {
+					b.outline.reveal(i)
+				}
+			}
+			b.outline.build()
+		}
+	}
+
+	b.search.run(b.visibleLines())
+	for i, m := range b.search.matches {
+		if m.line >= b.offset {
+			b.search.current = i
+			break
+		}
+	}
+}
+
+// outlineMatches moves the matches found in the flat log to the rows of the
+// outline, unfolding every section with a match so that n/N can reach them
+// all, and makes the first match on or below a line the current one
+func (b *BuildLogComponent) outlineMatches(top int) {
+	if !b.search.active() {
+		return
+	}
+
+	for _, m := range b.search.matches {
+		b.outline.reveal(m.line)
+	}
+	b.outline.build()
+
+	rowOf := make([]int, len(b.buf.lines))
+	for i := range rowOf {
+		rowOf[i] = -1
+	}
+	for row, r := range b.outline.rows {
+		if r.section == nil {
+			rowOf[r.line] = row
+		}
+	}
+	b.search.remap(rowOf)
+
+	from := b.rowFor(top)
+	for i, m := range b.search.matches {
+		if m.line >= from {
+			b.search.current = i
+			break
+		}
+	}
+}
+
+// moveMatch moves the current match and scrolls it into view; only the
+// rows on screen are rendered, so this costs the same on any size of log
+func (b *BuildLogComponent) moveMatch(delta int) {
+	if _, ok := b.search.move(delta); ok {
+		b.jumpToMatch()
+	}
+}
+
+// jumpToMatch scrolls the current match to the middle of the viewport
+func (b *BuildLogComponent) jumpToMatch() {
+	if len(b.search.matches) == 0 {
+		return
+	}
+	row := b.search.matches[b.search.current].line
+	b.scrollTo(row - b.pageSize()/2)
+}
+
+// moveFailure selects the next or previous failure hit, showing the panel
+// if it was hidden, and scrolls its first line to the middle of the viewport
+func (b *BuildLogComponent) moveFailure(delta int) {
+	hit, ok := b.failures.move(delta)
+	if !ok || !b.ready {
+		return
+	}
+
+	if b.failures.hidden {
+		b.failures.hidden = false
+		b.resize()
+	}
+
+	row := b.reveal(hit.Line)
+	b.scrollTo(row - b.pageSize()/2)
+}
+
+// resize fits the viewport below the failure summary panel
+func (b *BuildLogComponent) resize() {
+	if !b.ready {
+		return
 	}
 
-	return b, tea.Batch(cmds...)
+	// The footer takes a blank line and a line of help under the log
+	b.viewport.Width = b.width - 4
+	b.viewport.Height = max(3, b.height-strings.Count(b.header(), "\n")-2)
+	b.scrollTo(b.offset)
+}
+
+// header renders the title and the failure summary above the log
+func (b BuildLogComponent) header() string {
+	var sb strings.Builder
+	sb.WriteString(utils.TitleStyle.Render(fmt.Sprintf("Build Log: %s #%d", b.jobName, b.buildNum)))
+	sb.WriteString("\n\n")
+
+	if panel := b.failures.panel(b.width-4, b.keys); panel != "" {
+		sb.WriteString(panel)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
 }
 
 // View renders the build log component
@@ -94,46 +900,128 @@ func (b BuildLogComponent) View() string {
 	}
 
 	var sb strings.Builder
+	sb.WriteString(b.header())
 
-	// Add the title
-	title := utils.TitleStyle.Render(fmt.Sprintf("Build Log: %s #%d", b.jobName, b.buildNum))
-	sb.WriteString(title)
+	// Add viewport with the rows on screen
+	b.viewport.SetContent(b.window())
+	sb.WriteString(b.viewport.View())
 	sb.WriteString("\n\n")
 
-	// Add viewport with log content
-	sb.WriteString(b.viewport.View())
+	// The prompt replaces the footer while it is open
+	if b.promptMode == promptSave {
+		sb.WriteString(b.prompt.View())
+		sb.WriteString(" ")
+		if b.confirmingOverwrite() {
+			sb.WriteString(utils.WarningText.Render("file exists, enter to overwrite"))
+			sb.WriteString(utils.MutedText.Render(" | esc cancel"))
+		} else {
+			sb.WriteString(utils.MutedText.Render(fmt.Sprintf("[%s]  tab format | enter save | esc cancel", b.exportFormat)))
+		}
+		return sb.String()
+	}
+	if b.promptMode != promptNone {
+		sb.WriteString(b.prompt.View())
+		sb.WriteString(" ")
+		sb.WriteString(utils.MutedText.Render(fmt.Sprintf("%s  %s regex | %s case | enter apply | esc cancel",
+			b.promptPattern().flags(), b.keys.ToggleRegex.Help().Key, b.keys.ToggleCase.Help().Key)))
+		return sb.String()
+	}
 
 	// Add footer with controls
 	footerHelp := fmt.Sprintf(
-		"%s scroll up/down | %s page up/down | %s back",
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("↑/↓"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("PgUp/PgDown"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("ESC"),
+		"%s scroll up/down | %s page up/down | %s search | %s filter | %s save | %s pager | %s editor | %s back",
+		utils.MutedText.Render(b.keys.Up.Help().Key+" "+b.keys.Down.Help().Key),
+		utils.MutedText.Render(b.keys.PageUp.Help().Key+" "+b.keys.PageDown.Help().Key),
+		utils.MutedText.Render(b.keys.Search.Help().Key),
+		utils.MutedText.Render(b.keys.Filter.Help().Key),
+		utils.MutedText.Render(b.keys.SaveLog.Help().Key),
+		utils.MutedText.Render(b.keys.OpenPager.Help().Key),
+		utils.MutedText.Render(b.keys.OpenEditor.Help().Key),
+		utils.MutedText.Render(b.keys.Back.Help().Key),
 	)
+	if b.outlineActive() {
+		footerHelp = fmt.Sprintf("%s fold/unfold | %s stages | %s raw log | %s",
+			utils.MutedText.Render(b.keys.ToggleSection.Help().Key),
+			utils.MutedText.Render(b.keys.PrevSection.Help().Key+" "+b.keys.NextSection.Help().Key),
+			utils.MutedText.Render(b.keys.ToggleOutline.Help().Key),
+			footerHelp)
+	}
+	if status := b.search.status(); status != "" {
+		footerHelp = fmt.Sprintf("%s (%s %s/%s) | %s",
+			utils.WarningText.Render(status), b.search.flags(),
+			b.keys.NextMatch.Help().Key, b.keys.PrevMatch.Help().Key, footerHelp)
+	}
+	if !b.buf.empty() && !b.buf.complete {
+		status := fmt.Sprintf("%s loaded", formatSize(b.buf.size))
+		if b.pending != nil {
+			status += ", waiting for the rest"
+		}
+		footerHelp = fmt.Sprintf("%s | %s", utils.WarningText.Render(status), footerHelp)
+	}
+	if status := b.filter.status(len(b.filtered.matched)); status != "" {
+		footerHelp = fmt.Sprintf("%s (%s off, %s invert, %s/%s context) | %s",
+			utils.WarningText.Render(status), b.keys.ToggleFilter.Help().Key, b.keys.InvertFilter.Help().Key,
+			b.keys.MoreContext.Help().Key, b.keys.LessContext.Help().Key, footerHelp)
+	}
 
 	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
+		Foreground(utils.ColorLightGray).
 		Render(footerHelp)
 
-	sb.WriteString("\n\n")
 	sb.WriteString(footer)
 
 	return sb.String()
 }
 
-// formatLog formats the log content for display
-func (b BuildLogComponent) formatLog() string {
-	if b.log == "" {
+// window renders the rows on screen
+func (b BuildLogComponent) window() string {
+	if b.buf.empty() {
 		return "No log data available for this build."
 	}
 
-	// Apply any log filters here if needed
-	log := b.log
-
-	// Colorize certain log patterns
-	log = utils.ColorizeLogOutput(log)
+	end := min(b.rowCount(), b.offset+b.pageSize())
+	rows := make([]string, 0, max(0, end-b.offset))
+	for row := b.offset; row < end; row++ {
+		rows = append(rows, b.renderRow(row))
+	}
 
-	return log
+	return strings.Join(rows, "\n")
 }
 
+// renderRow renders one displayed row: the line-number gutter when filtered,
+// then the colorized line with any search matches highlighted
+func (b BuildLogComponent) renderRow(row int) string {
+	line := row
+	gutter := ""
+	switch {
+	case b.filter.enabled:
+		line = b.filtered.rows[row]
+		gutter = b.filtered.gutter(row, len(strconv.Itoa(len(b.buf.lines))))
+		if line == gapRow {
+			return gutter
+		}
+	case b.outlineActive():
+		r := b.outline.rows[row]
+		if r.section != nil {
+			return b.outline.header(r.section)
+		}
+		line = r.line
+	}
+
+	if _, ok := b.search.byLine[row]; ok {
+		return gutter + b.search.highlight(row, b.buf.lines[line])
+	}
+	if b.failures.isCurrent(line) {
+		return gutter + utils.CurrentMatchStyle.Render(b.buf.lines[line])
+	}
+	return gutter + b.buf.style(line)
+}
 
+// colorizeLines colors every log line, keeping the log's own ANSI colours
+func (b BuildLogComponent) colorizeLines() []string {
+	rendered := make([]string, len(b.buf.lines))
+	for i := range b.buf.lines {
+		rendered[i] = b.buf.colorize(i)
+	}
+	return rendered
+}
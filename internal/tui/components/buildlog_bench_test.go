@@ -0,0 +1,112 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// benchLogSizes are the log lengths, in lines, each benchmark runs with;
+// apart from loading, the cost should not grow with the size of the log
+var benchLogSizes = []int{10_000, 100_000, 1_000_000}
+
+// benchLog generates a pipeline log of n lines with a mix of stages,
+// parallel branches, warnings, errors and coloured output
+func benchLog(n int) string {
+	var sb strings.Builder
+	sb.WriteString("Started by user admin\n[Pipeline] Start of Pipeline\n[Pipeline] node\n[Pipeline] {\n")
+	for i := 4; i < n-2; i++ {
+		switch {
+		case i%5000 == 4:
+			fmt.Fprintf(&sb, "[Pipeline] { (Stage %d)\n", i/5000)
+		case i%5000 == 4999:
+			sb.WriteString("[Pipeline] }\n")
+		case i%997 == 0:
+			fmt.Fprintf(&sb, "ERROR: step %d failed with exit code 1\n", i)
+		case i%101 == 0:
+			fmt.Fprintf(&sb, "WARNING: deprecated option used at line %d\n", i)
+		case i%53 == 0:
+			fmt.Fprintf(&sb, "\x1b[32m[INFO]\x1b[0m Downloaded artifact-%d.jar (%d kB)\n", i, i%900)
+		default:
+			fmt.Fprintf(&sb, "[INFO] Compiling module %d of the integration test suite\n", i)
+		}
+	}
+	sb.WriteString("[Pipeline] }\nFinished: FAILURE")
+	return sb.String()
+}
+
+// benchBuildLog returns a sized build log component showing a log of n lines
+func benchBuildLog(b *testing.B, n int) BuildLogComponent {
+	b.Helper()
+	log, _ := NewBuildLog().Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+	// Show the log flat so that every line is a row
+	log.outline.enabled = false
+	return log.WithLog(benchLog(n))
+}
+
+func BenchmarkBuildLogLoad(b *testing.B) {
+	analyzer, err := utils.NewFailureAnalyzer(config.FailureAnalysis{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, n := range benchLogSizes {
+		log := benchLog(n)
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				NewBuildLog().WithFailureAnalyzer(analyzer).WithLog(log)
+			}
+		})
+	}
+}
+
+func BenchmarkBuildLogView(b *testing.B) {
+	for _, n := range benchLogSizes {
+		c := benchBuildLog(b, n)
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.View()
+			}
+		})
+	}
+}
+
+func BenchmarkBuildLogScroll(b *testing.B) {
+	pageDown := tea.KeyMsg{Type: tea.KeyPgDown}
+	for _, n := range benchLogSizes {
+		c := benchBuildLog(b, n)
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			c := c
+			for i := 0; i < b.N; i++ {
+				if c.offset+c.pageSize() >= c.rowCount() {
+					c.offset = 0
+				}
+				c, _ = c.Update(pageDown)
+				c.View()
+			}
+		})
+	}
+}
+
+func BenchmarkBuildLogNextMatch(b *testing.B) {
+	next := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}
+	for _, n := range benchLogSizes {
+		c := benchBuildLog(b, n)
+		c.search.query = "ERROR"
+		c.runSearch()
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			c := c
+			for i := 0; i < b.N; i++ {
+				c, _ = c.Update(next)
+				c.View()
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// CommandMsg reports a command line entered in command mode
+type CommandMsg struct {
+	Line string
+}
+
+// CommandLineComponent is the ":" command line shown in place of the status
+// bar. Tab completes the command name or its last argument from the
+// candidates given when it is opened, and up/down recall earlier commands.
+// While it is open it takes every key press.
+type CommandLineComponent struct {
+	input  textinput.Model
+	active bool
+	width  int
+
+	// Candidates by command name; the empty name lists the commands
+	completions map[string][]string
+
+	// Earlier command lines, oldest first, and the one recalled
+	history []string
+	recall  int
+	draft   string // Line being typed before recalling history
+
+	// Tab completion in progress
+	base       string // Line up to the word being completed
+	candidates []string
+	candidate  int
+}
+
+// NewCommandLine creates a closed command line
+func NewCommandLine() CommandLineComponent {
+	input := textinput.New()
+	input.Prompt = ":"
+
+	return CommandLineComponent{input: input}
+}
+
+// Open shows an empty command line completing from the given candidates
+func (c CommandLineComponent) Open(completions map[string][]string, history []string) CommandLineComponent {
+	c.completions = completions
+	c.history = history
+	c.recall = len(history)
+	c.draft = ""
+	c.candidates = nil
+	c.input.SetValue("")
+	c.input.Focus()
+	c.active = true
+	return c
+}
+
+// Active reports whether the command line is open
+func (c CommandLineComponent) Active() bool {
+	return c.active
+}
+
+// close hides the command line
+func (c CommandLineComponent) close() CommandLineComponent {
+	c.active = false
+	c.input.Blur()
+	c.candidates = nil
+	return c
+}
+
+// complete fills in the next (or previous) candidate for the word before
+// the cursor
+func (c CommandLineComponent) complete(step int) CommandLineComponent {
+	if c.candidates == nil {
+		line := c.input.Value()
+		var options []string
+		word := line
+		c.base = ""
+		if i := strings.IndexByte(line, ' '); i < 0 {
+			options = c.completions[""]
+		} else {
+			options = c.completions[line[:i]]
+			j := strings.LastIndexByte(line, ' ')
+			c.base, word = line[:j+1], line[j+1:]
+		}
+
+		c.candidates = matchCandidates(options, word)
+		if len(c.candidates) == 0 {
+			c.candidates = nil
+			return c
+		}
+		c.candidate = -1
+		if step < 0 {
+			c.candidate = 0
+		}
+	}
+
+	c.candidate = (c.candidate + step + len(c.candidates)) % len(c.candidates)
+	completed := c.base + c.candidates[c.candidate]
+	// A single candidate is final, so move on to the next word
+	if len(c.candidates) == 1 && !strings.HasSuffix(completed, "=") {
+		completed += " "
+	}
+	c.input.SetValue(completed)
+	c.input.CursorEnd()
+	return c
+}
+
+// matchCandidates returns the options starting with word, followed by those
+// containing it elsewhere, ignoring case
+func matchCandidates(options []string, word string) []string {
+	word = strings.ToLower(word)
+	var prefixed, contained []string
+	for _, option := range options {
+		lower := strings.ToLower(option)
+		switch {
+		case strings.HasPrefix(lower, word):
+			prefixed = append(prefixed, option)
+		case strings.Contains(lower, word):
+			contained = append(contained, option)
+		}
+	}
+	return append(prefixed, contained...)
+}
+
+// recallHistory replaces the line with an earlier (step -1) or later
+// (step 1) command from the history
+func (c CommandLineComponent) recallHistory(step int) CommandLineComponent {
+	next := c.recall + step
+	if next < 0 || next > len(c.history) {
+		return c
+	}
+
+	if c.recall == len(c.history) {
+		c.draft = c.input.Value()
+	}
+	c.recall = next
+	if next == len(c.history) {
+		c.input.SetValue(c.draft)
+	} else {
+		c.input.SetValue(c.history[next])
+	}
+	c.input.CursorEnd()
+	return c
+}
+
+// Update handles messages
+func (c CommandLineComponent) Update(msg tea.Msg) (CommandLineComponent, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		return c, nil
+
+	case tea.KeyMsg:
+		if !c.active {
+			return c, nil
+		}
+
+		switch msg.String() {
+		case "tab":
+			return c.complete(1), nil
+		case "shift+tab":
+			return c.complete(-1), nil
+		}
+		c.candidates = nil
+
+		switch msg.String() {
+		case "esc":
+			return c.close(), nil
+		case "enter":
+			line := strings.TrimSpace(c.input.Value())
+			c = c.close()
+			if line == "" {
+				return c, nil
+			}
+			return c, func() tea.Msg {
+				return CommandMsg{Line: line}
+			}
+		case "up":
+			return c.recallHistory(-1), nil
+		case "down":
+			return c.recallHistory(1), nil
+		case "backspace":
+			// Backspace on an empty line leaves command mode, as in vim
+			if c.input.Value() == "" {
+				return c.close(), nil
+			}
+		}
+
+		var cmd tea.Cmd
+		c.input, cmd = c.input.Update(msg)
+		return c, cmd
+	}
+
+	return c, nil
+}
+
+// View renders the command line, followed by the completion candidates while
+// cycling through them
+func (c CommandLineComponent) View() string {
+	line := c.input.View()
+	if len(c.candidates) < 2 {
+		return line
+	}
+
+	room := c.width - runewidth.StringWidth(c.input.Value()) - 6
+	var hints []string
+	for i, candidate := range c.candidates {
+		room -= runewidth.StringWidth(candidate) + 2
+		if room < 0 {
+			hints = append(hints, "…")
+			break
+		}
+		if i == c.candidate {
+			hints = append(hints, utils.MatchStyle.Render(candidate))
+		} else {
+			hints = append(hints, utils.MutedText.Render(candidate))
+		}
+	}
+	return line + "   " + strings.Join(hints, "  ")
+}
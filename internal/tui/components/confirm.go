@@ -0,0 +1,167 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// maxConfirmLines is how many detail lines the confirmation dialog lists
+const maxConfirmLines = 12
+
+// ConfirmMsg reports the answer to the confirmation dialog
+type ConfirmMsg struct {
+	Confirmed bool
+}
+
+// ConfirmComponent is a dialog asking to confirm an action before it runs.
+// While it is open it takes every key press: y or enter confirms, n or esc
+// cancels. A dialog that requires some text, such as the name of a job about
+// to be deleted, only confirms once it has been typed.
+type ConfirmComponent struct {
+	title  string
+	lines  []string
+	active bool
+	width  int
+	height int
+
+	// Destructive actions are shown in red
+	danger bool
+
+	// Text to type to confirm, and what has been typed
+	required string
+	input    textinput.Model
+	mismatch bool
+}
+
+// NewConfirm creates a closed confirmation dialog
+func NewConfirm() ConfirmComponent {
+	input := textinput.New()
+	input.Prompt = "> "
+	return ConfirmComponent{input: input}
+}
+
+// Open shows the dialog with a question and the details of what it affects
+func (c ConfirmComponent) Open(title string, lines []string) ConfirmComponent {
+	c.title = title
+	c.lines = lines
+	c.active = true
+	c.danger = false
+	c.required = ""
+	c.mismatch = false
+	c.input.Reset()
+	c.input.Blur()
+	return c
+}
+
+// WithDanger marks the action of the open dialog as destructive
+func (c ConfirmComponent) WithDanger() ConfirmComponent {
+	c.danger = true
+	return c
+}
+
+// WithRequiredText makes the open dialog confirm only once text is typed
+func (c ConfirmComponent) WithRequiredText(text string) ConfirmComponent {
+	c.required = text
+	c.input.Focus()
+	return c
+}
+
+// Active reports whether the dialog is open
+func (c ConfirmComponent) Active() bool {
+	return c.active
+}
+
+// answer closes the dialog and reports the answer
+func (c ConfirmComponent) answer(confirmed bool) (ConfirmComponent, tea.Cmd) {
+	c.active = false
+	return c, func() tea.Msg {
+		return ConfirmMsg{Confirmed: confirmed}
+	}
+}
+
+// Update handles messages
+func (c ConfirmComponent) Update(msg tea.Msg) (ConfirmComponent, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+
+	case tea.KeyMsg:
+		if !c.active {
+			break
+		}
+		if c.required != "" {
+			return c.updateInput(msg)
+		}
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return c.answer(true)
+		case "n", "N", "esc", "q":
+			return c.answer(false)
+		}
+	}
+	return c, nil
+}
+
+// updateInput answers once the required text is typed and enter pressed
+func (c ConfirmComponent) updateInput(msg tea.KeyMsg) (ConfirmComponent, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return c.answer(false)
+	case tea.KeyEnter:
+		if c.input.Value() == c.required {
+			return c.answer(true)
+		}
+		c.mismatch = true
+		return c, nil
+	}
+
+	var cmd tea.Cmd
+	c.mismatch = false
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+// View renders the dialog in the middle of the screen
+func (c ConfirmComponent) View() string {
+	lines := limitLines(c.lines, maxConfirmLines)
+
+	titleStyle, color := utils.WarningText, utils.ColorWarning
+	if c.danger {
+		titleStyle, color = utils.FailureText, utils.ColorSecondary
+	}
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(c.title))
+	sb.WriteString("\n")
+	if len(lines) > 0 {
+		sb.WriteString("\n" + strings.Join(lines, "\n") + "\n")
+	}
+	sb.WriteString("\n")
+
+	confirmKeys, cancelKeys := "y/enter", "n/esc"
+	if c.required != "" {
+		sb.WriteString(fmt.Sprintf("Type %s to confirm:\n%s\n", utils.BoldText.Render(c.required), c.input.View()))
+		if c.mismatch {
+			sb.WriteString(utils.FailureText.Render("That is not the name asked for") + "\n")
+		}
+		sb.WriteString("\n")
+		confirmKeys, cancelKeys = "enter", "esc"
+	}
+	sb.WriteString(utils.MutedText.Render(fmt.Sprintf("%s confirm • %s cancel",
+		utils.BoldText.Render(confirmKeys), utils.BoldText.Render(cancelKeys))))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(1, 2).
+		Render(sb.String())
+
+	return lipgloss.Place(max(c.width, lipgloss.Width(box)), max(c.height, lipgloss.Height(box)),
+		lipgloss.Center, lipgloss.Center, box)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
 )
 
@@ -22,6 +23,40 @@ type ServerInfo struct {
 	FreeNodes  int
 }
 
+// DashboardJob is a job with the state of its last build, as shown by the
+// dashboard widgets
+type DashboardJob struct {
+	Name       string
+	Status     string // Result of the last completed build
+	InProgress bool
+	Watched    bool
+
+	// Last build, if the job was ever built
+	LastBuild   int
+	BuildStatus string
+	Started     time.Time
+	Duration    time.Duration
+	Estimate    time.Duration // Zero when Jenkins has no estimate
+}
+
+// QueueEntry is a build waiting in the build queue
+type QueueEntry struct {
+	JobName string
+	Why     string
+	Since   time.Time
+	Stuck   bool
+}
+
+// DashboardTickMsg redraws the dashboard so that running builds progress
+type DashboardTickMsg time.Time
+
+// DashboardTick schedules the next redraw of the dashboard
+func DashboardTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return DashboardTickMsg(t)
+	})
+}
+
 // DashboardComponent represents the dashboard view
 type DashboardComponent struct {
 	width      int
@@ -29,6 +64,17 @@ type DashboardComponent struct {
 	keys       KeyMap
 	help       help.Model
 	serverInfo ServerInfo
+	widgets    []dashboardWidget
+
+	// State of the connection and when the server last answered
+	connState   string
+	lastContact time.Time
+
+	jobs          []DashboardJob
+	queue         []QueueEntry
+	busyExecutors int
+	allExecutors  int
+	updated       time.Time
 }
 
 // NewDashboard creates a new dashboard component
@@ -39,18 +85,89 @@ func NewDashboard() DashboardComponent {
 		serverInfo: ServerInfo{
 			Connected: false,
 		},
+		widgets: defaultWidgets(),
 	}
 }
 
+// WithWidgets sets the widgets shown and their order from the config,
+// rejecting unknown widgets and sizes
+func (d DashboardComponent) WithWidgets(cfg config.Dashboard) (DashboardComponent, error) {
+	if len(cfg.Widgets) == 0 {
+		d.widgets = defaultWidgets()
+		return d, nil
+	}
+
+	var widgets []dashboardWidget
+	for i, w := range cfg.Widgets {
+		widget, ok := newWidget(w.Type)
+		if !ok {
+			return d, fmt.Errorf("dashboard.widgets[%d]: unknown widget %q (valid widgets: %s)",
+				i, w.Type, strings.Join(widgetKinds, ", "))
+		}
+
+		switch w.Width {
+		case "":
+		case "half":
+			widget.full = false
+		case "full":
+			widget.full = true
+		default:
+			return d, fmt.Errorf("dashboard.widgets[%d]: width must be \"half\" or \"full\", not %q", i, w.Width)
+		}
+
+		if w.Height < 0 {
+			return d, fmt.Errorf("dashboard.widgets[%d]: height must not be negative", i)
+		}
+		if w.Height > 0 {
+			widget.height = w.Height
+		}
+
+		if !w.Hidden {
+			widgets = append(widgets, widget)
+		}
+	}
+
+	d.widgets = widgets
+	return d, nil
+}
+
 // WithServerInfo adds server information to the dashboard
 func (d DashboardComponent) WithServerInfo(info ServerInfo) DashboardComponent {
 	d.serverInfo = info
 	return d
 }
 
+// WithConnection sets the state of the connection shown by the server
+// widget: connecting, connected, degraded, offline or auth failed
+func (d DashboardComponent) WithConnection(state string, lastContact time.Time) DashboardComponent {
+	d.connState = state
+	d.lastContact = lastContact
+	return d
+}
+
+// WithJobs sets the jobs summarised by the widgets
+func (d DashboardComponent) WithJobs(jobs []DashboardJob) DashboardComponent {
+	d.jobs = jobs
+	d.updated = time.Now()
+	return d
+}
+
+// WithQueue sets the builds waiting in the build queue
+func (d DashboardComponent) WithQueue(queue []QueueEntry) DashboardComponent {
+	d.queue = queue
+	return d
+}
+
+// WithExecutors sets how many of the build executors are busy
+func (d DashboardComponent) WithExecutors(busy, total int) DashboardComponent {
+	d.busyExecutors = busy
+	d.allExecutors = total
+	return d
+}
+
 // Init initializes the dashboard component
 func (d DashboardComponent) Init() tea.Cmd {
-	return nil
+	return DashboardTick()
 }
 
 // Update handles messages and user input
@@ -71,45 +188,49 @@ func (d DashboardComponent) View() string {
 
 	title := utils.TitleStyle.Render("Jenkins TUI Dashboard")
 	sb.WriteString(title)
+	sb.WriteString("\n")
+
+	sb.WriteString(d.layout())
 	sb.WriteString("\n\n")
 
-	// Server information
-	serverTitle := "Server Information"
-	if d.serverInfo.Connected {
-		serverTitle += " " + utils.SuccessText.Render("● Connected")
-	} else {
-		serverTitle += " " + utils.FailureText.Render("● Disconnected")
+	if !d.updated.IsZero() {
+		sb.WriteString(utils.MutedText.Render(fmt.Sprintf("Last updated: %s", d.updated.Format("2006-01-02 15:04:05"))))
 	}
 
-	var serverContent string
-	if d.serverInfo.Connected {
-		serverContent = fmt.Sprintf(
-			"URL: %s\nVersion: %s\nMode: %s\nUptime: %s\nNodes: %d total, %d free",
-			d.serverInfo.URL,
-			d.serverInfo.Version,
-			d.serverInfo.Mode,
-			d.serverInfo.Uptime,
-			d.serverInfo.TotalNodes,
-			d.serverInfo.FreeNodes,
-		)
-	} else {
-		serverContent = "Not connected to Jenkins server"
-	}
+	return sb.String()
+}
 
-	serverInfo := utils.ServerInfoStyle.Width(d.width / 2).Render(
-		lipgloss.JoinVertical(
-			lipgloss.Left,
-			serverTitle,
-			serverContent,
-		),
-	)
+// layout arranges the widgets in rows: on wide terminals two half-width
+// widgets share a row, on narrow ones every widget takes a row of its own
+func (d DashboardComponent) layout() string {
+	width := max(d.width, 40)
+	columns := 1
+	if width >= dashboardTwoColumnWidth {
+		columns = 2
+	}
 
-	sb.WriteString(serverInfo)
-	sb.WriteString("\n\n")
+	var rows []string
+	var pending []string
+	flush := func() {
+		if len(pending) > 0 {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, pending...))
+			pending = nil
+		}
+	}
 
-	// Current time
-	currentTime := fmt.Sprintf("Last updated: %s", time.Now().Format("2006-01-02 15:04:05"))
-	sb.WriteString(currentTime)
+	for _, widget := range d.widgets {
+		if widget.full || columns == 1 {
+			flush()
+			rows = append(rows, d.renderWidget(widget, width))
+			continue
+		}
+
+		pending = append(pending, d.renderWidget(widget, width/columns))
+		if len(pending) == columns {
+			flush()
+		}
+	}
+	flush()
 
-	return sb.String()
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
@@ -0,0 +1,365 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// dashboardTwoColumnWidth is the terminal width from which half-width
+// widgets are laid out side by side
+const dashboardTwoColumnWidth = 100
+
+// widgetKinds lists every dashboard widget in the default order
+var widgetKinds = []string{"server", "watched", "status", "executors", "running", "queue", "failing", "recent"}
+
+// defaultWidgetHeights are the rows of content of the widgets listing jobs
+// or builds when the config gives no height
+var defaultWidgetHeights = map[string]int{
+	"watched": 6,
+	"running": 6,
+	"queue":   6,
+	"failing": 6,
+	"recent":  8,
+}
+
+// dashboardWidget is a widget laid out on the dashboard
+type dashboardWidget struct {
+	kind   string
+	full   bool // Takes a whole row even on wide terminals
+	height int  // Maximum rows of content
+}
+
+// newWidget returns a widget of a kind with its default size
+func newWidget(kind string) (dashboardWidget, bool) {
+	if !containsString(widgetKinds, kind) {
+		return dashboardWidget{}, false
+	}
+	return dashboardWidget{kind: kind, full: kind == "recent", height: defaultWidgetHeights[kind]}, true
+}
+
+// defaultWidgets returns every widget in the default order
+func defaultWidgets() []dashboardWidget {
+	widgets := make([]dashboardWidget, len(widgetKinds))
+	for i, kind := range widgetKinds {
+		widgets[i], _ = newWidget(kind)
+	}
+	return widgets
+}
+
+// renderWidget draws a widget in a box width columns wide
+func (d DashboardComponent) renderWidget(w dashboardWidget, width int) string {
+	inner := max(width-4, 10) // Border and padding
+
+	var title string
+	var lines []string
+	switch w.kind {
+	case "server":
+		title, lines = d.serverWidget()
+	case "watched":
+		title, lines = d.watchedWidget(inner)
+	case "status":
+		title, lines = d.statusWidget(inner)
+	case "executors":
+		title, lines = d.executorsWidget(inner)
+	case "running":
+		title, lines = d.runningWidget(inner)
+	case "queue":
+		title, lines = d.queueWidget(inner)
+	case "failing":
+		title, lines = d.failingWidget(inner)
+	case "recent":
+		title, lines = d.recentWidget(inner)
+	}
+	if w.height > 0 {
+		lines = limitLines(lines, w.height)
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(utils.ColorLightGray).
+		Padding(0, 1).
+		Width(width - 2)
+	return box.Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{utils.HeaderText.Render(title)}, lines...)...))
+}
+
+// serverWidget shows the connection and the server's version and nodes; the
+// details of a server that stopped answering are kept, with the time of its
+// last answer
+func (d DashboardComponent) serverWidget() (string, []string) {
+	title := "Server"
+	state := "● Disconnected"
+	if d.connState != "" {
+		state = "● " + strings.ToUpper(d.connState[:1]) + d.connState[1:]
+	}
+	if !d.serverInfo.Connected {
+		if d.connState == "connecting" {
+			return title + " " + utils.MutedText.Render(state), []string{"Connecting to Jenkins server..."}
+		}
+		return title + " " + utils.FailureText.Render(state), []string{"Not connected to Jenkins server"}
+	}
+
+	lines := []string{
+		fmt.Sprintf("URL: %s", d.serverInfo.URL),
+		fmt.Sprintf("Version: %s", d.serverInfo.Version),
+		fmt.Sprintf("Mode: %s", d.serverInfo.Mode),
+		fmt.Sprintf("Nodes: %d total, %d free", d.serverInfo.TotalNodes, d.serverInfo.FreeNodes),
+	}
+	if d.serverInfo.Uptime != "" && d.serverInfo.Uptime != "0s" {
+		lines = append(lines, fmt.Sprintf("Uptime: %s", d.serverInfo.Uptime))
+	}
+
+	switch d.connState {
+	case "", "connected":
+		return title + " " + utils.SuccessText.Render("● Connected"), lines
+	case "degraded":
+		state = utils.WarningText.Render(state)
+	default:
+		state = utils.FailureText.Render(state)
+	}
+	if !d.lastContact.IsZero() {
+		lines = append(lines, utils.WarningText.Render(fmt.Sprintf("Last answer: %s", d.lastContact.Format("15:04:05"))))
+	}
+	return title + " " + state, lines
+}
+
+// watchedWidget lists the watched jobs with the state of their last build
+func (d DashboardComponent) watchedWidget(width int) (string, []string) {
+	var watched []DashboardJob
+	nameWidth := 0
+	for _, job := range d.jobs {
+		if job.Watched {
+			watched = append(watched, job)
+			nameWidth = max(nameWidth, runewidth.StringWidth(job.Name))
+		}
+	}
+	if len(watched) == 0 {
+		return "Watched Jobs", []string{utils.MutedText.Render("Press w on a job to watch it")}
+	}
+
+	nameWidth = min(nameWidth, width/2)
+	var lines []string
+	for _, job := range watched {
+		status := utils.StatusStyle(job.Status).Render(job.Status)
+		if job.InProgress {
+			status += " " + utils.StatusStyle("running").Render("(running)")
+		}
+		lines = append(lines, fmt.Sprintf("★ %s  %s%s", padName(job.Name, nameWidth), buildLabel(job.LastBuild), status))
+	}
+	return "Watched Jobs", lines
+}
+
+// statusWidget counts the jobs by the result of their last build
+func (d DashboardComponent) statusWidget(width int) (string, []string) {
+	if len(d.jobs) == 0 {
+		return "Jobs", []string{utils.MutedText.Render("No jobs")}
+	}
+
+	statuses := []string{"success", "failure", "unstable", "aborted", "disabled", "unknown"}
+	counts := map[string]int{}
+	running := 0
+	for _, job := range d.jobs {
+		status := job.Status
+		if !containsString(statuses, status) {
+			status = "unknown"
+		}
+		counts[status]++
+		if job.InProgress {
+			running++
+		}
+	}
+
+	// One bar split in the colours of the statuses, then the counts
+	var bar strings.Builder
+	counted, filled := 0, 0
+	for _, status := range statuses {
+		counted += counts[status]
+		end := (counted*width + len(d.jobs)/2) / len(d.jobs)
+		bar.WriteString(utils.StatusStyle(status).Render(strings.Repeat("█", end-filled)))
+		filled = end
+	}
+
+	lines := []string{bar.String()}
+	for _, status := range statuses {
+		if counts[status] > 0 {
+			lines = append(lines, fmt.Sprintf("%s %-9s %4d", utils.StatusStyle(status).Render("●"), status, counts[status]))
+		}
+	}
+	if running > 0 {
+		lines = append(lines, fmt.Sprintf("%s %-9s %4d", utils.StatusStyle("running").Render("▶"), "running", running))
+	}
+	return fmt.Sprintf("Jobs (%d)", len(d.jobs)), lines
+}
+
+// executorsWidget shows how busy the build executors are
+func (d DashboardComponent) executorsWidget(width int) (string, []string) {
+	if d.allExecutors == 0 {
+		return "Executors", []string{utils.MutedText.Render("No executor data")}
+	}
+
+	usage := float64(d.busyExecutors) / float64(d.allExecutors)
+	color := utils.ColorSuccess
+	switch {
+	case usage >= 0.9:
+		color = utils.ColorSecondary
+	case usage >= 0.7:
+		color = utils.ColorWarning
+	}
+
+	return "Executors", []string{
+		progressBar(width, usage, color),
+		fmt.Sprintf("%d of %d busy (%.0f%%)", d.busyExecutors, d.allExecutors, usage*100),
+		fmt.Sprintf("Queue: %d waiting", len(d.queue)),
+	}
+}
+
+// runningWidget shows the running builds with their progress towards
+// Jenkins' estimate of their duration
+func (d DashboardComponent) runningWidget(width int) (string, []string) {
+	var running []DashboardJob
+	for _, job := range d.jobs {
+		if job.BuildStatus == "running" {
+			running = append(running, job)
+		}
+	}
+	if len(running) == 0 {
+		return "Running", []string{utils.MutedText.Render("No builds running")}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Started.Before(running[j].Started)
+	})
+
+	nameWidth := min(width/3, 30)
+	barWidth := max(width-nameWidth-30, 5)
+	var lines []string
+	for _, job := range running {
+		elapsed := time.Since(job.Started)
+		label := padName(job.Name, nameWidth) + "  " + buildLabel(job.LastBuild)
+		elapsedText := utils.FormatDuration(elapsed.Milliseconds())
+		if job.Estimate <= 0 {
+			lines = append(lines, fmt.Sprintf("%s%s elapsed", label, elapsedText))
+			continue
+		}
+
+		// Overdue builds stay just short of full
+		progress := min(float64(elapsed)/float64(job.Estimate), 0.99)
+		lines = append(lines, fmt.Sprintf("%s%s %3.0f%%  %s", label,
+			progressBar(barWidth, progress, utils.ColorRunning), progress*100, elapsedText))
+	}
+	return fmt.Sprintf("Running (%d)", len(running)), lines
+}
+
+// queueWidget lists the builds waiting in the build queue
+func (d DashboardComponent) queueWidget(width int) (string, []string) {
+	if len(d.queue) == 0 {
+		return "Queue", []string{utils.MutedText.Render("Queue is empty")}
+	}
+
+	var lines []string
+	for _, item := range d.queue {
+		line := fmt.Sprintf("%s  %s", item.JobName, utils.FormatTimeAgo(item.Since))
+		if item.Stuck {
+			line += " " + utils.WarningText.Render("stuck")
+		}
+		if item.Why != "" {
+			line += utils.MutedText.Render(" — " + runewidth.Truncate(item.Why, max(width-lipgloss.Width(line)-3, 5), "…"))
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("Queue (%d)", len(d.queue)), lines
+}
+
+// failingWidget lists the jobs whose last build failed, most recent first
+func (d DashboardComponent) failingWidget(width int) (string, []string) {
+	var failing []DashboardJob
+	for _, job := range d.jobs {
+		if job.Status == "failure" || job.Status == "unstable" {
+			failing = append(failing, job)
+		}
+	}
+	if len(failing) == 0 {
+		return "Failing", []string{utils.SuccessText.Render("No failing jobs")}
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		return failing[i].Started.After(failing[j].Started)
+	})
+
+	nameWidth := max(width-24, 10)
+	var lines []string
+	for _, job := range failing {
+		ago := ""
+		if !job.Started.IsZero() {
+			ago = utils.FormatTimeAgo(job.Started)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s  %s%s",
+			utils.StatusStyle(job.Status).Render("✗"), padName(job.Name, min(nameWidth, 40)), buildLabel(job.LastBuild), ago))
+	}
+	return fmt.Sprintf("Failing (%d)", len(failing)), lines
+}
+
+// recentWidget lists the last build of every job, most recent first
+func (d DashboardComponent) recentWidget(width int) (string, []string) {
+	var recent []DashboardJob
+	for _, job := range d.jobs {
+		if job.LastBuild > 0 && !job.Started.IsZero() {
+			recent = append(recent, job)
+		}
+	}
+	if len(recent) == 0 {
+		return "Recent Builds", []string{utils.MutedText.Render("No builds yet")}
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].Started.After(recent[j].Started)
+	})
+
+	nameWidth := min(max(width-48, 10), 50)
+	var lines []string
+	for _, job := range recent {
+		duration := utils.FormatDuration(job.Duration.Milliseconds())
+		if job.BuildStatus == "running" {
+			duration = "running"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s%s  %-16s %s",
+			padName(job.Name, nameWidth),
+			buildLabel(job.LastBuild),
+			utils.StatusStyle(job.BuildStatus).Render(fmt.Sprintf("%-8s", job.BuildStatus)),
+			utils.FormatTimeAgo(job.Started),
+			duration,
+		))
+	}
+	return "Recent Builds", lines
+}
+
+// progressBar draws a bar width cells wide, filled up to fraction
+func progressBar(width int, fraction float64, color lipgloss.Color) string {
+	filled := min(int(fraction*float64(width)+0.5), width)
+	return lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		utils.MutedText.Render(strings.Repeat("░", width-filled))
+}
+
+// padName truncates or pads a name to width cells
+func padName(name string, width int) string {
+	return runewidth.FillRight(runewidth.Truncate(name, width, "…"), width)
+}
+
+// buildLabel renders a build number in a column of its own, or blanks
+func buildLabel(number int) string {
+	if number <= 0 {
+		return strings.Repeat(" ", 8)
+	}
+	return fmt.Sprintf("%-8s", fmt.Sprintf("#%d", number))
+}
+
+// limitLines keeps at most n lines, saying how many were left out
+func limitLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+	hidden := len(lines) - n + 1
+	return append(lines[:n-1:n-1], utils.MutedText.Render(fmt.Sprintf("… and %d more", hidden)))
+}
@@ -0,0 +1,175 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// maxErrors is how many errors the error log keeps
+const maxErrors = 100
+
+// ErrorEntry is an error reported to the user; the same error reported
+// again in a row is counted rather than repeated
+type ErrorEntry struct {
+	Time    time.Time
+	Summary string
+	Details string
+	Count   int
+}
+
+// ErrorLogComponent lists the recent errors, newest first
+type ErrorLogComponent struct {
+	entries []ErrorEntry // Oldest first
+	unseen  int
+
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+	keys     KeyMap
+}
+
+// NewErrorLog creates an empty error log
+func NewErrorLog() ErrorLogComponent {
+	return ErrorLogComponent{keys: DefaultKeyMap()}
+}
+
+// WithKeyMap sets the keybindings used to scroll the error log
+func (e ErrorLogComponent) WithKeyMap(keys KeyMap) ErrorLogComponent {
+	e.keys = keys
+	if e.ready {
+		applyViewportKeys(&e.viewport, keys)
+	}
+	return e
+}
+
+// Add records an error
+func (e ErrorLogComponent) Add(summary, details string, at time.Time) ErrorLogComponent {
+	e.unseen++
+
+	if n := len(e.entries); n > 0 && e.entries[n-1].Summary == summary && e.entries[n-1].Details == details {
+		entries := append([]ErrorEntry(nil), e.entries...)
+		entries[n-1].Time = at
+		entries[n-1].Count++
+		e.entries = entries
+	} else {
+		e.entries = append(e.entries[:n:n], ErrorEntry{Time: at, Summary: summary, Details: details, Count: 1})
+		if len(e.entries) > maxErrors {
+			e.entries = e.entries[len(e.entries)-maxErrors:]
+		}
+	}
+
+	e.refreshContent()
+	return e
+}
+
+// Unseen returns the number of errors reported since the log was last seen
+func (e ErrorLogComponent) Unseen() int {
+	return e.unseen
+}
+
+// Seen marks every error as seen and scrolls back to the newest
+func (e ErrorLogComponent) Seen() ErrorLogComponent {
+	e.unseen = 0
+	e.viewport.GotoTop()
+	return e
+}
+
+// RefreshStyles re-applies the current theme
+func (e ErrorLogComponent) RefreshStyles() ErrorLogComponent {
+	e.viewport.Style = utils.LogStyle
+	e.refreshContent()
+	return e
+}
+
+// Init initializes the error log component
+func (e ErrorLogComponent) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (e ErrorLogComponent) Update(msg tea.Msg) (ErrorLogComponent, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.width = msg.Width
+		e.height = msg.Height
+
+		// The title is above the list, a blank line and the footer below
+		height := max(msg.Height-strings.Count(e.header(), "\n")-2, 3)
+		if !e.ready {
+			e.viewport = viewport.New(msg.Width-4, height)
+			e.viewport.Style = utils.LogStyle
+			applyViewportKeys(&e.viewport, e.keys)
+			e.ready = true
+		} else {
+			e.viewport.Width = msg.Width - 4
+			e.viewport.Height = height
+		}
+		e.refreshContent()
+	}
+
+	e.viewport, cmd = e.viewport.Update(msg)
+	return e, cmd
+}
+
+// refreshContent renders the errors into the viewport, newest first, with
+// their details wrapped under them
+func (e *ErrorLogComponent) refreshContent() {
+	if !e.ready {
+		return
+	}
+	if len(e.entries) == 0 {
+		e.viewport.SetContent(utils.MutedText.Render("No errors so far"))
+		return
+	}
+
+	width := max(e.viewport.Width-e.viewport.Style.GetHorizontalFrameSize(), 20)
+	timeWidth := len("15:04:05  ")
+	block := lipgloss.NewStyle().Width(width - timeWidth)
+
+	var sb strings.Builder
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		summary := entry.Summary
+		if entry.Count > 1 {
+			summary += fmt.Sprintf(" (×%d)", entry.Count)
+		}
+		text := utils.FailureText.Render(summary)
+		if entry.Details != "" {
+			text += "\n" + utils.MutedText.Render(entry.Details)
+		}
+
+		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			utils.MutedText.Render(entry.Time.Format("15:04:05"))+"  ", block.Render(text)))
+		sb.WriteString("\n\n")
+	}
+	e.viewport.SetContent(strings.TrimRight(sb.String(), "\n"))
+}
+
+// header renders the title above the errors
+func (e ErrorLogComponent) header() string {
+	return utils.TitleStyle.Render(fmt.Sprintf("Error Log (%d)", len(e.entries))) + "\n\n"
+}
+
+// View renders the error log component
+func (e ErrorLogComponent) View() string {
+	if !e.ready {
+		return "Loading..."
+	}
+
+	footer := lipgloss.NewStyle().
+		Foreground(utils.ColorLightGray).
+		Render(fmt.Sprintf("%s scroll | %s back",
+			utils.MutedText.Render(e.keys.Up.Help().Key+" "+e.keys.Down.Help().Key),
+			utils.MutedText.Render(e.keys.Back.Help().Key)))
+
+	return e.header() + e.viewport.View() + "\n\n" + footer
+}
@@ -11,10 +11,23 @@ import (
 
 // HelpComponent represents the help view
 type HelpComponent struct {
-	width  int
-	height int
-	keys   KeyMap
-	help   help.Model
+	width   int
+	height  int
+	keys    KeyMap
+	keyMaps KeyMaps
+	help    help.Model
+}
+
+// keyViewTitles are the headings of the per-view shortcut sections
+var keyViewTitles = map[string]string{
+	DashboardKeys:  "Dashboard",
+	JobListKeys:    "Job List",
+	JobDetailKeys:  "Job Detail",
+	BuildLogKeys:   "Build Log",
+	LogDiffKeys:    "Log Diff",
+	BuildChartKeys: "Build Chart",
+	HelpKeys:       "Help",
+	ErrorLogKeys:   "Error Log",
 }
 
 // NewHelp creates a new help component
@@ -28,6 +41,13 @@ func NewHelp() HelpComponent {
 	}
 }
 
+// WithKeyMaps sets the effective keybindings the help screen describes
+func (h HelpComponent) WithKeyMaps(keyMaps KeyMaps) HelpComponent {
+	h.keyMaps = keyMaps
+	h.keys = keyMaps.For(HelpKeys)
+	return h
+}
+
 // Init initializes the help component
 func (h HelpComponent) Init() tea.Cmd {
 	return nil
@@ -44,6 +64,17 @@ func (h HelpComponent) Update(msg tea.Msg) (HelpComponent, tea.Cmd) {
 	return h, nil
 }
 
+// viewOnlyKeys returns the bindings of a view with the global ones disabled
+func (h HelpComponent) viewOnlyKeys(view string) KeyMap {
+	keys := h.keyMaps.For(view)
+	for _, spec := range bindingSpecs {
+		if spec.views == nil {
+			spec.field(&keys).SetEnabled(false)
+		}
+	}
+	return keys
+}
+
 // View renders the help component
 func (h HelpComponent) View() string {
 	var sb strings.Builder
@@ -53,19 +84,26 @@ func (h HelpComponent) View() string {
 	sb.WriteString(title)
 	sb.WriteString("\n\n")
 
-	// Keyboard shortcuts section
+	// Keyboard shortcuts section, generated from the effective bindings
 	shortcutsContent := "Keyboard Shortcuts:\n\n" + h.help.View(h.keys)
+	for _, view := range []string{JobListKeys, JobDetailKeys, BuildLogKeys, LogDiffKeys, BuildChartKeys} {
+		shortcutsContent += fmt.Sprintf("\n\n%s:\n%s", keyViewTitles[view], h.help.View(h.viewOnlyKeys(view)))
+	}
 	shortcuts := utils.HelpSectionStyle.Width(h.width - 4).Render(shortcutsContent)
 	sb.WriteString(shortcuts)
 	sb.WriteString("\n")
 
+	keys := h.keyMaps.For(JobListKeys)
+	jobKeys := h.keyMaps.For(JobDetailKeys)
+	logKeys := h.keyMaps.For(BuildLogKeys)
+
 	// Usage section
 	usageContent := fmt.Sprintf(`
 Navigation:
-• Use arrow keys to navigate in lists and logs
-• Press Enter to select an item or action
-• Press Esc to go back to the previous view
-• Press j to go to the job list
+• Use %s/%s to navigate in lists and logs
+• Press %s to select an item or action
+• Press %s to go back to the previous view, and %s to go forward again
+• Press %s to go to the job list
 
 
 Views:
@@ -73,16 +111,28 @@ Views:
 • Job List: List of all Jenkins jobs
 • Job Detail: Information about a specific job
 • Build Log: Console output for a specific build
+• Log Diff: Differences between the console output of two builds
+• Build Chart: Durations of a job's recent builds (press %s in a job)
 
 Filtering:
 • Press / to filter jobs in the job list
 • Type your search term and press Enter
 • Press Esc to cancel filtering
+• In a build log, press %s to search and %s/%s to jump between matches
+• In a job, press %s on two builds and %s to compare their logs
 
 Tips:
-• Press r to refresh data
+• Press %s to refresh data
+• Press %s to cycle through colour themes
 • Logs will automatically colorize common patterns
-`)
+`,
+		keys.Up.Help().Key, keys.Down.Help().Key, keys.Enter.Help().Key,
+		keys.Back.Help().Key, keys.Forward.Help().Key, keys.Jobs.Help().Key,
+		jobKeys.Chart.Help().Key,
+		logKeys.Search.Help().Key, logKeys.NextMatch.Help().Key, logKeys.PrevMatch.Help().Key,
+		jobKeys.MarkBuild.Help().Key, jobKeys.CompareBuilds.Help().Key,
+		keys.Refresh.Help().Key, keys.Theme.Help().Key,
+	)
 
 	usage := utils.HelpSectionStyle.Width(h.width - 4).Render("Usage Guide:" + usageContent)
 	sb.WriteString(usage)
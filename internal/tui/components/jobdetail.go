@@ -2,12 +2,17 @@ package components
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/stats"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
 )
 
@@ -17,6 +22,7 @@ type BuildInfo struct {
 	Status    string
 	StartTime time.Time
 	Duration  time.Duration
+	Marked    bool // Selected for comparison
 }
 
 // Build represents detailed information about a build
@@ -34,11 +40,35 @@ type JobDetailComponent struct {
 	jobName     string
 	jobURL      string
 	description string
+	watched     bool
 	buildList   list.Model
+	builds      []BuildInfo
+	trendBuilds int // Builds shown in the duration sparkline
 	lastBuild   *Build
 	width       int
 	height      int
 	keys        KeyMap
+
+	// Build numbers marked for comparison, oldest mark first
+	marked []int
+
+	// Failure signatures found in the log of the last build
+	failureJob   string
+	failureBuild int
+	failures     []utils.FailureHit
+
+	// Build history the reliability statistics are computed from, whether
+	// it reaches back to the first build, and the window a longer history
+	// was asked for
+	history        []api.Build
+	historyWhole   bool
+	historyRequest historyRequest
+	statsWindow    stats.Window
+
+	// Build to select once the builds of the job arrive, 0 for none
+	selectOnLoad int
+
+	clicks clickTracker
 }
 
 // FilterValue implements list.Item
@@ -48,6 +78,9 @@ func (b BuildInfo) FilterValue() string {
 
 // Title implements list.Item
 func (b BuildInfo) Title() string {
+	if b.Marked {
+		return fmt.Sprintf("Build #%d ◆", b.Number)
+	}
 	return fmt.Sprintf("Build #%d", b.Number)
 }
 
@@ -59,14 +92,14 @@ func (b BuildInfo) Description() string {
 	return fmt.Sprintf("%s | %s | Duration: %s",
 		status,
 		utils.FormatTimeAgo(b.StartTime),
-		utils.FormatDuration(int64(b.Duration)),
+		utils.FormatDuration(b.Duration.Milliseconds()),
 	)
 }
 
 // NewJobDetail creates a new job detail component
 func NewJobDetail() JobDetailComponent {
 	// Set up the build list
-	delegate := list.NewDefaultDelegate()
+	delegate := newItemDelegate()
 	buildList := list.New([]list.Item{}, delegate, 0, 0)
 	buildList.Title = "Builds"
 	buildList.SetShowStatusBar(true)
@@ -74,38 +107,281 @@ func NewJobDetail() JobDetailComponent {
 	buildList.Styles.Title = utils.TitleStyle
 	buildList.SetShowHelp(true)
 
+	keys := DefaultKeyMap()
+	applyListKeys(&buildList, keys)
+
+	window, _ := stats.FindWindow(stats.DefaultWindow)
+
 	return JobDetailComponent{
-		buildList: buildList,
-		keys:      DefaultKeyMap(),
+		buildList:   buildList,
+		trendBuilds: defaultTrendBuilds,
+		statsWindow: window,
+		keys:        keys,
 	}
 }
 
 // WithJobDetail adds job details to the component
 func (j JobDetailComponent) WithJobDetail(name, description, url string) JobDetailComponent {
+	if name != j.jobName {
+		j.marked = nil
+	}
 	j.jobName = name
 	j.description = description
 	j.jobURL = url
 	j.buildList.Title = fmt.Sprintf("Builds for %s", name)
+	fitListTitle(&j.buildList)
+	return j
+}
+
+// WithWatched sets whether the job is on the watchlist
+func (j JobDetailComponent) WithWatched(watched bool) JobDetailComponent {
+	j.watched = watched
+	return j
+}
+
+// WithTrendBuilds sets how many builds the duration sparkline shows
+func (j JobDetailComponent) WithTrendBuilds(n int) JobDetailComponent {
+	if n > 0 {
+		j.trendBuilds = n
+	}
+	return j
+}
+
+// WithStatsWindow sets the window the reliability statistics cover, by name
+func (j JobDetailComponent) WithStatsWindow(name string) (JobDetailComponent, error) {
+	if name == "" {
+		return j, nil
+	}
+
+	window, ok := stats.FindWindow(name)
+	if !ok {
+		names := make([]string, len(stats.Windows))
+		for i, w := range stats.Windows {
+			names[i] = w.Name
+		}
+		return j, fmt.Errorf("stats.window: unknown window %q (valid windows: %s)", name, strings.Join(names, ", "))
+	}
+	j.statsWindow = window
+	return j, nil
+}
+
+// historyRequest is a window of a job a longer build history was asked for
+type historyRequest struct {
+	jobName string
+	window  stats.Window
+}
+
+// covers reports whether the history asked for reaches back over a window
+func (r historyRequest) covers(jobName string, window stats.Window) bool {
+	if r.jobName != jobName {
+		return false
+	}
+	return r.window.Period == 0 || (window.Period != 0 && window.Period <= r.window.Period)
+}
+
+// BuildHistoryRequestMsg asks for the builds of a job started since a time,
+// or all of them for the zero time, when the statistics of a window need
+// more than the builds listed with the job
+type BuildHistoryRequestMsg struct {
+	JobName string
+	Since   time.Time
+}
+
+// WithHistory sets the build history the reliability statistics are
+// computed from, most recent build first; whole tells that it reaches back
+// to the job's first build. A shorter history of the job, such as the
+// builds listed with it on refresh, keeps the older builds of a longer one
+// fetched before.
+func (j JobDetailComponent) WithHistory(builds []api.Build, whole bool) JobDetailComponent {
+	if n := len(builds); n > 0 && !whole && j.historyRequest.jobName == j.jobName {
+		for i, build := range j.history {
+			if build.Number == builds[n-1].Number {
+				builds = append(builds[:n:n], j.history[i+1:]...)
+				whole = j.historyWhole
+				break
+			}
+		}
+	}
+
+	j.history = builds
+	j.historyWhole = whole
 	return j
 }
 
+// RequestHistory asks for a longer build history when the one at hand
+// stops short of the statistics window, once per job and window
+func (j JobDetailComponent) RequestHistory() (JobDetailComponent, tea.Cmd) {
+	if len(j.history) == 0 || j.historyRequest.covers(j.jobName, j.statsWindow) {
+		return j, nil
+	}
+	if report := stats.Compute(j.history, j.historyWhole, j.statsWindow, time.Now()); !report.Truncated {
+		return j, nil
+	}
+
+	j.historyRequest = historyRequest{jobName: j.jobName, window: j.statsWindow}
+	request := BuildHistoryRequestMsg{JobName: j.jobName}
+	if j.statsWindow.Period > 0 {
+		request.Since = time.Now().Add(-j.statsWindow.Period)
+	}
+	return j, func() tea.Msg {
+		return request
+	}
+}
+
+// History returns the build history of the job
+func (j JobDetailComponent) History() []api.Build {
+	return j.history
+}
+
 // WithBuilds adds builds to the job detail component
 func (j JobDetailComponent) WithBuilds(builds []BuildInfo) JobDetailComponent {
+	j.builds = builds
+
 	// Convert builds to list items
 	items := make([]list.Item, len(builds))
 	for i, build := range builds {
+		build.Marked = containsInt(j.marked, build.Number)
 		items[i] = build
 	}
 	j.buildList.SetItems(items)
+
+	if j.selectOnLoad != 0 {
+		j.selectBuild(j.selectOnLoad)
+		j.selectOnLoad = 0
+	}
 	return j
 }
 
+// SelectBuild moves the cursor to a build, or to the build once the builds
+// of the job arrive when it is not listed yet
+func (j JobDetailComponent) SelectBuild(number int) JobDetailComponent {
+	j.selectOnLoad = 0
+	if !j.selectBuild(number) {
+		j.selectOnLoad = number
+	}
+	return j
+}
+
+// selectBuild moves the cursor to a build and reports whether it is listed
+func (j *JobDetailComponent) selectBuild(number int) bool {
+	for i, item := range j.buildList.VisibleItems() {
+		if item.(BuildInfo).Number == number {
+			j.buildList.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// MarkedBuilds returns the builds marked for comparison in ascending order
+func (j JobDetailComponent) MarkedBuilds() []int {
+	marked := append([]int(nil), j.marked...)
+	sort.Ints(marked)
+	return marked
+}
+
+// toggleMark marks or unmarks the selected build; marking a third build
+// replaces the oldest mark
+func (j JobDetailComponent) toggleMark() JobDetailComponent {
+	selected := j.GetSelectedBuild()
+	if selected == nil {
+		return j
+	}
+
+	if containsInt(j.marked, selected.Number) {
+		var marked []int
+		for _, n := range j.marked {
+			if n != selected.Number {
+				marked = append(marked, n)
+			}
+		}
+		j.marked = marked
+	} else {
+		j.marked = append(j.marked, selected.Number)
+		if len(j.marked) > 2 {
+			j.marked = j.marked[1:]
+		}
+	}
+
+	for i, item := range j.buildList.Items() {
+		build := item.(BuildInfo)
+		build.Marked = containsInt(j.marked, build.Number)
+		j.buildList.SetItem(i, build)
+	}
+	return j
+}
+
+// containsInt reports whether n is in values
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
 // WithLastBuildInfo adds the last build information
 func (j JobDetailComponent) WithLastBuildInfo(build Build) JobDetailComponent {
 	j.lastBuild = &build
 	return j
 }
 
+// WithFailureSummary sets the failure signatures found in a build's log;
+// they are shown while that build is the last build and has failed
+func (j JobDetailComponent) WithFailureSummary(buildNumber int, hits []utils.FailureHit) JobDetailComponent {
+	j.failureJob = j.jobName
+	j.failureBuild = buildNumber
+	j.failures = hits
+	return j
+}
+
+// HasFailureSummary reports whether the log of a build of the job has been
+// analysed already
+func (j JobDetailComponent) HasFailureSummary(buildNumber int) bool {
+	return j.failureJob == j.jobName && j.failureBuild == buildNumber
+}
+
+// FailedBuild returns the last build if it failed and its log has a
+// failure summary, or 0
+func (j JobDetailComponent) FailedBuild() int {
+	if j.lastBuild == nil || !j.HasFailureSummary(j.lastBuild.Number) || len(j.failures) == 0 {
+		return 0
+	}
+	if !strings.EqualFold(j.lastBuild.Status, string(api.StatusFailed)) {
+		return 0
+	}
+	return j.failureBuild
+}
+
+// WithKeyMap sets the keybindings used by the build list
+func (j JobDetailComponent) WithKeyMap(keys KeyMap) JobDetailComponent {
+	j.keys = keys
+	applyListKeys(&j.buildList, keys)
+	return j
+}
+
+// RefreshStyles re-applies the current theme
+func (j JobDetailComponent) RefreshStyles() JobDetailComponent {
+	styleList(&j.buildList)
+	return j
+}
+
+// Capturing reports whether the filter input is consuming key presses
+func (j JobDetailComponent) Capturing() bool {
+	return j.buildList.FilterState() == list.Filtering
+}
+
+// Build returns a build of the job by number, nil if it is not listed
+func (j JobDetailComponent) Build(number int) *BuildInfo {
+	for _, build := range j.builds {
+		if build.Number == number {
+			return &build
+		}
+	}
+	return nil
+}
+
 // GetSelectedBuild returns the currently selected build
 func (j JobDetailComponent) GetSelectedBuild() *BuildInfo {
 	if j.buildList.SelectedItem() == nil {
@@ -125,12 +401,28 @@ func (j JobDetailComponent) Init() tea.Cmd {
 func (j JobDetailComponent) Update(msg tea.Msg) (JobDetailComponent, tea.Cmd) {
 	var cmd tea.Cmd
 
+	// The details above the list grow and shrink with the job
+	j = j.fitList()
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		j.width = msg.Width
 		j.height = msg.Height
 		j.buildList.SetWidth(msg.Width)
-		j.buildList.SetHeight(msg.Height - 15) // Leave space for job info
+		fitListTitle(&j.buildList)
+		j = j.fitList()
+
+	case tea.KeyMsg:
+		if j.buildList.FilterState() != list.Filtering && key.Matches(msg, j.keys.MarkBuild) {
+			return j.toggleMark(), nil
+		}
+		if j.buildList.FilterState() != list.Filtering && key.Matches(msg, j.keys.StatsWindow) {
+			j.statsWindow = j.statsWindow.Next()
+			return j.RequestHistory()
+		}
+
+	case tea.MouseMsg:
+		return j.updateMouse(msg)
 	}
 
 	// Handle build list updates
@@ -139,16 +431,71 @@ func (j JobDetailComponent) Update(msg tea.Msg) (JobDetailComponent, tea.Cmd) {
 	return j, cmd
 }
 
+// updateMouse scrolls the build list with the wheel, opens links clicked in
+// the job details, selects the build clicked and opens its log on a double
+// click
+func (j JobDetailComponent) updateMouse(msg tea.MouseMsg) (JobDetailComponent, tea.Cmd) {
+	if j.width == 0 || j.buildList.FilterState() == list.Filtering {
+		return j, nil
+	}
+
+	if delta := wheelDelta(msg); delta != 0 {
+		if delta < 0 {
+			j.buildList.CursorUp()
+		} else {
+			j.buildList.CursorDown()
+		}
+		return j, nil
+	}
+	if !isClick(msg) {
+		return j, nil
+	}
+
+	header := j.header()
+	top := strings.Count(header, "\n")
+	if msg.Y < top {
+		return j, openURL(header, msg)
+	}
+
+	var double bool
+	j.clicks, double = j.clicks.click(msg)
+	i := listItemAt(j.buildList, msg.Y-top)
+	if i < 0 {
+		return j, nil
+	}
+	j.buildList.Select(i)
+
+	if double {
+		return j, openSelected
+	}
+	return j, nil
+}
+
 // View renders the job detail component
 func (j JobDetailComponent) View() string {
 	if j.width == 0 {
 		return "Loading..."
 	}
+	j = j.fitList()
+	return j.header() + j.buildList.View()
+}
+
+// fitList gives the build list the rows left under the header
+func (j JobDetailComponent) fitList() JobDetailComponent {
+	j.buildList.SetHeight(max(j.height-strings.Count(j.header(), "\n"), 5))
+	return j
+}
 
+// header renders everything above the build list
+func (j JobDetailComponent) header() string {
 	var sb strings.Builder
 
 	// Render job title and details
-	title := utils.TitleStyle.Render(fmt.Sprintf("Job: %s", j.jobName))
+	titleText := fmt.Sprintf("Job: %s", j.jobName)
+	if j.watched {
+		titleText += " ★"
+	}
+	title := utils.TitleStyle.Render(titleText)
 	sb.WriteString(title)
 	sb.WriteString("\n\n")
 
@@ -161,6 +508,17 @@ func (j JobDetailComponent) View() string {
 		jobDetails.WriteString(fmt.Sprintf("Description: %s\n", j.description))
 	}
 
+	// Durations of the recent builds
+	if trend := completedBuilds(j.builds); len(trend) > 1 {
+		trend = trend[max(len(trend)-j.trendBuilds, 0):]
+		avg, p90 := durationStats(trend)
+		jobDetails.WriteString(fmt.Sprintf("Trend (%d builds): %s  avg %s, p90 %s  %s\n",
+			len(trend), durationSparkline(trend, j.trendBuilds), shortDuration(avg), shortDuration(p90),
+			utils.MutedText.Render(fmt.Sprintf("(%s for chart)", j.keys.Chart.Help().Key))))
+	}
+
+	jobDetails.WriteString(j.statsView())
+
 	// Last build info if available
 	if j.lastBuild != nil {
 		statusColor := utils.GetStatusColor(j.lastBuild.Status)
@@ -172,7 +530,18 @@ func (j JobDetailComponent) View() string {
 			j.lastBuild.StartTime.Format("2006-01-02 15:04:05"),
 			utils.FormatTimeAgo(j.lastBuild.StartTime),
 		))
-		jobDetails.WriteString(fmt.Sprintf("Duration: %s\n", utils.FormatDuration(int64(j.lastBuild.Duration))))
+		jobDetails.WriteString(fmt.Sprintf("Duration: %s\n", utils.FormatDuration(j.lastBuild.Duration.Milliseconds())))
+
+		// Likely root causes of a failed build
+		if j.FailedBuild() != 0 {
+			jobDetails.WriteString("\n" + utils.FailureText.Render("Failure summary: "+utils.SummarizeFailures(j.failures)) + "\n")
+			for _, hit := range j.failures[:min(3, len(j.failures))] {
+				text := runewidth.Truncate(hit.Text, max(10, j.width-20), "…")
+				jobDetails.WriteString(fmt.Sprintf("- line %d: %s\n", hit.Line+1, text))
+			}
+			jobDetails.WriteString(utils.MutedText.Render(
+				fmt.Sprintf("Press %s to open the log at the first failure", j.keys.NextFailure.Help().Key)) + "\n")
+		}
 
 		// Show parameters if any
 		if len(j.lastBuild.Parameters) > 0 {
@@ -186,8 +555,69 @@ func (j JobDetailComponent) View() string {
 	sb.WriteString(jobDetailsStyle.Render(jobDetails.String()))
 	sb.WriteString("\n\n")
 
-	// Build list
-	sb.WriteString(j.buildList.View())
+	// Builds marked for comparison
+	if marked := j.MarkedBuilds(); len(marked) > 0 {
+		numbers := make([]string, len(marked))
+		for i, n := range marked {
+			numbers[i] = fmt.Sprintf("#%d", n)
+		}
+		hint := fmt.Sprintf("mark one more build with %s to compare", j.keys.MarkBuild.Help().Key)
+		if len(marked) == 2 {
+			hint = fmt.Sprintf("press %s to compare their logs", j.keys.CompareBuilds.Help().Key)
+		}
+		sb.WriteString(utils.MutedText.Render(fmt.Sprintf("Marked: %s (%s)", strings.Join(numbers, ", "), hint)))
+		sb.WriteString("\n")
+	}
 
 	return sb.String()
 }
+
+// statsView renders the reliability statistics of the selected window
+func (j JobDetailComponent) statsView() string {
+	if len(j.history) == 0 {
+		return ""
+	}
+
+	hint := utils.MutedText.Render(fmt.Sprintf("(%s for another window)", j.keys.StatsWindow.Help().Key))
+	report := stats.Compute(j.history, j.historyWhole, j.statsWindow, time.Now())
+	if report.Builds == 0 {
+		return fmt.Sprintf("Reliability (%s): no finished builds  %s\n", report.Window.Name, hint)
+	}
+
+	// Older builds than those fetched are left out of the window
+	builds := fmt.Sprintf("%d builds", report.Builds)
+	if report.Truncated {
+		builds += utils.WarningText.Render(fmt.Sprintf(", truncated to the last %d", len(j.history)))
+	}
+
+	mttr := "never failed"
+	switch {
+	case report.Recoveries > 0:
+		mttr = fmt.Sprintf("MTTR %s", utils.FormatDuration(report.MTTR.Milliseconds()))
+	case report.Failures > 0:
+		mttr = "not recovered"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Reliability (%s, %s): %s success • %s • longest failing streak %d  %s\n",
+		report.Window.Name, builds, rateStyle(report.SuccessRate).Render(fmt.Sprintf("%.0f%%", report.SuccessRate*100)),
+		mttr, report.LongestStreak, hint))
+	sb.WriteString(fmt.Sprintf("Durations: avg %s, p95 %s • flakiness %.0f%% (flaky builds: %d)",
+		shortDuration(report.AvgDuration), shortDuration(report.P95Duration), report.Flakiness*100, report.FlakyBuilds))
+	if report.Unstable > 0 {
+		sb.WriteString(fmt.Sprintf(" • %d unstable", report.Unstable))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// rateStyle colours a success rate: green when high, red when low
+func rateStyle(rate float64) lipgloss.Style {
+	switch {
+	case rate >= 0.9:
+		return utils.SuccessText
+	case rate >= 0.7:
+		return utils.WarningText
+	}
+	return utils.FailureText
+}
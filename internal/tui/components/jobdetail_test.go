@@ -0,0 +1,79 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/stats"
+)
+
+// history returns builds numbered from newest down to oldest, one a day,
+// most recent first
+func history(newest, oldest int) []api.Build {
+	var builds []api.Build
+	for n := newest; n >= oldest; n-- {
+		builds = append(builds, api.Build{
+			Number:    n,
+			Status:    string(api.StatusSuccess),
+			Result:    "SUCCESS",
+			StartTime: time.Now().Add(-time.Duration(newest-n) * 24 * time.Hour).UnixMilli(),
+		})
+	}
+	return builds
+}
+
+func numbers(builds []api.Build) []int {
+	var out []int
+	for _, b := range builds {
+		out = append(out, b.Number)
+	}
+	return out
+}
+
+func TestJobDetailHistory(t *testing.T) {
+	j := NewJobDetail().WithJobDetail("app", "", "")
+	j.statsWindow, _ = stats.FindWindow("30d")
+
+	// Ten days of builds listed with the job do not cover 30 days
+	j = j.WithHistory(history(20, 11), false)
+	j, cmd := j.RequestHistory()
+	if cmd == nil {
+		t.Fatal("no longer history asked for")
+	}
+	if msg := cmd().(BuildHistoryRequestMsg); msg.JobName != "app" || msg.Since.IsZero() {
+		t.Errorf("asked for %+v", msg)
+	}
+	if _, cmd := j.RequestHistory(); cmd != nil {
+		t.Error("history asked for twice")
+	}
+
+	// The longer history arrives, then a refresh lists the last builds
+	j = j.WithHistory(history(20, 1), true)
+	j = j.WithHistory(history(22, 13), false)
+	if got := numbers(j.history); !reflect.DeepEqual(got, numbers(history(22, 1))) {
+		t.Errorf("history %v after a refresh", got)
+	}
+	if !j.historyWhole {
+		t.Error("refresh lost the first build")
+	}
+
+	// A longer window asks again, the whole history covers every window
+	j.statsWindow, _ = stats.FindWindow("all")
+	j.historyWhole = false
+	if _, cmd := j.RequestHistory(); cmd == nil {
+		t.Error("no history asked for the longer window")
+	}
+	j.historyRequest = historyRequest{jobName: "app", window: j.statsWindow}
+	j.statsWindow, _ = stats.FindWindow("90d")
+	if _, cmd := j.RequestHistory(); cmd != nil {
+		t.Error("history asked for again within a window already asked for")
+	}
+
+	// Another job starts afresh
+	j = j.WithJobDetail("web", "", "").WithHistory(history(5, 1), false)
+	if _, cmd := j.RequestHistory(); cmd == nil {
+		t.Error("no history asked for another job")
+	}
+}
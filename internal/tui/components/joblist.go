@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
@@ -18,6 +19,16 @@ type JobListItem struct {
 	LastBuild time.Time
 	JobDesc   string
 	URL       string
+	Watched   bool
+	Marked    bool // Selected for a bulk action
+
+	// Shown in the table
+	Running      bool
+	BuildNumber  int // Last build, 0 if never built
+	LastDuration time.Duration
+	LastSuccess  time.Time
+	LastFailure  time.Time
+	Health       int // Health score 0-100, -1 when unknown
 }
 
 // FilterValue returns the value to filter on
@@ -27,7 +38,14 @@ func (i JobListItem) FilterValue() string {
 
 // Title returns the title of the job item
 func (i JobListItem) Title() string {
-	return i.Name
+	title := i.Name
+	if i.Watched {
+		title += " ★"
+	}
+	if i.Marked {
+		title = "✓ " + title
+	}
+	return title
 }
 
 // Description returns the description of the job item
@@ -49,12 +67,27 @@ type JobListComponent struct {
 	keys   KeyMap
 	width  int
 	height int
+
+	// Table mode
+	table       bool
+	jobs        []JobListItem
+	current     string // Name of the job under the cursor
+	offset      int    // First row shown
+	sortBy      int    // Index in jobColumns
+	sortDesc    bool
+	groupBy     string
+	tableFilter textinput.Model
+
+	// Names of the jobs selected for a bulk action
+	marked map[string]bool
+
+	clicks clickTracker
 }
 
 // NewJobList creates a new job list component
 func NewJobList() JobListComponent {
 	// Set up list
-	delegate := list.NewDefaultDelegate()
+	delegate := newItemDelegate()
 	jobList := list.New([]list.Item{}, delegate, 0, 0)
 	jobList.Title = "Jenkins Jobs"
 	jobList.SetShowStatusBar(true)
@@ -62,24 +95,189 @@ func NewJobList() JobListComponent {
 	jobList.Styles.Title = utils.TitleStyle
 	jobList.SetShowHelp(true)
 
+	keys := DefaultKeyMap()
+	applyListKeys(&jobList, keys)
+
+	tableFilter := textinput.New()
+	tableFilter.Prompt = "/ "
+	tableFilter.Placeholder = "filter jobs"
+
 	return JobListComponent{
-		list: jobList,
-		keys: DefaultKeyMap(),
+		list:        jobList,
+		keys:        keys,
+		sortBy:      1, // Name
+		tableFilter: tableFilter,
+		marked:      map[string]bool{},
 	}
 }
 
-// WithJobs adds jobs to the job list
+// WithTable shows the jobs as a table instead of a list
+func (j JobListComponent) WithTable(table bool) JobListComponent {
+	j.table = table
+	return j
+}
+
+// WithJobs adds jobs to the job list; selected jobs that are gone are
+// no longer selected
 func (j JobListComponent) WithJobs(jobs []JobListItem) JobListComponent {
+	marked := make(map[string]bool, len(j.marked))
+	j.jobs = make([]JobListItem, len(jobs))
 	items := make([]list.Item, len(jobs))
 	for i, job := range jobs {
+		if j.marked[job.Name] {
+			marked[job.Name] = true
+			job.Marked = true
+		}
+		j.jobs[i] = job
 		items[i] = job
 	}
+	j.marked = marked
 	j.list.SetItems(items)
 	return j
 }
 
+// setMarked selects or deselects jobs for a bulk action
+func (j JobListComponent) setMarked(names []string, marked bool) JobListComponent {
+	selection := make(map[string]bool, len(j.marked))
+	for name := range j.marked {
+		selection[name] = true
+	}
+	for _, name := range names {
+		if marked {
+			selection[name] = true
+		} else {
+			delete(selection, name)
+		}
+	}
+	j.marked = selection
+
+	jobs := make([]JobListItem, len(j.jobs))
+	for i, job := range j.jobs {
+		job.Marked = selection[job.Name]
+		jobs[i] = job
+	}
+	j.jobs = jobs
+	for i, item := range j.list.Items() {
+		job := item.(JobListItem)
+		job.Marked = selection[job.Name]
+		j.list.SetItem(i, job)
+	}
+	return j
+}
+
+// ClearMarks deselects every job
+func (j JobListComponent) ClearMarks() JobListComponent {
+	return j.setMarked(j.MarkedJobs(), false)
+}
+
+// visibleJobs returns the names of the jobs shown, in display order
+func (j JobListComponent) visibleJobs() []string {
+	var names []string
+	if j.table {
+		for _, row := range j.tableRows() {
+			if row.job >= 0 {
+				names = append(names, j.jobs[row.job].Name)
+			}
+		}
+		return names
+	}
+
+	for _, item := range j.list.VisibleItems() {
+		names = append(names, item.(JobListItem).Name)
+	}
+	return names
+}
+
+// MarkedJobs returns the names of the selected jobs in display order;
+// selected jobs hidden by a filter come last
+func (j JobListComponent) MarkedJobs() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range j.visibleJobs() {
+		if j.marked[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	for _, job := range j.jobs {
+		if j.marked[job.Name] && !seen[job.Name] {
+			names = append(names, job.Name)
+		}
+	}
+	return names
+}
+
+// Targets returns the jobs a bulk action applies to: the selected jobs,
+// or the job under the cursor when none is selected
+func (j JobListComponent) Targets() []string {
+	if marked := j.MarkedJobs(); len(marked) > 0 {
+		return marked
+	}
+	if selected := j.GetSelected(); selected != nil {
+		return []string{selected.Name}
+	}
+	return nil
+}
+
+// toggleMark selects or deselects the job under the cursor and moves on
+func (j JobListComponent) toggleMark() JobListComponent {
+	selected := j.GetSelected()
+	if selected == nil {
+		return j
+	}
+
+	j = j.setMarked([]string{selected.Name}, !j.marked[selected.Name])
+	if j.table {
+		return j.moveCursor(1)
+	}
+	j.list.CursorDown()
+	return j
+}
+
+// toggleMarkAll selects every job shown, or deselects them when they are
+// all selected already
+func (j JobListComponent) toggleMarkAll() JobListComponent {
+	visible := j.visibleJobs()
+	all := len(visible) > 0
+	for _, name := range visible {
+		all = all && j.marked[name]
+	}
+	return j.setMarked(visible, !all)
+}
+
+// WithKeyMap sets the keybindings used by the job list
+func (j JobListComponent) WithKeyMap(keys KeyMap) JobListComponent {
+	j.keys = keys
+	applyListKeys(&j.list, keys)
+	return j
+}
+
+// RefreshStyles re-applies the current theme
+func (j JobListComponent) RefreshStyles() JobListComponent {
+	styleList(&j.list)
+	return j
+}
+
+// Capturing reports whether the filter input is consuming key presses
+func (j JobListComponent) Capturing() bool {
+	if j.table {
+		return j.tableFilter.Focused()
+	}
+	return j.list.FilterState() == list.Filtering
+}
+
 // GetSelected returns the selected job
 func (j JobListComponent) GetSelected() *JobListItem {
+	if j.table {
+		rows := j.tableRows()
+		row := j.cursorRow(rows)
+		if row < 0 {
+			return nil
+		}
+		selected := j.jobs[rows[row].job]
+		return &selected
+	}
+
 	if j.list.SelectedItem() == nil {
 		return nil
 	}
@@ -88,6 +286,27 @@ func (j JobListComponent) GetSelected() *JobListItem {
 	return &selected
 }
 
+// SelectJob moves the cursor to a job if it is shown
+func (j JobListComponent) SelectJob(name string) JobListComponent {
+	if j.table {
+		rows := j.tableRows()
+		for _, row := range rows {
+			if row.job >= 0 && j.jobs[row.job].Name == name {
+				j.current = name
+				j.offset = j.scrollOffset(rows)
+			}
+		}
+		return j
+	}
+
+	for i, item := range j.list.VisibleItems() {
+		if item.(JobListItem).Name == name {
+			j.list.Select(i)
+		}
+	}
+	return j
+}
+
 // Init initializes the job list component
 func (j JobListComponent) Init() tea.Cmd {
 	return nil
@@ -105,7 +324,8 @@ func (j JobListComponent) Update(msg tea.Msg) (JobListComponent, tea.Cmd) {
 		j.width = msg.Width
 		j.height = msg.Height
 		j.list.SetWidth(msg.Width)
-		j.list.SetHeight(msg.Height - 10) // Allow space for header and footer
+		j.list.SetHeight(msg.Height)
+		fitListTitle(&j.list)
 
 	case tea.KeyMsg:
 		// Don't handle keys if list is filtering
@@ -116,7 +336,23 @@ func (j JobListComponent) Update(msg tea.Msg) (JobListComponent, tea.Cmd) {
 		switch {
 		case key.Matches(msg, j.keys.Quit):
 			return j, tea.Quit
+		case key.Matches(msg, j.keys.ToggleTable):
+			j.table = !j.table
+			return j, nil
+		case key.Matches(msg, j.keys.MarkJob) && !j.Capturing():
+			return j.toggleMark(), nil
+		case key.Matches(msg, j.keys.MarkAll) && !j.Capturing():
+			return j.toggleMarkAll(), nil
 		}
+
+		if j.table {
+			j, cmd = j.updateTable(msg)
+			j.offset = j.scrollOffset(j.tableRows())
+			return j, cmd
+		}
+
+	case tea.MouseMsg:
+		return j.updateMouse(msg)
 	}
 
 	// Handle list updates
@@ -126,7 +362,56 @@ func (j JobListComponent) Update(msg tea.Msg) (JobListComponent, tea.Cmd) {
 	return j, tea.Batch(cmds...)
 }
 
+// updateMouse scrolls with the wheel, selects the job clicked and opens it
+// on a double click
+func (j JobListComponent) updateMouse(msg tea.MouseMsg) (JobListComponent, tea.Cmd) {
+	if j.list.FilterState() == list.Filtering {
+		return j, nil
+	}
+
+	if delta := wheelDelta(msg); delta != 0 {
+		switch {
+		case j.table:
+			j = j.moveCursor(delta)
+			j.offset = j.scrollOffset(j.tableRows())
+		case delta < 0:
+			j.list.CursorUp()
+		default:
+			j.list.CursorDown()
+		}
+		return j, nil
+	}
+	if !isClick(msg) {
+		return j, nil
+	}
+
+	var double bool
+	j.clicks, double = j.clicks.click(msg)
+
+	if j.table {
+		var clicked bool
+		if j, clicked = j.tableClick(msg); !clicked {
+			return j, nil
+		}
+		j.offset = j.scrollOffset(j.tableRows())
+	} else {
+		i := listItemAt(j.list, msg.Y)
+		if i < 0 {
+			return j, nil
+		}
+		j.list.Select(i)
+	}
+
+	if double {
+		return j, openSelected
+	}
+	return j, nil
+}
+
 // View renders the job list component
 func (j JobListComponent) View() string {
+	if j.table {
+		return j.tableView()
+	}
 	return j.list.View()
 }
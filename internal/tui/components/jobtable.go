@@ -0,0 +1,481 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// jobColumn is a column of the job table
+type jobColumn struct {
+	name  string // Identifies the column when rendering cells
+	title string
+	width int // Zero for the name column, which takes the remaining width
+	less  func(a, b JobListItem) bool
+}
+
+// jobColumns lists the columns of the job table in display order
+var jobColumns = []jobColumn{
+	{"status", "Status", 10, func(a, b JobListItem) bool { return statusRank(a.Status) < statusRank(b.Status) }},
+	{"name", "Name", 0, func(a, b JobListItem) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }},
+	{"lastBuild", "Last Build", 14, func(a, b JobListItem) bool { return a.LastBuild.Before(b.LastBuild) }},
+	{"lastSuccess", "Last Success", 13, func(a, b JobListItem) bool { return a.LastSuccess.Before(b.LastSuccess) }},
+	{"lastFailure", "Last Failure", 13, func(a, b JobListItem) bool { return a.LastFailure.Before(b.LastFailure) }},
+	{"duration", "Duration", 10, func(a, b JobListItem) bool { return a.LastDuration < b.LastDuration }},
+	{"health", "Health", 7, func(a, b JobListItem) bool { return a.Health < b.Health }},
+}
+
+// Ways of grouping the job table
+const (
+	groupNone   = ""
+	groupStatus = "status"
+	groupFolder = "folder"
+)
+
+// jobGroupings lists the groupings in the order they are cycled through
+var jobGroupings = []string{groupNone, groupStatus, groupFolder}
+
+// statusOrder ranks job statuses with those needing attention first
+var statusOrder = []string{"failure", "unstable", "aborted", "success", "disabled", "unknown"}
+
+// statusRank returns the position of a status in statusOrder
+func statusRank(status string) int {
+	for i, s := range statusOrder {
+		if s == status {
+			return i
+		}
+	}
+	return len(statusOrder)
+}
+
+// jobFolder returns the folder a job is in, or "" at the top level
+func jobFolder(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// jobRow is a row of the job table: a group heading or a job
+type jobRow struct {
+	heading string
+	job     int // Index in jobs, -1 for headings
+}
+
+// tableRows filters, sorts and groups the jobs into the rows of the table
+func (j JobListComponent) tableRows() []jobRow {
+	filter := strings.ToLower(j.tableFilter.Value())
+	var indices []int
+	for i, job := range j.jobs {
+		if filter == "" || strings.Contains(strings.ToLower(job.Name), filter) {
+			indices = append(indices, i)
+		}
+	}
+
+	column := jobColumns[j.sortBy]
+	sort.SliceStable(indices, func(a, b int) bool {
+		x, y := j.jobs[indices[a]], j.jobs[indices[b]]
+		if j.sortDesc {
+			return column.less(y, x)
+		}
+		return column.less(x, y)
+	})
+
+	if j.groupBy == groupNone {
+		rows := make([]jobRow, len(indices))
+		for i, index := range indices {
+			rows[i] = jobRow{job: index}
+		}
+		return rows
+	}
+
+	// Groups keep the sort order within them
+	group := func(index int) string {
+		if j.groupBy == groupStatus {
+			return j.jobs[index].Status
+		}
+		return jobFolder(j.jobs[index].Name)
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		x, y := group(indices[a]), group(indices[b])
+		if j.groupBy == groupStatus {
+			return statusRank(x) < statusRank(y)
+		}
+		return x < y
+	})
+
+	counts := map[string]int{}
+	for _, index := range indices {
+		counts[group(index)]++
+	}
+
+	var rows []jobRow
+	for i, index := range indices {
+		name := group(index)
+		if i == 0 || group(indices[i-1]) != name {
+			if name == "" {
+				name = "(top level)"
+			}
+			rows = append(rows, jobRow{heading: fmt.Sprintf("%s (%d)", name, counts[group(index)]), job: -1})
+		}
+		rows = append(rows, jobRow{job: index})
+	}
+	return rows
+}
+
+// cursorRow returns the row of the current job, or the first job row
+func (j JobListComponent) cursorRow(rows []jobRow) int {
+	first := -1
+	for i, row := range rows {
+		if row.job < 0 {
+			continue
+		}
+		if j.jobs[row.job].Name == j.current {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+// moveCursor moves the current job by delta job rows, skipping headings
+func (j JobListComponent) moveCursor(delta int) JobListComponent {
+	rows := j.tableRows()
+	row := j.cursorRow(rows)
+	if row < 0 {
+		return j
+	}
+
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	for next := row + step; delta > 0 && next >= 0 && next < len(rows); next += step {
+		if rows[next].job >= 0 {
+			row = next
+			delta--
+		}
+	}
+
+	j.current = j.jobs[rows[row].job].Name
+	return j
+}
+
+// scrollOffset returns the first row shown, scrolled just enough from the
+// last position to keep the current job in view
+func (j JobListComponent) scrollOffset(rows []jobRow) int {
+	height := j.tableHeight(rows)
+	cursor := j.cursorRow(rows)
+	offset := min(j.offset, max(len(rows)-height, 0))
+	if cursor >= 0 && cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+
+	// Show the heading of the group at the top
+	if offset > 0 && cursor == offset && rows[offset-1].job < 0 {
+		offset--
+	}
+	return offset
+}
+
+// tableHeight returns how many rows of the table fit under the heading and
+// above the row count
+func (j JobListComponent) tableHeight(rows []jobRow) int {
+	return max(j.height-strings.Count(j.tableHeading(rows), "\n")-1, 3)
+}
+
+// updateTable handles key presses in table mode
+func (j JobListComponent) updateTable(msg tea.KeyMsg) (JobListComponent, tea.Cmd) {
+	if j.tableFilter.Focused() {
+		switch msg.Type {
+		case tea.KeyEnter:
+			j.tableFilter.Blur()
+			return j, nil
+		case tea.KeyEsc:
+			j.tableFilter.Blur()
+			j.tableFilter.SetValue("")
+			return j, nil
+		}
+
+		var cmd tea.Cmd
+		j.tableFilter, cmd = j.tableFilter.Update(msg)
+		j.offset = 0
+		return j, cmd
+	}
+
+	switch {
+	case key.Matches(msg, j.keys.Up):
+		return j.moveCursor(-1), nil
+	case key.Matches(msg, j.keys.Down):
+		return j.moveCursor(1), nil
+	case key.Matches(msg, j.keys.Left):
+		return j.moveCursor(-j.tableHeight(j.tableRows())), nil
+	case key.Matches(msg, j.keys.Right):
+		return j.moveCursor(j.tableHeight(j.tableRows())), nil
+	case key.Matches(msg, j.keys.SortBy):
+		j.sortBy = (j.sortBy + 1) % len(jobColumns)
+		return j, nil
+	case key.Matches(msg, j.keys.ReverseSort):
+		j.sortDesc = !j.sortDesc
+		return j, nil
+	case key.Matches(msg, j.keys.GroupBy):
+		for i, grouping := range jobGroupings {
+			if grouping == j.groupBy {
+				j.groupBy = jobGroupings[(i+1)%len(jobGroupings)]
+				break
+			}
+		}
+		return j, nil
+	case msg.String() == "/":
+		return j, j.tableFilter.Focus()
+	}
+	return j, nil
+}
+
+// tableClick selects the job in the row clicked, or sorts by the column
+// whose header was clicked, reversing the order if it is already sorted by
+// it. It reports whether a job was clicked.
+func (j JobListComponent) tableClick(msg tea.MouseMsg) (JobListComponent, bool) {
+	rows := j.tableRows()
+	top := strings.Count(j.tableHeading(rows), "\n")
+
+	if msg.Y == top-1 {
+		if column := j.columnAt(msg.X); column >= 0 {
+			if column == j.sortBy {
+				j.sortDesc = !j.sortDesc
+			} else {
+				j.sortBy, j.sortDesc = column, false
+			}
+		}
+		return j, false
+	}
+
+	if msg.Y < top || msg.Y-top >= j.tableHeight(rows) {
+		return j, false
+	}
+	i := j.scrollOffset(rows) + msg.Y - top
+	if i >= len(rows) || rows[i].job < 0 {
+		return j, false
+	}
+	j.current = j.jobs[rows[i].job].Name
+	return j, true
+}
+
+// columnWidth returns the width of a column of the table; the name column
+// takes what the fixed columns leave
+func (j JobListComponent) columnWidth(column jobColumn) int {
+	if column.width > 0 {
+		return column.width
+	}
+
+	nameWidth := max(j.width, 60) - 4 // Cursor and mark
+	for _, column := range jobColumns {
+		nameWidth -= column.width + 1
+	}
+	return max(nameWidth, 12)
+}
+
+// columnAt returns the index of the column at column x of the table, or -1
+func (j JobListComponent) columnAt(x int) int {
+	start := 4 // Cursor and mark
+	for i, column := range jobColumns {
+		end := start + j.columnWidth(column) + 1
+		if x >= start && x < end {
+			return i
+		}
+		start = end
+	}
+	return -1
+}
+
+// tableHeading renders what the table shows above its rows: the title, the
+// status line and the column header, which is the last line
+func (j JobListComponent) tableHeading(rows []jobRow) string {
+	var sb strings.Builder
+	sb.WriteString(utils.TitleStyle.Render("Jenkins Jobs"))
+	sb.WriteString("\n")
+	sb.WriteString(j.tableStatus(rows))
+	sb.WriteString("\n\n")
+
+	// Header, with an arrow on the sorted column
+	var header strings.Builder
+	header.WriteString("    ")
+	for i, column := range jobColumns {
+		title := column.title
+		switch {
+		case i == j.sortBy && j.sortDesc:
+			title += " ▼"
+		case i == j.sortBy:
+			title += " ▲"
+		}
+		header.WriteString(padName(title, j.columnWidth(column)) + " ")
+	}
+	sb.WriteString(utils.HeaderText.Render(strings.TrimRight(header.String(), " ")))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// tableView renders the jobs as a table
+func (j JobListComponent) tableView() string {
+	var sb strings.Builder
+	rows := j.tableRows()
+	sb.WriteString(j.tableHeading(rows))
+
+	if len(rows) == 0 {
+		sb.WriteString(utils.MutedText.Render("    No jobs"))
+		return sb.String()
+	}
+
+	height := j.tableHeight(rows)
+	cursor := j.cursorRow(rows)
+	offset := j.scrollOffset(rows)
+
+	var lines []string
+	for i := offset; i < min(offset+height, len(rows)); i++ {
+		row := rows[i]
+		if row.job < 0 {
+			lines = append(lines, utils.HeaderText.Render("  ▾ "+row.heading))
+			continue
+		}
+		lines = append(lines, j.tableLine(j.jobs[row.job], i == cursor))
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+
+	if len(rows) > height {
+		sb.WriteString("\n" + utils.MutedText.Render(fmt.Sprintf("    %d–%d of %d rows", offset+1, min(offset+height, len(rows)), len(rows))))
+	}
+	return sb.String()
+}
+
+// tableStatus describes the sorting, grouping, filter and selection
+func (j JobListComponent) tableStatus(rows []jobRow) string {
+	jobs := 0
+	for _, row := range rows {
+		if row.job >= 0 {
+			jobs++
+		}
+	}
+
+	order := "ascending"
+	if j.sortDesc {
+		order = "descending"
+	}
+	parts := []string{
+		fmt.Sprintf("%d jobs", jobs),
+		fmt.Sprintf("sorted by %s (%s)", strings.ToLower(jobColumns[j.sortBy].title), order),
+	}
+	if j.groupBy != groupNone {
+		parts = append(parts, "grouped by "+j.groupBy)
+	}
+	if marked := len(j.MarkedJobs()); marked > 0 {
+		parts = append(parts, utils.WarningText.Render(fmt.Sprintf("%d selected", marked)))
+	}
+
+	status := utils.MutedText.Render(strings.Join(parts, " • "))
+	if j.tableFilter.Focused() || j.tableFilter.Value() != "" {
+		status += "\n" + j.tableFilter.View()
+	}
+	return status
+}
+
+// tableLine renders a job as a row of the table
+func (j JobListComponent) tableLine(job JobListItem, current bool) string {
+	cursor := "  "
+	if current {
+		cursor = utils.HeaderText.Render("▸ ")
+	}
+	mark := "  "
+	if job.Marked {
+		mark = utils.WarningText.Render("✓ ")
+	}
+
+	cells := make([]string, len(jobColumns))
+	for i, column := range jobColumns {
+		width := j.columnWidth(column)
+		switch column.name {
+		case "status":
+			cells[i] = utils.StatusStyle(job.Status).Render(padName("● "+job.Status, width))
+		case "name":
+			name := job.Name
+			if job.Running {
+				name = "▶ " + name
+			}
+			if job.Watched {
+				name += " ★"
+			}
+			style := utils.NormalText
+			if current {
+				style = lipgloss.NewStyle().Foreground(utils.ColorHighlight).Bold(true)
+			}
+			cells[i] = style.Render(padName(name, width))
+		case "lastBuild":
+			text := ""
+			if job.BuildNumber > 0 {
+				text = fmt.Sprintf("#%-5d %s", job.BuildNumber, shortAgo(job.LastBuild))
+			}
+			cells[i] = padName(text, width)
+		case "lastSuccess":
+			cells[i] = padName(shortAgo(job.LastSuccess), width)
+		case "lastFailure":
+			cells[i] = padName(shortAgo(job.LastFailure), width)
+		case "duration":
+			text := ""
+			if job.LastDuration > 0 {
+				text = shortDuration(job.LastDuration)
+			}
+			cells[i] = padName(text, width)
+		case "health":
+			cells[i] = healthCell(job.Health, width)
+		}
+	}
+
+	return cursor + mark + strings.Join(cells, " ")
+}
+
+// healthCell renders a health score coloured by how healthy it is
+func healthCell(score, width int) string {
+	if score < 0 {
+		return utils.MutedText.Render(padName("–", width))
+	}
+
+	style := utils.SuccessText
+	switch {
+	case score < 40:
+		style = utils.FailureText
+	case score < 80:
+		style = utils.WarningText
+	}
+	return style.Render(runewidth.FillRight(fmt.Sprintf("%d%%", score), width))
+}
+
+// shortAgo renders how long ago a time was in a few cells, e.g. "3h ago"
+func shortAgo(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dmo ago", int(d.Hours()/24/30))
+}
@@ -1,6 +1,30 @@
 package components
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+// Views that can be given their own keybindings in the config file
+const (
+	DashboardKeys  = "dashboard"
+	JobListKeys    = "jobs"
+	JobDetailKeys  = "job"
+	BuildLogKeys   = "log"
+	LogDiffKeys    = "diff"
+	BuildChartKeys = "chart"
+	HelpKeys       = "help"
+	ErrorLogKeys   = "errors"
+)
+
+// keyViews lists every view name in display order
+var keyViews = []string{DashboardKeys, JobListKeys, JobDetailKeys, BuildLogKeys, LogDiffKeys, BuildChartKeys, HelpKeys, ErrorLogKeys}
 
 // KeyMap defines the keybindings for the application
 type KeyMap struct {
@@ -8,63 +32,381 @@ type KeyMap struct {
 	Down      key.Binding
 	Left      key.Binding
 	Right     key.Binding
+	PageUp    key.Binding
+	PageDown  key.Binding
 	Help      key.Binding
 	Quit      key.Binding
 	Enter     key.Binding
 	Back      key.Binding
+	Forward   key.Binding
 	Dashboard key.Binding
 	Jobs      key.Binding
 	Refresh   key.Binding
+	Theme     key.Binding
+	Watch     key.Binding
+	Palette   key.Binding
+	Command   key.Binding
+	ErrorLog  key.Binding
+
+	// Split panes
+	ToggleSplit key.Binding
+	NextPane    key.Binding
+	GrowPane    key.Binding
+	ShrinkPane  key.Binding
+
+	// Build log search
+	Search      key.Binding
+	NextMatch   key.Binding
+	PrevMatch   key.Binding
+	ToggleRegex key.Binding
+	ToggleCase  key.Binding
+
+	// Build log filter
+	Filter       key.Binding
+	ToggleFilter key.Binding
+	InvertFilter key.Binding
+	MoreContext  key.Binding
+	LessContext  key.Binding
+
+	// Build log export
+	SaveLog    key.Binding
+	OpenPager  key.Binding
+	OpenEditor key.Binding
+
+	// Build comparison
+	MarkBuild     key.Binding
+	CompareBuilds key.Binding
+	NextHunk      key.Binding
+	PrevHunk      key.Binding
+	DiffLayout    key.Binding
+
+	// Failure summary
+	NextFailure    key.Binding
+	PrevFailure    key.Binding
+	ToggleFailures key.Binding
+
+	// Pipeline sections
+	ToggleSection key.Binding
+	NextSection   key.Binding
+	PrevSection   key.Binding
+	ToggleOutline key.Binding
+
+	// Build duration chart
+	Chart     key.Binding
+	PrevBuild key.Binding
+	NextBuild key.Binding
+
+	// Reliability statistics
+	StatsWindow key.Binding
+	ExportStats key.Binding
+
+	// Job table and bulk actions
+	ToggleTable key.Binding
+	SortBy      key.Binding
+	ReverseSort key.Binding
+	GroupBy     key.Binding
+	MarkJob     key.Binding
+	MarkAll     key.Binding
+	BuildJobs   key.Binding
+	StopJobs    key.Binding
+	EnableJobs  key.Binding
+	DisableJobs key.Binding
+
+	// Destructive actions
+	StopBuild   key.Binding
+	DeleteJob   key.Binding
+	DeleteBuild key.Binding
+}
+
+// bindingSpec describes a remappable action and where it is active
+type bindingSpec struct {
+	action string
+	keys   []string
+	help   string
+	views  []string // nil means every view
+	field  func(k *KeyMap) *key.Binding
 }
 
-// DefaultKeyMap returns a KeyMap with default keybindings
+// listViews are the views built around a navigable list
+var listViews = []string{JobListKeys, JobDetailKeys}
+
+// scrollViews are the views with vertical cursor or scroll movement
+var scrollViews = []string{JobListKeys, JobDetailKeys, BuildLogKeys, LogDiffKeys, ErrorLogKeys}
+
+// pagedViews are the views scrolled a page at a time
+var pagedViews = []string{BuildLogKeys, LogDiffKeys, ErrorLogKeys}
+
+// bindingSpecs holds the default binding of every action
+var bindingSpecs = []bindingSpec{
+	{"up", []string{"up", "k"}, "up", scrollViews, func(k *KeyMap) *key.Binding { return &k.Up }},
+	{"down", []string{"down", "j"}, "down", scrollViews, func(k *KeyMap) *key.Binding { return &k.Down }},
+	{"left", []string{"left", "h"}, "prev page", listViews, func(k *KeyMap) *key.Binding { return &k.Left }},
+	{"right", []string{"right", "l"}, "next page", listViews, func(k *KeyMap) *key.Binding { return &k.Right }},
+	{"pageUp", []string{"pgup"}, "page up", pagedViews, func(k *KeyMap) *key.Binding { return &k.PageUp }},
+	{"pageDown", []string{"pgdown", " "}, "page down", pagedViews, func(k *KeyMap) *key.Binding { return &k.PageDown }},
+	{"help", []string{"?"}, "help", nil, func(k *KeyMap) *key.Binding { return &k.Help }},
+	{"quit", []string{"q", "ctrl+c"}, "quit", nil, func(k *KeyMap) *key.Binding { return &k.Quit }},
+	{"enter", []string{"enter"}, "select", []string{JobListKeys, JobDetailKeys, BuildChartKeys}, func(k *KeyMap) *key.Binding { return &k.Enter }},
+	{"back", []string{"esc", "alt+left"}, "back", nil, func(k *KeyMap) *key.Binding { return &k.Back }},
+	{"forward", []string{"alt+right", "ctrl+f"}, "forward", nil, func(k *KeyMap) *key.Binding { return &k.Forward }},
+	{"dashboard", []string{"d"}, "dashboard", nil, func(k *KeyMap) *key.Binding { return &k.Dashboard }},
+	{"jobs", []string{"J"}, "jobs", nil, func(k *KeyMap) *key.Binding { return &k.Jobs }},
+	{"refresh", []string{"r"}, "refresh", nil, func(k *KeyMap) *key.Binding { return &k.Refresh }},
+	{"theme", []string{"T"}, "next theme", nil, func(k *KeyMap) *key.Binding { return &k.Theme }},
+	{"watch", []string{"w"}, "watch/unwatch job", listViews, func(k *KeyMap) *key.Binding { return &k.Watch }},
+	{"palette", []string{"ctrl+p"}, "command palette", nil, func(k *KeyMap) *key.Binding { return &k.Palette }},
+	{"command", []string{":"}, "command mode", nil, func(k *KeyMap) *key.Binding { return &k.Command }},
+	{"errorLog", []string{"ctrl+e"}, "error log", nil, func(k *KeyMap) *key.Binding { return &k.ErrorLog }},
+	{"toggleSplit", []string{"ctrl+w"}, "split panes on/off", nil, func(k *KeyMap) *key.Binding { return &k.ToggleSplit }},
+	{"nextPane", []string{"tab"}, "next pane", nil, func(k *KeyMap) *key.Binding { return &k.NextPane }},
+	{"growPane", []string{">"}, "grow pane", nil, func(k *KeyMap) *key.Binding { return &k.GrowPane }},
+	{"shrinkPane", []string{"<"}, "shrink pane", nil, func(k *KeyMap) *key.Binding { return &k.ShrinkPane }},
+	{"search", []string{"/"}, "search", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.Search }},
+	{"nextMatch", []string{"n"}, "next match", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.NextMatch }},
+	{"prevMatch", []string{"N"}, "prev match", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.PrevMatch }},
+	{"toggleRegex", []string{"ctrl+r"}, "regex on/off", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleRegex }},
+	{"toggleCase", []string{"ctrl+t"}, "match case on/off", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleCase }},
+	{"filter", []string{"&"}, "filter lines", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.Filter }},
+	{"toggleFilter", []string{"F"}, "filter on/off", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleFilter }},
+	{"invertFilter", []string{"!"}, "invert filter", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.InvertFilter }},
+	{"moreContext", []string{"+"}, "more context", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.MoreContext }},
+	{"lessContext", []string{"-"}, "less context", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.LessContext }},
+	{"saveLog", []string{"s"}, "save log", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.SaveLog }},
+	{"openPager", []string{"|"}, "open in $PAGER", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.OpenPager }},
+	{"openEditor", []string{"e"}, "open in $EDITOR", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.OpenEditor }},
+	{"markBuild", []string{"m"}, "mark build", []string{JobDetailKeys}, func(k *KeyMap) *key.Binding { return &k.MarkBuild }},
+	{"compareBuilds", []string{"c"}, "diff marked builds", []string{JobDetailKeys}, func(k *KeyMap) *key.Binding { return &k.CompareBuilds }},
+	{"nextHunk", []string{"n"}, "next hunk", []string{LogDiffKeys}, func(k *KeyMap) *key.Binding { return &k.NextHunk }},
+	{"prevHunk", []string{"N"}, "prev hunk", []string{LogDiffKeys}, func(k *KeyMap) *key.Binding { return &k.PrevHunk }},
+	{"nextFailure", []string{"x"}, "next failure", []string{JobDetailKeys, BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.NextFailure }},
+	{"prevFailure", []string{"X"}, "prev failure", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.PrevFailure }},
+	{"toggleFailures", []string{"A"}, "failure summary on/off", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleFailures }},
+	{"toggleSection", []string{"enter"}, "fold/unfold stage", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleSection }},
+	{"nextSection", []string{"]"}, "next stage", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.NextSection }},
+	{"prevSection", []string{"["}, "prev stage", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.PrevSection }},
+	{"toggleOutline", []string{"o"}, "stages/raw log", []string{BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleOutline }},
+	{"diffLayout", []string{"v"}, "unified/side-by-side", []string{LogDiffKeys}, func(k *KeyMap) *key.Binding { return &k.DiffLayout }},
+	{"chart", []string{"g"}, "duration chart", []string{JobDetailKeys}, func(k *KeyMap) *key.Binding { return &k.Chart }},
+	{"prevBuild", []string{"left", "h"}, "prev build", []string{BuildChartKeys}, func(k *KeyMap) *key.Binding { return &k.PrevBuild }},
+	{"nextBuild", []string{"right", "l"}, "next build", []string{BuildChartKeys}, func(k *KeyMap) *key.Binding { return &k.NextBuild }},
+	{"statsWindow", []string{"S"}, "stats window", []string{JobDetailKeys}, func(k *KeyMap) *key.Binding { return &k.StatsWindow }},
+	{"exportStats", []string{"E"}, "export stats as CSV", []string{DashboardKeys, JobListKeys}, func(k *KeyMap) *key.Binding { return &k.ExportStats }},
+	{"toggleTable", []string{"t"}, "table/list", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.ToggleTable }},
+	{"sortBy", []string{"s"}, "sort column", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.SortBy }},
+	{"reverseSort", []string{"S"}, "reverse sort", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.ReverseSort }},
+	{"groupBy", []string{"v"}, "group by status/folder", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.GroupBy }},
+	{"markJob", []string{" "}, "select job", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.MarkJob }},
+	{"markAll", []string{"a"}, "select all", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.MarkAll }},
+	{"buildJobs", []string{"b"}, "build", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.BuildJobs }},
+	{"stopJobs", []string{"x"}, "stop builds", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.StopJobs }},
+	{"enableJobs", []string{"+"}, "enable", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.EnableJobs }},
+	{"disableJobs", []string{"-"}, "disable", []string{JobListKeys}, func(k *KeyMap) *key.Binding { return &k.DisableJobs }},
+	{"stopBuild", []string{"ctrl+x"}, "stop build (again to force)", []string{JobListKeys, JobDetailKeys, BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.StopBuild }},
+	{"deleteJob", []string{"D"}, "delete job", listViews, func(k *KeyMap) *key.Binding { return &k.DeleteJob }},
+	{"deleteBuild", []string{"delete"}, "delete build", []string{JobDetailKeys, BuildLogKeys}, func(k *KeyMap) *key.Binding { return &k.DeleteBuild }},
+}
+
+// ignoredActions were accepted by old config files but never had an effect
+var ignoredActions = map[string]bool{"builds": true, "nodes": true}
+
+// KeyMaps holds the effective KeyMap of every view
+type KeyMaps map[string]KeyMap
+
+// For returns the KeyMap of a view, falling back to the defaults
+func (k KeyMaps) For(view string) KeyMap {
+	if keys, ok := k[view]; ok {
+		return keys
+	}
+	return DefaultKeyMap()
+}
+
+// DefaultKeyMap returns a KeyMap with default keybindings for all actions
 func DefaultKeyMap() KeyMap {
-	return KeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "up"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "down"),
-		),
-		Left: key.NewBinding(
-			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "left"),
-		),
-		Right: key.NewBinding(
-			key.WithKeys("right", "l"),
-			key.WithHelp("→/l", "right"),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "help"),
-		),
-		Quit: key.NewBinding(
-			key.WithKeys("q", "ctrl+c"),
-			key.WithHelp("q/ctrl+c", "quit"),
-		),
-		Enter: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
-		),
-		Back: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "back"),
-		),
-		Dashboard: key.NewBinding(
-			key.WithKeys("d"),
-			key.WithHelp("d", "dashboard"),
-		),
-		Jobs: key.NewBinding(
-			key.WithKeys("j"),
-			key.WithHelp("j", "jobs"),
-		),
-		Refresh: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
+	var k KeyMap
+	for _, spec := range bindingSpecs {
+		*spec.field(&k) = newBinding(spec.keys, spec.help)
+	}
+	return k
+}
+
+// NewKeyMaps builds the per-view KeyMaps from the configured overrides and
+// rejects unknown actions, unknown views and keys bound twice in one view
+func NewKeyMaps(cfg config.KeyBindings) (KeyMaps, error) {
+	global, err := parseOverrides("keybindings", cfg.Global)
+	if err != nil {
+		return nil, err
+	}
+
+	for view := range cfg.Views {
+		if !containsString(keyViews, view) {
+			return nil, fmt.Errorf("keybindings.views: unknown view %q (valid views: %s)",
+				view, strings.Join(keyViews, ", "))
+		}
+	}
+
+	keyMaps := make(KeyMaps, len(keyViews))
+	for _, view := range keyViews {
+		local, err := parseOverrides("keybindings.views."+view, cfg.Views[view])
+		if err != nil {
+			return nil, err
+		}
+
+		var k KeyMap
+		owners := map[string][]string{}
+		for _, spec := range bindingSpecs {
+			keys := spec.keys
+			if override, ok := global[spec.action]; ok {
+				keys = override
+			}
+			if override, ok := local[spec.action]; ok {
+				keys = override
+			}
+
+			binding := newBinding(keys, spec.help)
+			if spec.views != nil && !containsString(spec.views, view) {
+				binding.SetEnabled(false)
+			} else {
+				for _, k := range keys {
+					owners[k] = append(owners[k], spec.action)
+				}
+			}
+			*spec.field(&k) = binding
+		}
+
+		if err := checkConflicts(view, owners); err != nil {
+			return nil, err
+		}
+		keyMaps[view] = k
 	}
+
+	return keyMaps, nil
+}
+
+// parseOverrides validates action names and splits their key lists
+func parseOverrides(section string, overrides map[string]string) (map[string][]string, error) {
+	parsed := make(map[string][]string, len(overrides))
+	for action, value := range overrides {
+		if ignoredActions[action] {
+			continue
+		}
+		if findSpec(action) == nil {
+			return nil, fmt.Errorf("%s: unknown action %q (valid actions: %s)",
+				section, action, strings.Join(actionNames(), ", "))
+		}
+
+		var keys []string
+		for _, k := range strings.Split(value, ",") {
+			switch k = strings.TrimSpace(k); k {
+			case "":
+			case "space":
+				keys = append(keys, " ")
+			default:
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%s: action %q has no keys", section, action)
+		}
+		parsed[action] = keys
+	}
+	return parsed, nil
+}
+
+// checkConflicts reports every key that more than one action uses in a view
+func checkConflicts(view string, owners map[string][]string) error {
+	var conflicts []string
+	for k, actions := range owners {
+		if len(actions) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%q is bound to %s", k, strings.Join(actions, " and ")))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Strings(conflicts)
+	return fmt.Errorf("keybindings conflict in %s view: %s", view, strings.Join(conflicts, "; "))
+}
+
+// findSpec returns the spec of an action, or nil if there is none
+func findSpec(action string) *bindingSpec {
+	for i := range bindingSpecs {
+		if bindingSpecs[i].action == action {
+			return &bindingSpecs[i]
+		}
+	}
+	return nil
+}
+
+// actionNames returns the names of all remappable actions
+func actionNames() []string {
+	names := make([]string, len(bindingSpecs))
+	for i, spec := range bindingSpecs {
+		names[i] = spec.action
+	}
+	return names
+}
+
+// newBinding creates a binding whose help label is derived from its keys
+func newBinding(keys []string, help string) key.Binding {
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(keyLabel(keys), help),
+	)
+}
+
+// keyLabel renders keys the way the help view shows them, e.g. "↑/k"
+func keyLabel(keys []string) string {
+	symbols := map[string]string{
+		"up":    "↑",
+		"down":  "↓",
+		"left":  "←",
+		"right": "→",
+		" ":     "space",
+	}
+
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		if symbol, ok := symbols[k]; ok {
+			labels[i] = symbol
+		} else {
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// containsString reports whether s is in values
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// applyListKeys maps the navigation bindings onto a bubbles list and leaves
+// quitting and help to the application
+func applyListKeys(l *list.Model, k KeyMap) {
+	l.KeyMap.CursorUp = k.Up
+	l.KeyMap.CursorDown = k.Down
+	l.KeyMap.PrevPage = k.Left
+	l.KeyMap.NextPage = k.Right
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+}
+
+// applyViewportKeys maps the scrolling bindings onto a viewport
+func applyViewportKeys(v *viewport.Model, k KeyMap) {
+	v.KeyMap.Up = k.Up
+	v.KeyMap.Down = k.Down
+	v.KeyMap.PageUp = k.PageUp
+	v.KeyMap.PageDown = k.PageDown
+	v.KeyMap.HalfPageUp = key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up"))
+	v.KeyMap.HalfPageDown = key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down"))
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
@@ -75,8 +417,20 @@ func (k KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns keybindings for the expanded help view
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Left, k.Right},
-		{k.Enter, k.Back, k.Help, k.Quit},
-		{k.Dashboard, k.Jobs, k.Refresh},
+		{k.Up, k.Down, k.Left, k.Right, k.PageUp, k.PageDown},
+		{k.Enter, k.Back, k.Forward, k.Help, k.Quit},
+		{k.Dashboard, k.Jobs, k.Refresh, k.Theme, k.Watch, k.Palette, k.Command, k.ErrorLog},
+		{k.ToggleSplit, k.NextPane, k.GrowPane, k.ShrinkPane},
+		{k.Search, k.NextMatch, k.PrevMatch, k.ToggleRegex, k.ToggleCase},
+		{k.Filter, k.ToggleFilter, k.InvertFilter, k.MoreContext, k.LessContext},
+		{k.SaveLog, k.OpenPager, k.OpenEditor},
+		{k.MarkBuild, k.CompareBuilds, k.NextHunk, k.PrevHunk, k.DiffLayout},
+		{k.NextFailure, k.PrevFailure, k.ToggleFailures},
+		{k.ToggleSection, k.NextSection, k.PrevSection, k.ToggleOutline},
+		{k.Chart, k.PrevBuild, k.NextBuild},
+		{k.StatsWindow, k.ExportStats},
+		{k.ToggleTable, k.SortBy, k.ReverseSort, k.GroupBy},
+		{k.MarkJob, k.MarkAll, k.BuildJobs, k.StopJobs, k.EnableJobs, k.DisableJobs},
+		{k.StopBuild, k.DeleteJob, k.DeleteBuild},
 	}
 }
@@ -0,0 +1,83 @@
+package components
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+func TestNewKeyMaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.KeyBindings
+		wantErr string // Part of the error, empty for none
+	}{
+		{"defaults", config.KeyBindings{}, ""},
+		{"global override", config.KeyBindings{Global: map[string]string{"refresh": "ctrl+r"}}, "conflict in log view: \"ctrl+r\" is bound to refresh and toggleRegex"},
+		{"conflict in one view only", config.KeyBindings{Global: map[string]string{"chart": "c"}}, "conflict in job view: \"c\" is bound to compareBuilds and chart"},
+		{"view override resolves it", config.KeyBindings{
+			Global: map[string]string{"refresh": "ctrl+r"},
+			Views:  map[string]map[string]string{"log": {"toggleRegex": "alt+r"}},
+		}, ""},
+		{"key of a disabled action", config.KeyBindings{Views: map[string]map[string]string{"dashboard": {"refresh": "n"}}}, ""},
+		{"key listed twice", config.KeyBindings{Global: map[string]string{"jobs": "J,J"}}, "\"J\" is bound to jobs and jobs"},
+		{"unknown action", config.KeyBindings{Global: map[string]string{"launch": "L"}}, "keybindings: unknown action \"launch\""},
+		{"unknown action in view", config.KeyBindings{Views: map[string]map[string]string{"log": {"launch": "L"}}}, "keybindings.views.log: unknown action"},
+		{"unknown view", config.KeyBindings{Views: map[string]map[string]string{"nodes": {"refresh": "R"}}}, "unknown view \"nodes\""},
+		{"no keys", config.KeyBindings{Global: map[string]string{"refresh": " , "}}, "action \"refresh\" has no keys"},
+		{"ignored action", config.KeyBindings{Global: map[string]string{"builds": "b"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyMaps(tt.cfg)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("no error, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("error %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyMapOverrides(t *testing.T) {
+	keyMaps, err := NewKeyMaps(config.KeyBindings{
+		Global: map[string]string{"refresh": "R, space", "pageDown": "pgdown"},
+		Views:  map[string]map[string]string{"jobs": {"refresh": "ctrl+l"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		view string
+		keys []string
+	}{
+		{DashboardKeys, []string{"R", " "}},
+		{JobListKeys, []string{"ctrl+l"}},
+		{JobDetailKeys, []string{"R", " "}},
+	}
+	for _, tt := range tests {
+		if got := keyMaps.For(tt.view).Refresh.Keys(); !reflect.DeepEqual(got, tt.keys) {
+			t.Errorf("refresh in %s view bound to %q, want %q", tt.view, got, tt.keys)
+		}
+	}
+
+	// Actions of other views are disabled, so their keys are free
+	if keyMaps.For(DashboardKeys).Search.Enabled() {
+		t.Error("search enabled in the dashboard")
+	}
+	if !keyMaps.For(BuildLogKeys).Search.Enabled() {
+		t.Error("search disabled in the build log")
+	}
+
+	// Views without a KeyMap fall back to the defaults
+	if got := KeyMaps(nil).For(BuildLogKeys).Search.Keys(); !reflect.DeepEqual(got, []string{"/"}) {
+		t.Errorf("default search bound to %q", got)
+	}
+}
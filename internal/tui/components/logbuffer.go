@@ -0,0 +1,139 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// styleCacheSize is how many coloured lines are kept before the cache is
+// cleared; a few screens' worth is enough to make scrolling back cheap
+const styleCacheSize = 4096
+
+// logBuffer holds a build log line by line as its ranges arrive. Lines are
+// kept as plain text for searching and filtering and only coloured when
+// they are shown, so the cost of drawing the log does not grow with its size.
+// The log is kept once: plain lines share the text of the ranges, and only
+// lines that stripping changed keep their raw form too.
+type logBuffer struct {
+	lines    []string       // Plain text of every complete line
+	escaped  map[int]string // Raw text of lines carrying escape sequences
+	partial  string         // Unterminated last line, completed by the next range
+	size     int64
+	next     int64 // Byte offset of the next range to fetch
+	complete bool
+	styled   map[int]string
+}
+
+// newLogBuffer creates an empty buffer waiting for its first range
+func newLogBuffer() logBuffer {
+	return logBuffer{
+		escaped: map[int]string{},
+		styled:  map[int]string{},
+	}
+}
+
+// append adds a range of raw text; complete marks it as the end of the log
+func (l *logBuffer) append(text string, next int64, complete bool) {
+	l.size += int64(len(text))
+	l.next = next
+	l.complete = complete
+
+	partial := l.partial
+	l.partial = ""
+	if !complete {
+		// Hold back an unterminated last line until the rest of it arrives
+		i := strings.LastIndexByte(text, '\n')
+		if i < 0 {
+			l.partial = partial + text
+			return
+		}
+		l.partial = text[i+1:]
+		text = text[:i]
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		if i == 0 && partial != "" {
+			// Only the line split between ranges is copied
+			line = partial + line
+		}
+		plain := utils.StripANSI(line)
+		if plain != line {
+			l.escaped[len(l.lines)] = line
+		}
+		l.lines = append(l.lines, plain)
+	}
+}
+
+// empty reports whether no log text has arrived
+func (l logBuffer) empty() bool {
+	return l.size == 0
+}
+
+// text returns the raw log received so far, colours included
+func (l logBuffer) text() string {
+	var sb strings.Builder
+	sb.Grow(int(l.size))
+	for i, line := range l.lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if raw, ok := l.escaped[i]; ok {
+			line = raw
+		}
+		sb.WriteString(line)
+	}
+	// The line break before the held back line was held back with it
+	if !l.complete && len(l.lines) > 0 {
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(l.partial)
+	return sb.String()
+}
+
+// plain returns the log received so far without escape sequences
+func (l logBuffer) plain() string {
+	return strings.Join(l.lines, "\n")
+}
+
+// style returns the coloured form of a line; results are cached until the
+// cache fills up or the theme changes
+func (l logBuffer) style(line int) string {
+	if styled, ok := l.styled[line]; ok {
+		return styled
+	}
+
+	styled := l.colorize(line)
+	if len(l.styled) >= styleCacheSize {
+		clear(l.styled)
+	}
+	l.styled[line] = styled
+	return styled
+}
+
+// colorize colours a line, keeping the log's own ANSI colours
+func (l logBuffer) colorize(line int) string {
+	if raw, ok := l.escaped[line]; ok {
+		if sanitized, colored := utils.SanitizeANSI(raw); colored {
+			return sanitized
+		}
+	}
+	return utils.ColorizeLogLine(l.lines[line])
+}
+
+// restyle drops the cached colours, e.g. after the theme changed
+func (l *logBuffer) restyle() {
+	l.styled = map[int]string{}
+}
+
+// formatSize renders a byte count for the footer, e.g. "12.5 MB"
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,95 @@
+package components
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLogBuffer(t *testing.T) {
+	tests := []struct {
+		name      string
+		ranges    []string // The last one completes the log
+		wantLines []string
+	}{
+		{"one range", []string{"a\nb\n"}, []string{"a", "b", ""}},
+		{"no final line break", []string{"a\nb"}, []string{"a", "b"}},
+		{"split on line breaks", []string{"a\n", "b\n", "c"}, []string{"a", "b", "c"}},
+		{"line split between ranges", []string{"a\nbe", "fo", "re\nc\n"}, []string{"a", "before", "c", ""}},
+		{"range without line break", []string{"ab", "cd\n"}, []string{"abcd", ""}},
+		{"colours stripped", []string{"\x1b[31mred\x1b[0m\nplain\n"}, []string{"red", "plain", ""}},
+		{"progress bar", []string{"10%\r50%\r100%\n"}, []string{"100%", ""}},
+		{"colour split between ranges", []string{"\x1b[3", "2mgreen\x1b[0m\n"}, []string{"green", ""}},
+		{"empty log", []string{""}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newLogBuffer()
+			var next int64
+			for i, text := range tt.ranges {
+				next += int64(len(text))
+				buf.append(text, next, i == len(tt.ranges)-1)
+
+				// The raw log is rebuilt from the lines at any point
+				if want := strings.Join(tt.ranges[:i+1], ""); buf.text() != want {
+					t.Fatalf("after range %d text() = %q, want %q", i, buf.text(), want)
+				}
+			}
+
+			if !reflect.DeepEqual(buf.lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", buf.lines, tt.wantLines)
+			}
+			if buf.plain() != strings.Join(tt.wantLines, "\n") {
+				t.Errorf("plain() = %q", buf.plain())
+			}
+			if buf.size != next {
+				t.Errorf("size = %d, want %d", buf.size, next)
+			}
+		})
+	}
+}
+
+func TestSearchCarriesOverToOutline(t *testing.T) {
+	ranges := []string{
+		"Started\n[Pipeline] { (Build)\nmake all\n",
+		"[Pipeline] }\n[Pipeline] { (Test)\nFAIL: TestA\n",
+		"FAIL: TestB\n[Pipeline] }\nFinished: FAILURE\n",
+	}
+
+	b, _ := NewBuildLog().Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	b = b.WithJobAndBuild("deploy", 7)
+
+	var offset int64
+	for i, text := range ranges {
+		b = b.WithLogRange(offset, text, offset+int64(len(text)), i == len(ranges)-1)
+		offset += int64(len(text))
+		if i == 0 {
+			// Searched for while the log is still arriving
+			b.search.query = "FAIL"
+			b.findMatches()
+		}
+	}
+	if !b.outlineActive() {
+		t.Fatal("outline not shown")
+	}
+
+	// The Test stage is unfolded and every match points at the row of its
+	// line, as a search over the outline finds them
+	var got []logMatch
+	got = append(got, b.search.matches...)
+	b.findMatches()
+	if !reflect.DeepEqual(got, b.search.matches) {
+		t.Errorf("carried over %+v, searching the outline finds %+v", got, b.search.matches)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d matches, want 3", len(got))
+	}
+	for _, m := range got {
+		if line := b.outline.lineFor(m.line); !strings.Contains(b.buf.lines[line], "FAIL") {
+			t.Errorf("match at row %d shows line %q", m.line, b.buf.lines[line])
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// Diff layouts accepted in the logDiff config section
+const (
+	DiffUnified    = "unified"
+	DiffSideBySide = "side-by-side"
+)
+
+// diffHunk is a run of changes with the unchanged lines around it
+type diffHunk struct {
+	ops      []utils.DiffOp
+	oldStart int
+	oldLen   int
+	newStart int
+	newLen   int
+}
+
+// LogDiffComponent shows the differences between the logs of two builds
+// after masking volatile content such as timestamps
+type LogDiffComponent struct {
+	jobName  string
+	oldBuild int
+	newBuild int
+	loaded   bool
+
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+	keys     KeyMap
+
+	normalizer *utils.LogNormalizer
+	context    int
+	sideBySide bool
+
+	// Plain-text lines of both logs and the hunks computed from them
+	oldLines []string
+	newLines []string
+	hunks    []diffHunk
+	added    int
+	removed  int
+
+	// hunkRows holds the viewport row of every hunk header
+	hunkRows   []int
+	current    int
+	jumpOffset int
+}
+
+// NewLogDiff creates a new log diff component
+func NewLogDiff() LogDiffComponent {
+	normalizer, _ := utils.NewLogNormalizer(config.LogDiff{})
+
+	return LogDiffComponent{
+		keys:       DefaultKeyMap(),
+		normalizer: normalizer,
+		context:    3,
+	}
+}
+
+// WithNormalizer sets the rules used to mask volatile log content
+func (d LogDiffComponent) WithNormalizer(n *utils.LogNormalizer) LogDiffComponent {
+	d.normalizer = n
+	return d
+}
+
+// WithLayout sets the initial layout and the number of unchanged lines
+// shown around each hunk
+func (d LogDiffComponent) WithLayout(layout string, context int) LogDiffComponent {
+	d.sideBySide = layout == DiffSideBySide
+	if context > 0 {
+		d.context = context
+	}
+	return d
+}
+
+// WithKeyMap sets the keybindings used to scroll and navigate hunks
+func (d LogDiffComponent) WithKeyMap(keys KeyMap) LogDiffComponent {
+	d.keys = keys
+	if d.ready {
+		applyViewportKeys(&d.viewport, keys)
+	}
+	return d
+}
+
+// WithBuilds sets the builds being compared and clears the previous diff
+// until their logs arrive
+func (d LogDiffComponent) WithBuilds(jobName string, oldBuild, newBuild int) LogDiffComponent {
+	d.jobName = jobName
+	d.oldBuild = oldBuild
+	d.newBuild = newBuild
+	d.loaded = false
+	d.oldLines, d.newLines, d.hunks = nil, nil, nil
+	d.refreshContent()
+	return d
+}
+
+// Builds returns the builds compared
+func (d LogDiffComponent) Builds() (int, int) {
+	return d.oldBuild, d.newBuild
+}
+
+// LogDiff is the comparison of the logs of two builds, made by CompareLogs
+type LogDiff struct {
+	oldLines []string
+	newLines []string
+	ops      []utils.DiffOp
+}
+
+// CompareLogs diffs the console output of the old and new build. Long logs
+// take a while to compare, so it is meant to run in a command rather than
+// in Update.
+func (d LogDiffComponent) CompareLogs(oldLog, newLog string) LogDiff {
+	diff := LogDiff{oldLines: plainLines(oldLog), newLines: plainLines(newLog)}
+
+	normalize := func(lines []string) []string {
+		out := make([]string, len(lines))
+		for i, line := range lines {
+			out[i] = d.normalizer.Normalize(line)
+		}
+		return out
+	}
+	diff.ops = utils.DiffLines(normalize(diff.oldLines), normalize(diff.newLines))
+	return diff
+}
+
+// WithDiff shows a comparison made by CompareLogs
+func (d LogDiffComponent) WithDiff(diff LogDiff) LogDiffComponent {
+	d.oldLines = diff.oldLines
+	d.newLines = diff.newLines
+
+	d.added, d.removed = 0, 0
+	for _, op := range diff.ops {
+		switch op.Kind {
+		case utils.DiffInsert:
+			d.added++
+		case utils.DiffDelete:
+			d.removed++
+		}
+	}
+
+	d.hunks = buildHunks(diff.ops, d.context)
+	d.loaded = true
+	d.current = 0
+	d.jumpOffset = -1
+	d.refreshContent()
+	if d.ready {
+		d.viewport.GotoTop()
+	}
+	return d
+}
+
+// RefreshStyles re-applies the current theme
+func (d LogDiffComponent) RefreshStyles() LogDiffComponent {
+	if d.ready {
+		d.viewport.Style = utils.LogStyle
+	}
+	d.refreshContent()
+	return d
+}
+
+// plainLines splits a log into lines without escape sequences; tabs are
+// expanded so that side-by-side columns line up
+func plainLines(log string) []string {
+	lines := strings.Split(strings.TrimSuffix(log, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(utils.StripANSI(line), "\t", "    ")
+	}
+	return lines
+}
+
+// buildHunks groups changed lines, merging changes separated by no more
+// than twice the context so that context lines are never shown twice
+func buildHunks(ops []utils.DiffOp, context int) []diffHunk {
+	var hunks []diffHunk
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == utils.DiffEqual {
+			i++
+			continue
+		}
+
+		start := max(0, i-context)
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != utils.DiffEqual {
+				end++
+				continue
+			}
+			// Count the unchanged run and stop if it is long enough to split
+			run := end
+			for run < len(ops) && ops[run].Kind == utils.DiffEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(len(ops), end+context)
+				break
+			}
+			end = run
+		}
+
+		hunk := diffHunk{ops: ops[start:end], oldStart: -1, newStart: -1}
+		for _, op := range hunk.ops {
+			if op.OldLine >= 0 {
+				if hunk.oldStart < 0 {
+					hunk.oldStart = op.OldLine
+				}
+				hunk.oldLen++
+			}
+			if op.NewLine >= 0 {
+				if hunk.newStart < 0 {
+					hunk.newStart = op.NewLine
+				}
+				hunk.newLen++
+			}
+		}
+		hunks = append(hunks, hunk)
+		i = end
+	}
+
+	return hunks
+}
+
+// header renders the hunk header in unified diff notation
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.oldStart+1, h.oldLen, h.newStart+1, h.newLen)
+}
+
+// refreshContent renders the hunks in the current layout
+func (d *LogDiffComponent) refreshContent() {
+	if !d.ready {
+		return
+	}
+
+	var rows []string
+	d.hunkRows = d.hunkRows[:0]
+
+	switch {
+	case !d.loaded:
+		rows = []string{"Loading logs..."}
+	case len(d.hunks) == 0:
+		rows = []string{utils.SuccessText.Render("No differences after normalisation")}
+	default:
+		gutter := len(fmt.Sprint(max(len(d.oldLines), len(d.newLines))))
+		for _, hunk := range d.hunks {
+			d.hunkRows = append(d.hunkRows, len(rows))
+			rows = append(rows, lipgloss.NewStyle().Foreground(utils.ColorAccent).Render(hunk.header()))
+			if d.sideBySide {
+				rows = append(rows, d.renderSideBySide(hunk, gutter)...)
+			} else {
+				rows = append(rows, d.renderUnified(hunk, gutter)...)
+			}
+		}
+	}
+
+	d.viewport.SetContent(strings.Join(rows, "\n"))
+}
+
+// renderUnified renders a hunk as old and new line numbers followed by the
+// line marked with " ", "-" or "+"
+func (d LogDiffComponent) renderUnified(hunk diffHunk, gutter int) []string {
+	removedStyle, addedStyle := diffLineStyles()
+	rows := make([]string, 0, len(hunk.ops))
+	for _, op := range hunk.ops {
+		oldNum, newNum := lineNumber(op.OldLine, gutter), lineNumber(op.NewLine, gutter)
+		prefix := utils.MutedText.Render(oldNum + " " + newNum + " │")
+
+		switch op.Kind {
+		case utils.DiffDelete:
+			rows = append(rows, prefix+removedStyle.Render("-"+d.oldLines[op.OldLine]))
+		case utils.DiffInsert:
+			rows = append(rows, prefix+addedStyle.Render("+"+d.newLines[op.NewLine]))
+		default:
+			rows = append(rows, prefix+" "+d.newLines[op.NewLine])
+		}
+	}
+	return rows
+}
+
+// renderSideBySide renders a hunk as two columns, pairing removed lines
+// with the added lines that replace them
+func (d LogDiffComponent) renderSideBySide(hunk diffHunk, gutter int) []string {
+	width := d.viewport.Width - d.viewport.Style.GetHorizontalFrameSize()
+	column := max(10, (width-3)/2-gutter-1)
+
+	cell := func(num int, text string, style lipgloss.Style) string {
+		if num < 0 {
+			return strings.Repeat(" ", gutter+1+column)
+		}
+		text = runewidth.FillRight(runewidth.Truncate(text, column, "…"), column)
+		return utils.MutedText.Render(lineNumber(num, gutter)+" ") + style.Render(text)
+	}
+	plain := lipgloss.NewStyle()
+	removedStyle, addedStyle := diffLineStyles()
+	separator := utils.MutedText.Render(" │ ")
+
+	var rows []string
+	for i := 0; i < len(hunk.ops); {
+		op := hunk.ops[i]
+		if op.Kind == utils.DiffEqual {
+			rows = append(rows, cell(op.OldLine, d.oldLines[op.OldLine], plain)+separator+
+				cell(op.NewLine, d.newLines[op.NewLine], plain))
+			i++
+			continue
+		}
+
+		// Collect a block of deletions and insertions and pair them up
+		var deleted, inserted []int
+		for ; i < len(hunk.ops) && hunk.ops[i].Kind != utils.DiffEqual; i++ {
+			if hunk.ops[i].Kind == utils.DiffDelete {
+				deleted = append(deleted, hunk.ops[i].OldLine)
+			} else {
+				inserted = append(inserted, hunk.ops[i].NewLine)
+			}
+		}
+		for k := 0; k < max(len(deleted), len(inserted)); k++ {
+			left, right := cell(-1, "", plain), cell(-1, "", plain)
+			if k < len(deleted) {
+				left = cell(deleted[k], d.oldLines[deleted[k]], removedStyle)
+			}
+			if k < len(inserted) {
+				right = cell(inserted[k], d.newLines[inserted[k]], addedStyle)
+			}
+			rows = append(rows, left+separator+right)
+		}
+	}
+	return rows
+}
+
+// diffLineStyles returns the styles of removed and added lines
+func diffLineStyles() (lipgloss.Style, lipgloss.Style) {
+	return lipgloss.NewStyle().Foreground(utils.ColorSecondary),
+		lipgloss.NewStyle().Foreground(utils.ColorSuccess)
+}
+
+// lineNumber renders a 0-based line index as a padded 1-based number, or
+// blanks for a line that is absent on one side
+func lineNumber(line, width int) string {
+	if line < 0 {
+		return strings.Repeat(" ", width)
+	}
+	return fmt.Sprintf("%*d", width, line+1)
+}
+
+// jumpToHunk scrolls to the next (delta 1) or previous (delta -1) hunk,
+// wrapping around at either end
+func (d *LogDiffComponent) jumpToHunk(delta int) {
+	if len(d.hunkRows) == 0 {
+		return
+	}
+
+	offset := d.viewport.YOffset
+	next := -1
+	switch {
+	case offset == d.jumpOffset:
+		// Still where the last jump left us, which may be short of the hunk
+		// when it is near the end of the diff
+		next = (d.current + delta + len(d.hunkRows)) % len(d.hunkRows)
+	case delta > 0:
+		next = 0
+		for i, row := range d.hunkRows {
+			if row > offset {
+				next = i
+				break
+			}
+		}
+	default:
+		next = len(d.hunkRows) - 1
+		for i, row := range d.hunkRows {
+			if row < offset {
+				next = i
+			}
+		}
+	}
+
+	d.current = next
+	d.viewport.SetYOffset(d.hunkRows[next])
+	d.jumpOffset = d.viewport.YOffset
+}
+
+// Init initializes the log diff component
+func (d LogDiffComponent) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (d LogDiffComponent) Update(msg tea.Msg) (LogDiffComponent, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+
+		// The title is above the diff, a blank line and the footer below
+		height := max(msg.Height-strings.Count(d.header(), "\n")-2, 3)
+		if !d.ready {
+			d.viewport = viewport.New(msg.Width-4, height)
+			d.viewport.Style = utils.LogStyle
+			applyViewportKeys(&d.viewport, d.keys)
+			d.ready = true
+		} else {
+			d.viewport.Width = msg.Width - 4
+			d.viewport.Height = height
+		}
+		d.refreshContent()
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, d.keys.NextHunk):
+			d.jumpToHunk(1)
+			return d, nil
+
+		case key.Matches(msg, d.keys.PrevHunk):
+			d.jumpToHunk(-1)
+			return d, nil
+
+		case key.Matches(msg, d.keys.DiffLayout):
+			d.sideBySide = !d.sideBySide
+			d.refreshContent()
+			if len(d.hunkRows) > 0 {
+				d.viewport.SetYOffset(d.hunkRows[d.current])
+				d.jumpOffset = d.viewport.YOffset
+			}
+			return d, nil
+		}
+	}
+
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+// header renders the title above the diff
+func (d LogDiffComponent) header() string {
+	return utils.TitleStyle.Render(fmt.Sprintf("Log Diff: %s #%d → #%d", d.jobName, d.oldBuild, d.newBuild)) + "\n\n"
+}
+
+// View renders the log diff component
+func (d LogDiffComponent) View() string {
+	if !d.ready {
+		return "Loading..."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(d.header())
+	sb.WriteString(d.viewport.View())
+	sb.WriteString("\n\n")
+
+	layout := DiffUnified
+	if d.sideBySide {
+		layout = DiffSideBySide
+	}
+
+	var status string
+	if d.loaded && len(d.hunks) > 0 {
+		status = fmt.Sprintf("%s | %s %s | ",
+			utils.WarningText.Render(fmt.Sprintf("hunk %d/%d", d.current+1, len(d.hunks))),
+			utils.FailureText.Render(fmt.Sprintf("-%d", d.removed)),
+			utils.SuccessText.Render(fmt.Sprintf("+%d", d.added)))
+	}
+
+	footerHelp := fmt.Sprintf(
+		"%s%s next/prev hunk | %s %s layout | %s scroll | %s back",
+		status,
+		utils.MutedText.Render(d.keys.NextHunk.Help().Key+" "+d.keys.PrevHunk.Help().Key),
+		utils.MutedText.Render(d.keys.DiffLayout.Help().Key),
+		layout,
+		utils.MutedText.Render(d.keys.Up.Help().Key+" "+d.keys.Down.Help().Key),
+		utils.MutedText.Render(d.keys.Back.Help().Key),
+	)
+
+	footer := lipgloss.NewStyle().
+		Foreground(utils.ColorLightGray).
+		Render(footerHelp)
+
+	sb.WriteString(footer)
+
+	return sb.String()
+}
@@ -0,0 +1,194 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// exportFormat is the file format used when saving a build log
+type exportFormat int
+
+const (
+	exportRaw   exportFormat = iota // As received, including ANSI colours
+	exportPlain                     // ANSI sequences stripped
+	exportHTML                      // Colours preserved as HTML
+)
+
+// exportFormats lists the formats in the order tab cycles through them
+var exportFormats = []exportFormat{exportRaw, exportPlain, exportHTML}
+
+// String returns the name of the format
+func (f exportFormat) String() string {
+	switch f {
+	case exportPlain:
+		return "plain"
+	case exportHTML:
+		return "html"
+	default:
+		return "raw"
+	}
+}
+
+// extension returns the file extension of the format
+func (f exportFormat) extension() string {
+	if f == exportHTML {
+		return ".html"
+	}
+	return ".log"
+}
+
+// LogExportMsg reports the outcome of saving or opening a build log
+type LogExportMsg struct {
+	Action string // "saved", "pager" or "editor"
+	Path   string
+	Err    error
+}
+
+// exportContent renders the log in the given format
+func (b BuildLogComponent) exportContent(format exportFormat) string {
+	switch format {
+	case exportPlain:
+		return b.buf.plain()
+	case exportHTML:
+		return utils.ANSIToHTML(b.colorizeLines(), fmt.Sprintf("%s #%d", b.jobName, b.buildNum))
+	default:
+		return b.buf.text()
+	}
+}
+
+// defaultExportName returns the suggested file name for saving the log
+func (b BuildLogComponent) defaultExportName(format exportFormat) string {
+	name := strings.NewReplacer("/", "-", " ", "_").Replace(b.jobName)
+	if name == "" {
+		name = "build"
+	}
+	return fmt.Sprintf("%s-%d%s", name, b.buildNum, format.extension())
+}
+
+// exportExists reports whether saving the log to path would replace a file
+func exportExists(path string) bool {
+	path, err := utils.ExpandPath(path)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// confirmingOverwrite reports whether the save prompt holds an existing file
+// enter was pressed on once
+func (b BuildLogComponent) confirmingOverwrite() bool {
+	return b.overwrite != "" && b.prompt.Value() == b.overwrite
+}
+
+// saveLog writes the log to path in the given format. An existing file is
+// only replaced when overwrite is set.
+func (b BuildLogComponent) saveLog(path string, format exportFormat, overwrite bool) tea.Cmd {
+	content := b.exportContent(format)
+
+	return func() tea.Msg {
+		path, err := utils.ExpandPath(path)
+		if err != nil {
+			return LogExportMsg{Action: "saved", Err: err}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return LogExportMsg{Action: "saved", Path: path, Err: fmt.Errorf("failed to create directory: %v", err)}
+		}
+		if err := writeExport(path, content, overwrite); err != nil {
+			return LogExportMsg{Action: "saved", Path: path, Err: fmt.Errorf("failed to write log: %v", err)}
+		}
+		return LogExportMsg{Action: "saved", Path: path}
+	}
+}
+
+// writeExport writes content to path, failing if the file exists unless
+// overwrite is set
+func writeExport(path, content string, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// openPager opens the raw log, colours included, in $PAGER; like the editor,
+// the pager reads it from a temporary file
+func (b BuildLogComponent) openPager() tea.Cmd {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	return b.runExternal("pager", pager, b.buf.text(), nil)
+}
+
+// openEditor opens the plain log in $VISUAL or $EDITOR at the current match,
+// or at the top visible line without one
+func (b BuildLogComponent) openEditor() tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	line := fmt.Sprintf("+%d", b.editorLine()+1)
+	return b.runExternal("editor", editor, b.buf.plain(), []string{line})
+}
+
+// editorLine returns the log line the editor opens at: the current match
+// when there is a search, else the top visible line
+func (b BuildLogComponent) editorLine() int {
+	if b.search.active() && len(b.search.matches) > 0 {
+		return b.lineAt(b.search.matches[b.search.current].line)
+	}
+	return b.topLine()
+}
+
+// runExternal writes content to a temporary file and hands the terminal to
+// command until it exits, then removes the file and returns to the TUI
+func (b BuildLogComponent) runExternal(action, command, content string, args []string) tea.Cmd {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return func() tea.Msg {
+			return LogExportMsg{Action: action, Err: fmt.Errorf("no %s configured", action)}
+		}
+	}
+
+	file, err := os.CreateTemp("", "jenkins-tui-*.log")
+	if err == nil {
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return func() tea.Msg {
+			return LogExportMsg{Action: action, Err: fmt.Errorf("failed to write temporary file: %v", err)}
+		}
+	}
+
+	args = append(append(fields[1:], args...), file.Name())
+	cmd := exec.Command(fields[0], args...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		os.Remove(file.Name())
+		if err != nil {
+			err = fmt.Errorf("%s exited: %v", fields[0], err)
+		}
+		return LogExportMsg{Action: action, Err: err}
+	})
+}
@@ -0,0 +1,92 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSaveLogOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.log")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := NewBuildLog().Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	b = b.WithJobAndBuild("deploy", 7).WithLog("new\n")
+
+	save := func(b BuildLogComponent, path string) (BuildLogComponent, tea.Cmd) {
+		b, _ = b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		b.prompt.SetValue(path)
+		return b.updateSavePrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	// A new file is saved at once
+	fresh := filepath.Join(dir, "fresh.log")
+	if _, cmd := save(b, fresh); cmd == nil {
+		t.Fatal("no save for a new file")
+	} else if msg := cmd().(LogExportMsg); msg.Err != nil {
+		t.Fatalf("saving a new file: %v", msg.Err)
+	}
+
+	// An existing file asks first, and is replaced on the second enter
+	b, cmd := save(b, existing)
+	if cmd != nil || b.promptMode != promptSave {
+		t.Fatal("existing file saved without asking")
+	}
+	b, cmd = b.updateSavePrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("no save once confirmed")
+	}
+	if msg := cmd().(LogExportMsg); msg.Err != nil {
+		t.Fatalf("overwriting: %v", msg.Err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "new\n" {
+		t.Errorf("file holds %q after overwriting", data)
+	}
+
+	// Without the confirmation the file is left alone
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg := b.saveLog(existing, exportRaw, false)().(LogExportMsg); msg.Err == nil {
+		t.Error("existing file replaced without overwrite")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("file holds %q, want it untouched", data)
+	}
+}
+
+func TestEditorLine(t *testing.T) {
+	log := "one\ntwo\nthree\nfour\nfive\n"
+
+	tests := []struct {
+		name  string
+		query string
+		moves int
+		want  int
+	}{
+		{"no search", "", 0, 0},
+		{"first match", "f", 0, 3},
+		{"next match", "f", 1, 4},
+		{"no match", "six", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := NewBuildLog().Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			b = b.WithJobAndBuild("deploy", 7).WithLog(log)
+			b.search.query = tt.query
+			b.findMatches()
+			for i := 0; i < tt.moves; i++ {
+				b.search.move(1)
+			}
+			if got := b.editorLine(); got != tt.want {
+				t.Errorf("editorLine() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
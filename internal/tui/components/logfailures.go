@@ -0,0 +1,85 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// maxPanelHits is the number of failure hits listed in the summary panel
+const maxPanelHits = 5
+
+// failureSummary holds the failure signatures found in a log and the one
+// the user last jumped to
+type failureSummary struct {
+	hits    []utils.FailureHit
+	current int // -1 until the first jump
+	hidden  bool
+}
+
+// move selects the next or previous hit, wrapping around at either end
+func (f *failureSummary) move(delta int) (utils.FailureHit, bool) {
+	if len(f.hits) == 0 {
+		return utils.FailureHit{}, false
+	}
+	if f.current < 0 && delta < 0 {
+		f.current = 0
+	}
+	f.current = (f.current + delta + len(f.hits)) % len(f.hits)
+	return f.hits[f.current], true
+}
+
+// isCurrent reports whether line is the first line of the selected hit
+func (f failureSummary) isCurrent(line int) bool {
+	return f.current >= 0 && f.current < len(f.hits) && f.hits[f.current].Line == line
+}
+
+// panel renders the summary shown above the log, listing the hits around
+// the selected one; it is empty when there is nothing to show
+func (f failureSummary) panel(width int, keys KeyMap) string {
+	if len(f.hits) == 0 || f.hidden {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(utils.FailureText.Render("Failure summary: " + utils.SummarizeFailures(f.hits)))
+	sb.WriteString(utils.MutedText.Render(fmt.Sprintf("  (%s/%s jump, %s hide)",
+		keys.NextFailure.Help().Key, keys.PrevFailure.Help().Key, keys.ToggleFailures.Help().Key)))
+
+	// Keep the selected hit in the listed window
+	start := 0
+	if f.current >= maxPanelHits {
+		start = f.current - maxPanelHits + 1
+	}
+	end := min(len(f.hits), start+maxPanelHits)
+
+	numWidth := len(fmt.Sprint(f.hits[len(f.hits)-1].Line + 1))
+	for i := start; i < end; i++ {
+		hit := f.hits[i]
+		marker := "  "
+		if i == f.current {
+			marker = "▶ "
+		}
+
+		label := hit.Signature
+		if hit.Count > 1 {
+			label = fmt.Sprintf("%s (%d lines)", label, hit.Count)
+		}
+		prefix := fmt.Sprintf("%s%*d  %s: ", marker, numWidth, hit.Line+1, label)
+		text := runewidth.Truncate(hit.Text, max(10, width-runewidth.StringWidth(prefix)), "…")
+
+		sb.WriteString("\n")
+		if i == f.current {
+			sb.WriteString(utils.WarningText.Render(prefix) + text)
+		} else {
+			sb.WriteString(utils.MutedText.Render(prefix) + text)
+		}
+	}
+	if len(f.hits) > end {
+		sb.WriteString("\n" + utils.MutedText.Render(fmt.Sprintf("  … %d more", len(f.hits)-end)))
+	}
+
+	return sb.String()
+}
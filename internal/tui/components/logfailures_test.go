@@ -0,0 +1,65 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+func TestFailureSummaryOnlyForFailedBuilds(t *testing.T) {
+	analyzer, err := utils.NewFailureAnalyzer(config.FailureAnalysis{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const log = "[INFO] Building\nERROR: script returned exit code 1\nFinished: FAILURE\n"
+
+	tests := []struct {
+		name     string
+		before   string // Status told before the log arrives
+		after    string // Status told after it has arrived
+		wantHits int
+	}{
+		{"failed", string(api.StatusFailed), "", 1},
+		{"succeeded", string(api.StatusSuccess), "", 0},
+		{"running", string(api.StatusRunning), "", 0},
+		{"unknown", "", "", 0},
+		{"failed once finished", string(api.StatusRunning), string(api.StatusFailed), 1},
+		{"status corrected", string(api.StatusFailed), string(api.StatusSuccess), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := NewBuildLog().WithFailureAnalyzer(analyzer).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			b = b.WithBuildStatus("deploy", 7, tt.before).
+				WithJobAndBuild("deploy", 7).
+				WithLog(log)
+			if tt.after != "" {
+				b = b.WithBuildStatus("deploy", 7, tt.after)
+			}
+
+			if got := len(b.failures.hits); got != tt.wantHits {
+				t.Errorf("got %d hits, want %d", got, tt.wantHits)
+			}
+			if panel := b.failures.panel(100, b.keys); (panel != "") != (tt.wantHits > 0) {
+				t.Errorf("panel shown %v, want %v", panel != "", tt.wantHits > 0)
+			}
+		})
+	}
+}
+
+func TestFailureStatusOfOtherBuild(t *testing.T) {
+	analyzer, err := utils.NewFailureAnalyzer(config.FailureAnalysis{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := NewBuildLog().WithFailureAnalyzer(analyzer).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	b = b.WithJobAndBuild("deploy", 7).WithLog("ERROR: script returned exit code 1\n")
+	b = b.WithBuildStatus("deploy", 8, string(api.StatusFailed))
+	if len(b.failures.hits) != 0 {
+		t.Errorf("the status of build 8 analysed the log of build 7")
+	}
+}
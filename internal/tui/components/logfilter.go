@@ -0,0 +1,123 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// gapRow marks a break between non-adjacent groups of filtered lines
+const gapRow = -1
+
+// logFilter reduces a log to the lines matching (or, inverted, not matching)
+// a pattern, like grep with -B/-A context
+type logFilter struct {
+	logPattern
+	invert  bool
+	before  int
+	after   int
+	enabled bool
+	err     error
+}
+
+// filteredLog is the result of applying a logFilter
+type filteredLog struct {
+	rows    []int        // original line index per displayed row, or gapRow
+	matched map[int]bool // original lines that matched, as opposed to context
+	last    int          // last line shown, -1 before the first
+	until   int          // last line of context owed to the latest match
+}
+
+// apply selects the lines to show from the full log
+func (f *logFilter) apply(lines []string) filteredLog {
+	result := filteredLog{matched: map[int]bool{}, last: -1, until: -1}
+	f.err = nil
+	f.extend(&result, lines, 0)
+	return result
+}
+
+// extend adds the lines to show from lines[from:], the lines that arrived
+// since the filter was applied
+func (f *logFilter) extend(result *filteredLog, lines []string, from int) {
+	re, err := f.compile()
+	if err != nil {
+		f.err = err
+		return
+	}
+
+	show := func(line int) {
+		if result.last >= 0 && line > result.last+1 {
+			result.rows = append(result.rows, gapRow)
+		}
+		result.rows = append(result.rows, line)
+		result.last = line
+	}
+
+	for i := from; i < len(lines); i++ {
+		if re.MatchString(lines[i]) == f.invert {
+			if i <= result.until {
+				show(i)
+			}
+			continue
+		}
+
+		// A match brings in the context before it that is not shown yet
+		result.matched[i] = true
+		for j := max(result.last+1, i-f.before); j <= i; j++ {
+			show(j)
+		}
+		result.until = i + f.after
+	}
+}
+
+// status describes the filter for the footer
+func (f logFilter) status(shown int) string {
+	switch {
+	case f.err != nil:
+		return fmt.Sprintf("invalid filter: %v", f.err)
+	case !f.enabled:
+		return ""
+	}
+
+	verb := "matching"
+	if f.invert {
+		verb = "not matching"
+	}
+	return fmt.Sprintf("filter: %d lines %s %q (context -%d/+%d)", shown, verb, f.query, f.before, f.after)
+}
+
+// gutter renders the original line number of a row, grep style: ":" marks a
+// matching line and "-" a context line
+func (r filteredLog) gutter(row, width int) string {
+	line := r.rows[row]
+	if line == gapRow {
+		return utils.MutedText.Render(strings.Repeat(" ", width) + " ⋮")
+	}
+
+	sep := "-"
+	if r.matched[line] {
+		sep = ":"
+	}
+	return utils.MutedText.Render(fmt.Sprintf("%*d%s ", width, line+1, sep))
+}
+
+// rowFor returns the first row showing line or a later line
+func (r filteredLog) rowFor(line int) int {
+	for row, l := range r.rows {
+		if l >= line {
+			return row
+		}
+	}
+	return max(0, len(r.rows)-1)
+}
+
+// lineFor returns the original line shown at row, looking past gaps
+func (r filteredLog) lineFor(row int) int {
+	for ; row < len(r.rows); row++ {
+		if r.rows[row] != gapRow {
+			return r.rows[row]
+		}
+	}
+	return 0
+}
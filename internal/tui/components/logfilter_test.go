@@ -0,0 +1,101 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogFilter(t *testing.T) {
+	lines := []string{
+		"start",    // 0
+		"ERROR a",  // 1
+		"one",      // 2
+		"two",      // 3
+		"three",    // 4
+		"four",     // 5
+		"ERROR b",  // 6
+		"ERROR c",  // 7
+		"five",     // 8
+		"finished", // 9
+	}
+
+	tests := []struct {
+		name        string
+		filter      logFilter
+		wantRows    []int
+		wantMatched []int
+	}{
+		{"matches only", logFilter{logPattern: logPattern{query: "error"}}, []int{1, gapRow, 6, 7}, []int{1, 6, 7}},
+		{"context after", logFilter{logPattern: logPattern{query: "error"}, after: 1}, []int{1, 2, gapRow, 6, 7, 8}, []int{1, 6, 7}},
+		{"context before", logFilter{logPattern: logPattern{query: "error"}, before: 1}, []int{0, 1, gapRow, 5, 6, 7}, []int{1, 6, 7}},
+		{"context joins groups", logFilter{logPattern: logPattern{query: "error"}, before: 2, after: 2}, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, []int{1, 6, 7}},
+		{"inverted", logFilter{logPattern: logPattern{query: "error|e$", regex: true}, invert: true}, []int{0, gapRow, 3, gapRow, 5, gapRow, 9}, []int{0, 3, 5, 9}},
+		{"case sensitive", logFilter{logPattern: logPattern{query: "error", caseSensitive: true}}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.filter
+			got := f.apply(lines)
+			if f.err != nil {
+				t.Fatal(f.err)
+			}
+			if !reflect.DeepEqual(got.rows, tt.wantRows) {
+				t.Errorf("rows %v, want %v", got.rows, tt.wantRows)
+			}
+			for _, line := range tt.wantMatched {
+				if !got.matched[line] {
+					t.Errorf("line %d not marked as matching", line)
+				}
+			}
+			if len(got.matched) != len(tt.wantMatched) {
+				t.Errorf("%d lines marked as matching, want %d", len(got.matched), len(tt.wantMatched))
+			}
+
+			// Filtering the lines as they arrive shows the same rows
+			for _, split := range []int{1, 2, 7} {
+				inc := f.apply(lines[:split])
+				f.extend(&inc, lines, split)
+				if !reflect.DeepEqual(inc.rows, got.rows) {
+					t.Errorf("split at %d: rows %v, want %v", split, inc.rows, got.rows)
+				}
+			}
+		})
+	}
+}
+
+func TestLogFilterInvalid(t *testing.T) {
+	f := logFilter{logPattern: logPattern{query: "(", regex: true}}
+	f.apply([]string{"a"})
+	if f.err == nil {
+		t.Fatal("no error for an invalid pattern")
+	}
+	if status := f.status(0); status == "" {
+		t.Error("error not shown in the status")
+	}
+}
+
+func TestFilteredLogRows(t *testing.T) {
+	r := filteredLog{rows: []int{1, 2, gapRow, 6, 7}}
+
+	tests := []struct {
+		line, row int
+	}{
+		{0, 0},
+		{2, 1},
+		{3, 3},
+		{7, 4},
+		{9, 4},
+	}
+	for _, tt := range tests {
+		if got := r.rowFor(tt.line); got != tt.row {
+			t.Errorf("rowFor(%d) = %d, want %d", tt.line, got, tt.row)
+		}
+	}
+
+	for row, want := range []int{1, 2, 6, 6, 7} {
+		if got := r.lineFor(row); got != want {
+			t.Errorf("lineFor(%d) = %d, want %d", row, got, want)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+var (
+	// pipelineOpenRe matches "[Pipeline] {" and "[Pipeline] { (Build)", with
+	// an optional parallel branch prefix after "[Pipeline]"
+	pipelineOpenRe = regexp.MustCompile(`^\[Pipeline\] (?:\[([^\]]+)\] )?\{(?: \((.*)\))?\s*$`)
+
+	// pipelineCloseRe matches the end of a block, "[Pipeline] }"
+	pipelineCloseRe = regexp.MustCompile(`^\[Pipeline\] (?:\[([^\]]+)\] )?\}\s*$`)
+
+	// pipelineStepRe matches any other step marker such as "[Pipeline] sh"
+	pipelineStepRe = regexp.MustCompile(`^\[Pipeline\] \[([^\]]+)\] `)
+
+	stageSkippedRe = regexp.MustCompile(`^Stage "[^"]*" skipped due to `)
+	stageFailedRe  = regexp.MustCompile(`^ERROR: |script returned exit code [1-9]|^Failed in branch `)
+	logFinishedRe  = regexp.MustCompile(`^Finished: `)
+)
+
+// Section statuses
+const (
+	sectionSuccess    = "success"
+	sectionFailed     = "failed"
+	sectionSkipped    = "skipped"
+	sectionRunning    = "running"
+	sectionIncomplete = "incomplete" // More of it is still to load
+)
+
+// logSection is a pipeline stage or parallel branch
+type logSection struct {
+	name     string
+	branch   bool   // A parallel branch rather than a stage
+	branchOf string // Name of the enclosing parallel branch, if any
+	line     int    // Line of the opening "[Pipeline] { (name)" marker
+	depth    int
+	parent   *logSection
+	items    []outlineItem
+	closed   bool
+	failed   bool // A line inside the section, nested ones excluded, failed it
+	skipped  bool
+	status   string
+	partial  bool // Open where the lines loaded so far end
+	lines    int  // Lines inside the section, nested ones included
+	expanded bool
+}
+
+// outlineItem is a log line or a nested section inside a section
+type outlineItem struct {
+	line    int
+	section *logSection
+}
+
+// outlineRow is a displayed row: a section header or a log line
+type outlineRow struct {
+	line    int
+	section *logSection
+}
+
+// logOutline is the tree of stages and branches of a pipeline log, shown
+// folded with a header row per section
+type logOutline struct {
+	root     *logSection
+	sections []*logSection // In order of appearance
+	owner    []*logSection // Innermost section of every line, nil at top level
+	rows     []outlineRow
+	selected *logSection
+	enabled  bool
+
+	// Parser state carried between ranges of the log
+	stack    []outlineFrame
+	branches map[string]*logSection
+	finished bool
+}
+
+// outlineFrame is an open block; anonymous blocks such as "node {" have no
+// section but still need to be matched with their closing brace
+type outlineFrame struct {
+	section *logSection
+	branch  string
+}
+
+// newOutline creates an empty outline ready to be fed log lines
+func newOutline() logOutline {
+	return logOutline{
+		root:     &logSection{depth: -1, expanded: true},
+		enabled:  true,
+		branches: map[string]*logSection{},
+	}
+}
+
+// parseOutline builds the section tree of a whole pipeline log
+func parseOutline(lines []string) logOutline {
+	o := newOutline()
+	o.feed(lines, 0)
+	o.finish(true)
+	return o
+}
+
+// current returns the innermost open section, within branch if given.
+// Output of a branch whose block was already closed still goes to it,
+// since the closing braces of parallel branches are not labelled.
+func (o *logOutline) current(branch string) *logSection {
+	for i := len(o.stack) - 1; i >= 0; i-- {
+		if s := o.stack[i].section; s != nil && (branch == "" || s.branchOf == branch) {
+			return s
+		}
+	}
+	if s, ok := o.branches[branch]; ok {
+		return s
+	}
+	if branch != "" {
+		for i := len(o.stack) - 1; i >= 0; i-- {
+			if s := o.stack[i].section; s != nil {
+				return s
+			}
+		}
+	}
+	return o.root
+}
+
+// add puts a log line into a section and notes whether it fails or skips it
+func (o *logOutline) add(s *logSection, line int, text string) {
+	s.items = append(s.items, outlineItem{line: line})
+	if s != o.root {
+		o.owner[line] = s
+	}
+
+	// Cheap checks first, as this runs for every line of the log
+	_, text = splitBranchPrefix(text)
+	if !s.skipped && strings.HasPrefix(text, "Stage ") {
+		s.skipped = stageSkippedRe.MatchString(text)
+	}
+	if !s.failed && (strings.HasPrefix(text, "ERROR: ") || strings.HasPrefix(text, "Failed in branch ") ||
+		strings.Contains(text, "script returned exit code ")) {
+		s.failed = stageFailedRe.MatchString(text)
+	}
+}
+
+// feed parses lines[from:], the lines that arrived since the last call.
+// Output of parallel branches, prefixed with "[branch] ", is grouped under
+// its branch even when the branches interleave.
+func (o *logOutline) feed(lines []string, from int) {
+	o.owner = append(o.owner, make([]*logSection, len(lines)-len(o.owner))...)
+
+	for i := from; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.HasPrefix(line, "[Pipeline] ") {
+			// Plain output, possibly of a parallel branch
+			branch, _ := splitBranchPrefix(line)
+			o.add(o.current(branch), i, line)
+			o.finished = o.finished || logFinishedRe.MatchString(line)
+			continue
+		}
+
+		if m := pipelineOpenRe.FindStringSubmatch(line); m != nil {
+			parent := o.current(m[1])
+			if m[2] == "" {
+				o.add(parent, i, line)
+				o.stack = append(o.stack, outlineFrame{branch: parent.branchOf})
+				continue
+			}
+
+			name := strings.TrimPrefix(m[2], "Branch: ")
+			isBranch := name != m[2]
+			if isBranch {
+				// Branches of one parallel are siblings, not nested
+				for parent.branch && parent.parent != nil {
+					parent = parent.parent
+				}
+			}
+
+			s := &logSection{name: name, line: i, depth: parent.depth + 1, parent: parent, branchOf: parent.branchOf}
+			if isBranch {
+				s.branch, s.branchOf = true, name
+				o.branches[name] = s
+			}
+			parent.items = append(parent.items, outlineItem{line: i, section: s})
+			o.owner[i] = s
+			o.sections = append(o.sections, s)
+			o.stack = append(o.stack, outlineFrame{section: s, branch: s.branchOf})
+			continue
+		}
+
+		if m := pipelineCloseRe.FindStringSubmatch(line); m != nil {
+			// Close the innermost block of the branch, or of the log
+			at := len(o.stack) - 1
+			for m[1] != "" && at >= 0 && o.stack[at].branch != m[1] {
+				at--
+			}
+			if at < 0 {
+				o.add(o.current(""), i, line)
+				continue
+			}
+
+			frame := o.stack[at]
+			o.stack = append(o.stack[:at], o.stack[at+1:]...)
+			if frame.section != nil {
+				o.add(frame.section, i, line)
+				frame.section.closed = true
+			} else {
+				o.add(o.current(frame.branch), i, line)
+			}
+			continue
+		}
+
+		branch := ""
+		if m := pipelineStepRe.FindStringSubmatch(line); m != nil {
+			branch = m[1]
+		}
+		o.add(o.current(branch), i, line)
+	}
+}
+
+// splitBranchPrefix splits the "[branch-a] " prefix off parallel output
+func splitBranchPrefix(line string) (branch, rest string) {
+	end := strings.IndexByte(line, ']')
+	if !strings.HasPrefix(line, "[") || end < 2 || !strings.HasPrefix(line[end+1:], " ") {
+		return "", line
+	}
+	return line[1:end], line[end+2:]
+}
+
+// finish derives the status of every section from the lines parsed so far
+// and lists the rows to show; until the whole log is parsed, sections still
+// open are incomplete
+func (o *logOutline) finish(complete bool) {
+	o.root.finalize(o.finished, complete)
+	o.build()
+}
+
+// finalize counts the lines of a section and derives its status from the
+// lines inside it and from its nested sections
+func (s *logSection) finalize(finished, complete bool) {
+	failed, skipped := s.failed, s.skipped
+	s.lines = 0
+	s.partial = !s.closed && !complete
+
+	for _, item := range s.items {
+		if item.section != nil {
+			item.section.finalize(finished, complete)
+			s.lines += item.section.lines + 1
+			failed = failed || item.section.status == sectionFailed
+			continue
+		}
+		s.lines++
+	}
+
+	switch {
+	case failed:
+		s.status = sectionFailed
+	case skipped:
+		s.status = sectionSkipped
+	case s.partial:
+		s.status = sectionIncomplete
+	case !s.closed && !finished:
+		s.status = sectionRunning
+	default:
+		s.status = sectionSuccess
+	}
+}
+
+// active reports whether the log is shown as an outline
+func (o logOutline) active() bool {
+	return o.enabled && len(o.sections) > 0
+}
+
+// endsFolded reports whether the lines loaded so far end inside a folded
+// section, where the lines still to come would not show
+func (o logOutline) endsFolded() bool {
+	if len(o.rows) == 0 {
+		return false
+	}
+	s := o.rows[len(o.rows)-1].section
+	return s != nil && !s.expanded && s.partial
+}
+
+// build lists the rows of the expanded parts of the tree
+func (o *logOutline) build() {
+	o.rows = nil
+	if o.root != nil {
+		o.appendRows(o.root)
+	}
+}
+
+// appendRows adds the rows of a section's items, descending into expanded
+// sections
+func (o *logOutline) appendRows(s *logSection) {
+	for _, item := range s.items {
+		if item.section == nil {
+			o.rows = append(o.rows, outlineRow{line: item.line})
+			continue
+		}
+		o.rows = append(o.rows, outlineRow{line: item.section.line, section: item.section})
+		if item.section.expanded {
+			o.appendRows(item.section)
+		}
+	}
+}
+
+// reveal expands every section containing line; call build afterwards
+func (o *logOutline) reveal(line int) {
+	if line < 0 || line >= len(o.owner) {
+		return
+	}
+	s := o.owner[line]
+	if s != nil && s.line == line {
+		// A header is visible once its parent is expanded
+		s = s.parent
+	}
+	for ; s != nil && s != o.root; s = s.parent {
+		s.expanded = true
+	}
+}
+
+// rowFor returns the row showing line, or the header of the innermost
+// visible section containing it
+func (o logOutline) rowFor(line int) int {
+	for row, r := range o.rows {
+		if r.line == line {
+			return row
+		}
+	}
+	if line >= 0 && line < len(o.owner) {
+		for s := o.owner[line]; s != nil; s = s.parent {
+			if row := o.headerRow(s); row >= 0 {
+				return row
+			}
+		}
+	}
+	return 0
+}
+
+// lineFor returns the log line shown at row
+func (o logOutline) lineFor(row int) int {
+	if row >= 0 && row < len(o.rows) {
+		return o.rows[row].line
+	}
+	return 0
+}
+
+// headerRow returns the row of a section's header, or -1 if it is folded
+// away
+func (o logOutline) headerRow(s *logSection) int {
+	for row, r := range o.rows {
+		if r.section == s {
+			return row
+		}
+	}
+	return -1
+}
+
+// nextHeader returns the row of the header after (delta 1) or before
+// (delta -1) row, or -1 if there is none
+func (o logOutline) nextHeader(row, delta int) int {
+	for r := row + delta; r >= 0 && r < len(o.rows); r += delta {
+		if o.rows[r].section != nil {
+			return r
+		}
+	}
+	return -1
+}
+
+// header renders the row of a section: fold marker, name, status and size
+func (o logOutline) header(s *logSection) string {
+	marker := "▸"
+	if s.expanded {
+		marker = "▾"
+	}
+
+	name := s.name
+	if s.branch {
+		name = "⑂ " + name
+	}
+	if s == o.selected {
+		name = utils.CurrentMatchStyle.Render(name)
+	} else {
+		name = utils.BoldText.Render(name)
+	}
+
+	var status string
+	switch s.status {
+	case sectionFailed:
+		status = utils.StatusStyle("failed").Render("✘ failed")
+	case sectionSkipped:
+		status = utils.MutedText.Render("⊘ skipped")
+	case sectionRunning:
+		status = utils.StatusStyle("running").Render("● running")
+	case sectionIncomplete:
+		status = utils.MutedText.Render("◌ loading")
+	default:
+		status = utils.StatusStyle("success").Render("✔")
+	}
+
+	size := fmt.Sprintf("(%d lines)", s.lines)
+	if s.partial {
+		size = fmt.Sprintf("(%d+ lines)", s.lines)
+	}
+	return fmt.Sprintf("%s%s %s %s %s", strings.Repeat("  ", s.depth), marker, name, status,
+		utils.MutedText.Render(size))
+}
@@ -0,0 +1,224 @@
+package components
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var pipelineLog = []string{
+	"Started by user admin",                            // 0
+	"[Pipeline] Start of Pipeline",                     // 1
+	"[Pipeline] node",                                  // 2
+	"[Pipeline] {",                                     // 3
+	"[Pipeline] stage",                                 // 4
+	"[Pipeline] { (Build)",                             // 5
+	"[Pipeline] sh",                                    // 6
+	"+ make",                                           // 7
+	"[Pipeline] }",                                     // 8
+	"[Pipeline] // stage",                              // 9
+	"[Pipeline] stage",                                 // 10
+	"[Pipeline] { (Test)",                              // 11
+	"[Pipeline] parallel",                              // 12
+	"[Pipeline] { (Branch: unit)",                      // 13
+	"[Pipeline] { (Branch: lint)",                      // 14
+	"[unit] + go test ./...",                           // 15
+	"[lint] + golint ./...",                            // 16
+	"[unit] --- FAIL: TestParse",                       // 17
+	"[unit] ERROR: script returned exit code 1",        // 18
+	"[Pipeline] }",                                     // 19
+	"[Pipeline] }",                                     // 20
+	"[Pipeline] // parallel",                           // 21
+	"[Pipeline] }",                                     // 22
+	"[Pipeline] // stage",                              // 23
+	"[Pipeline] stage",                                 // 24
+	"[Pipeline] { (Deploy)",                            // 25
+	`Stage "Deploy" skipped due to earlier failure(s)`, // 26
+	"[Pipeline] }",                                     // 27
+	"[Pipeline] // stage",                              // 28
+	"[Pipeline] }",                                     // 29
+	"[Pipeline] // node",                               // 30
+	"[Pipeline] End of Pipeline",                       // 31
+	"Finished: FAILURE",                                // 32
+}
+
+// sectionSummary describes a section as "name depth status lines", with
+// a "branch" prefix for parallel branches
+func sectionSummary(s *logSection) string {
+	kind := ""
+	if s.branch {
+		kind = "branch "
+	}
+	return fmt.Sprintf("%s%s %d %s %d", kind, s.name, s.depth, s.status, s.lines)
+}
+
+func outlineSummary(o logOutline) []string {
+	var out []string
+	for _, s := range o.sections {
+		out = append(out, sectionSummary(s))
+	}
+	return out
+}
+
+func TestParseOutline(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int // Lines of the log that have arrived
+		want  []string
+	}{
+		{"whole log", len(pipelineLog), []string{
+			"Build 0 success 3",
+			"Test 0 failed 11",
+			"branch unit 1 failed 4",
+			"branch lint 1 success 2",
+			"Deploy 0 skipped 2",
+		}},
+		{"still running", 17, []string{
+			"Build 0 success 3",
+			"Test 0 running 5",
+			"branch unit 1 running 1",
+			"branch lint 1 running 1",
+		}},
+		{"no stages", 4, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parseOutline(pipelineLog[:tt.lines])
+			if got := outlineSummary(o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sections %q, want %q", got, tt.want)
+			}
+			if o.active() != (len(tt.want) > 0) {
+				t.Errorf("active %v", o.active())
+			}
+		})
+	}
+}
+
+func TestOutlineFedInRanges(t *testing.T) {
+	want := outlineSummary(parseOutline(pipelineLog))
+
+	for _, split := range [][]int{{1, 14, 19}, {5, 6, 16, 20, 26}} {
+		o := newOutline()
+		from := 0
+		for _, to := range append(split, len(pipelineLog)) {
+			o.feed(pipelineLog[:to], from)
+			from = to
+		}
+		o.finish(true)
+
+		if got := outlineSummary(o); !reflect.DeepEqual(got, want) {
+			t.Errorf("fed up to %v: sections %q, want %q", split, got, want)
+		}
+	}
+}
+
+func TestOutlineOfPartialLog(t *testing.T) {
+	o := newOutline()
+	o.feed(pipelineLog[:17], 0)
+	o.finish(false)
+
+	want := []string{
+		"Build 0 success 3",
+		"Test 0 incomplete 5",
+		"branch unit 1 incomplete 1",
+		"branch lint 1 incomplete 1",
+	}
+	if got := outlineSummary(o); !reflect.DeepEqual(got, want) {
+		t.Errorf("sections %q, want %q", got, want)
+	}
+	if !o.active() {
+		t.Error("outline of the lines loaded so far not shown")
+	}
+
+	// The lines still to come go to the folded Test stage
+	if !o.endsFolded() {
+		t.Error("log does not end in a folded stage")
+	}
+	o.reveal(16)
+	o.build()
+	if o.endsFolded() {
+		t.Error("log ends in a folded stage once the lint branch is unfolded")
+	}
+}
+
+func TestOutlineSearchAcrossRanges(t *testing.T) {
+	b, _ := NewBuildLog().Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	b = b.WithJobAndBuild("app", 7)
+
+	// Searched once the first range is there, the rest arrives folded
+	var offset int64
+	from := 0
+	for _, to := range []int{14, 17, len(pipelineLog)} {
+		text := strings.Join(pipelineLog[from:to], "\n") + "\n"
+		next := offset + int64(len(text))
+		b = b.WithLogRange(offset, text, next, to == len(pipelineLog))
+		if from == 0 {
+			b.search.query = "unit"
+			b.findMatches()
+		}
+		offset, from = next, to
+	}
+
+	if !b.outlineActive() {
+		t.Fatal("outline not shown")
+	}
+	var got []int
+	for _, m := range b.search.matches {
+		got = append(got, b.lineAt(m.line))
+	}
+	if want := []int{15, 17, 18}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matches on lines %v, want %v", got, want)
+	}
+}
+
+func TestOutlineRows(t *testing.T) {
+	o := parseOutline(pipelineLog)
+
+	// Folded, each stage is a single header row
+	var headers []string
+	for _, r := range o.rows {
+		if r.section != nil {
+			headers = append(headers, r.section.name)
+		}
+	}
+	if want := []string{"Build", "Test", "Deploy"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers %q, want %q", headers, want)
+	}
+	if got := o.lineFor(o.rowFor(7)); got != 5 {
+		t.Errorf("folded line 7 shown at line %d, want the Build header", got)
+	}
+
+	// Revealing a line unfolds the sections around it
+	o.reveal(17)
+	o.build()
+	if row := o.rowFor(17); o.lineFor(row) != 17 {
+		t.Errorf("line 17 not shown once revealed")
+	}
+	if row := o.rowFor(16); o.lineFor(row) != 14 {
+		t.Errorf("line 16 of the folded lint branch shown at line %d, want its header", o.lineFor(row))
+	}
+	if next := o.nextHeader(o.rowFor(17), 1); next < 0 || o.rows[next].section.name != "lint" {
+		t.Errorf("next header after line 17 is row %d", next)
+	}
+}
+
+func TestSplitBranchPrefix(t *testing.T) {
+	tests := []struct {
+		line, branch, rest string
+	}{
+		{"[unit] + go test", "unit", "+ go test"},
+		{"[INFO] Building", "INFO", "Building"},
+		{"[x]no space", "", "[x]no space"},
+		{"[] empty", "", "[] empty"},
+		{"plain", "", "plain"},
+	}
+	for _, tt := range tests {
+		if branch, rest := splitBranchPrefix(tt.line); branch != tt.branch || rest != tt.rest {
+			t.Errorf("splitBranchPrefix(%q) = %q, %q; want %q, %q", tt.line, branch, rest, tt.branch, tt.rest)
+		}
+	}
+}
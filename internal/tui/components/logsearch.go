@@ -0,0 +1,175 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// logMatch is a single search hit as byte offsets into a raw log line
+type logMatch struct {
+	line  int
+	start int
+	end   int
+}
+
+// logPattern is a user query interpreted as plain text or a regex
+type logPattern struct {
+	query         string
+	regex         bool
+	caseSensitive bool
+}
+
+// compile turns the query into a regular expression honouring the toggles
+func (p logPattern) compile() (*regexp.Regexp, error) {
+	pattern := p.query
+	if !p.regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !p.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// flags renders the regex and case toggles shown next to the prompt
+func (p logPattern) flags() string {
+	mode := "text"
+	if p.regex {
+		mode = "regex"
+	}
+	sensitivity := "ignore case"
+	if p.caseSensitive {
+		sensitivity = "match case"
+	}
+	return fmt.Sprintf("[%s] [%s]", mode, sensitivity)
+}
+
+// logSearch holds the state of a search inside a build log
+type logSearch struct {
+	logPattern
+	matches []logMatch
+	byLine  map[int][]int // line -> indexes into matches
+	current int
+	err     error
+}
+
+// run finds every match of the query in lines and resets the current match
+func (s *logSearch) run(lines []string) {
+	s.matches = nil
+	s.byLine = map[int][]int{}
+	s.current = 0
+	s.err = nil
+
+	s.extend(lines, 0)
+}
+
+// extend adds the matches in lines[from:], the lines that arrived since the
+// search last ran, keeping the current match
+func (s *logSearch) extend(lines []string, from int) {
+	if s.query == "" || s.err != nil {
+		return
+	}
+
+	re, err := s.compile()
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	for i := from; i < len(lines); i++ {
+		for _, loc := range re.FindAllStringIndex(lines[i], -1) {
+			// Skip empty matches such as "a*" against "b"
+			if loc[0] == loc[1] {
+				continue
+			}
+			s.byLine[i] = append(s.byLine[i], len(s.matches))
+			s.matches = append(s.matches, logMatch{line: i, start: loc[0], end: loc[1]})
+		}
+	}
+}
+
+// remap moves the matches from log lines to the rows showing them, given
+// the row of every line or -1 when it is not shown, and orders them by row
+func (s *logSearch) remap(rowOf []int) {
+	matches := make([]logMatch, 0, len(s.matches))
+	for _, m := range s.matches {
+		if row := rowOf[m.line]; row >= 0 {
+			m.line = row
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].line < matches[j].line
+	})
+
+	s.matches = matches
+	s.byLine = map[int][]int{}
+	for i, m := range matches {
+		s.byLine[m.line] = append(s.byLine[m.line], i)
+	}
+	s.current = 0
+}
+
+// active reports whether a committed query is being highlighted
+func (s logSearch) active() bool {
+	return s.query != ""
+}
+
+// move advances the current match by delta, wrapping around
+func (s *logSearch) move(delta int) (logMatch, bool) {
+	if len(s.matches) == 0 {
+		return logMatch{}, false
+	}
+	s.current = (s.current + delta + len(s.matches)) % len(s.matches)
+	return s.matches[s.current], true
+}
+
+// highlight renders a raw log line with its matches emphasised on top of
+// the line's normal colour
+func (s logSearch) highlight(lineNum int, line string) string {
+	base, _ := utils.LogLineStyle(line)
+
+	var sb strings.Builder
+	pos := 0
+	for _, idx := range s.byLine[lineNum] {
+		m := s.matches[idx]
+		sb.WriteString(renderSegment(base, line[pos:m.start]))
+
+		style := utils.MatchStyle
+		if idx == s.current {
+			style = utils.CurrentMatchStyle
+		}
+		sb.WriteString(style.Render(line[m.start:m.end]))
+		pos = m.end
+	}
+	sb.WriteString(renderSegment(base, line[pos:]))
+
+	return sb.String()
+}
+
+// status describes the search for the footer, e.g. "match 3/41"
+func (s logSearch) status() string {
+	switch {
+	case s.err != nil:
+		return fmt.Sprintf("invalid pattern: %v", s.err)
+	case !s.active():
+		return ""
+	case len(s.matches) == 0:
+		return fmt.Sprintf("no matches for %q", s.query)
+	default:
+		return fmt.Sprintf("match %d/%d", s.current+1, len(s.matches))
+	}
+}
+
+// renderSegment renders part of a line, skipping empty segments
+func renderSegment(style lipgloss.Style, segment string) string {
+	if segment == "" {
+		return ""
+	}
+	return style.Render(segment)
+}
@@ -0,0 +1,94 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogSearch(t *testing.T) {
+	lines := []string{
+		"[INFO] Building app",
+		"ERROR: build failed",
+		"error: missing file",
+		"Error Error",
+		"done",
+	}
+
+	tests := []struct {
+		name    string
+		pattern logPattern
+		want    []logMatch
+		wantErr bool
+	}{
+		{"ignore case", logPattern{query: "error"}, []logMatch{{1, 0, 5}, {2, 0, 5}, {3, 0, 5}, {3, 6, 11}}, false},
+		{"match case", logPattern{query: "Error", caseSensitive: true}, []logMatch{{3, 0, 5}, {3, 6, 11}}, false},
+		{"text quoted", logPattern{query: "[INFO]"}, []logMatch{{0, 0, 6}}, false},
+		{"regex", logPattern{query: `^\w+:`, regex: true}, []logMatch{{1, 0, 6}, {2, 0, 6}}, false},
+		{"empty matches skipped", logPattern{query: "x*", regex: true}, nil, false},
+		{"no match", logPattern{query: "warning"}, nil, false},
+		{"invalid regex", logPattern{query: "(", regex: true}, nil, true},
+		{"no query", logPattern{}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := logSearch{logPattern: tt.pattern}
+			s.run(lines)
+			if (s.err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", s.err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(s.matches, tt.want) {
+				t.Errorf("matches %v, want %v", s.matches, tt.want)
+			}
+
+			// Searching the lines as they arrive finds the same matches
+			inc := logSearch{logPattern: tt.pattern}
+			inc.run(lines[:2])
+			inc.extend(lines, 2)
+			if !reflect.DeepEqual(inc.matches, s.matches) || !reflect.DeepEqual(inc.byLine, s.byLine) {
+				t.Errorf("incremental matches %v, want %v", inc.matches, s.matches)
+			}
+		})
+	}
+}
+
+func TestLogSearchMove(t *testing.T) {
+	s := logSearch{logPattern: logPattern{query: "a"}}
+	s.run([]string{"a", "b", "aa"})
+
+	var got []int
+	for _, delta := range []int{1, 1, 1, -1, -1} {
+		m, ok := s.move(delta)
+		if !ok {
+			t.Fatal("no match to move to")
+		}
+		got = append(got, m.line*10+m.start)
+	}
+	if want := []int{20, 21, 0, 21, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("moved through %v, want %v", got, want)
+	}
+
+	if _, ok := (&logSearch{}).move(1); ok {
+		t.Error("moved without matches")
+	}
+}
+
+func TestLogSearchRemap(t *testing.T) {
+	s := logSearch{logPattern: logPattern{query: "x"}}
+	s.run([]string{"x", "x", "y", "x x"})
+	s.move(2)
+
+	// Line 1 is folded away and the others move down a row
+	s.remap([]int{1, -1, 2, 3})
+
+	want := []logMatch{{1, 0, 1}, {3, 0, 1}, {3, 2, 3}}
+	if !reflect.DeepEqual(s.matches, want) {
+		t.Errorf("matches %v, want %v", s.matches, want)
+	}
+	if !reflect.DeepEqual(s.byLine, map[int][]int{1: {0}, 3: {1, 2}}) {
+		t.Errorf("byLine %v", s.byLine)
+	}
+	if s.current != 0 {
+		t.Errorf("current %d, want 0", s.current)
+	}
+}
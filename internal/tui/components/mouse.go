@@ -0,0 +1,132 @@
+package components
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// doubleClickTime is the most time between the two clicks of a double click
+const doubleClickTime = 400 * time.Millisecond
+
+// urlPattern finds web links in rendered text
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// OpenMsg asks to open the selected job or build, as enter does. A double
+// click sends it.
+type OpenMsg struct{}
+
+// OpenURLMsg asks to open a link in the browser. A click on a link sends it.
+type OpenURLMsg struct {
+	URL string
+}
+
+// openSelected returns the command sending OpenMsg
+func openSelected() tea.Msg {
+	return OpenMsg{}
+}
+
+// clickTracker recognises double clicks
+type clickTracker struct {
+	at   time.Time
+	x, y int
+}
+
+// click records a click and reports whether it is the second click of a
+// double click on the same cell
+func (c clickTracker) click(msg tea.MouseMsg) (clickTracker, bool) {
+	now := time.Now()
+	double := now.Sub(c.at) < doubleClickTime && msg.X == c.x && msg.Y == c.y
+	if double {
+		// A third click starts over
+		return clickTracker{}, true
+	}
+	return clickTracker{at: now, x: msg.X, y: msg.Y}, false
+}
+
+// isClick reports whether msg is a press of the left button
+func isClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// wheelDelta returns -1 when the wheel turns up, 1 when it turns down and 0
+// for other mouse events
+func wheelDelta(msg tea.MouseMsg) int {
+	if msg.Action != tea.MouseActionPress {
+		return 0
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return -1
+	case tea.MouseButtonWheelDown:
+		return 1
+	}
+	return 0
+}
+
+// listItemAt returns the index among the visible items of the item shown at
+// row y of a list's view, or -1 if there is none. It follows the layout of
+// list.Model.View: title bar, status bar, then items of the delegate's height
+// separated by its spacing.
+func listItemAt(l list.Model, y int) int {
+	if l.ShowTitle() || (l.ShowFilter() && l.FilteringEnabled()) {
+		title := l.Styles.Title.Render(l.Title)
+		if l.FilterState() == list.Filtering {
+			title = l.FilterInput.View()
+		}
+		y -= lipgloss.Height(l.Styles.TitleBar.Render(title))
+	}
+	if l.ShowStatusBar() {
+		y -= lipgloss.Height(l.Styles.StatusBar.Render(" "))
+	}
+
+	delegate := newItemDelegate()
+	rowHeight := delegate.Height() + delegate.Spacing()
+	if y < 0 || y%rowHeight >= delegate.Height() {
+		return -1
+	}
+
+	start, end := l.Paginator.GetSliceBounds(len(l.VisibleItems()))
+	if i := start + y/rowHeight; i < end {
+		return i
+	}
+	return -1
+}
+
+// urlAt returns the link shown at column x of row y of a rendered view, or
+// "" if there is none
+func urlAt(view string, x, y int) string {
+	lines := strings.Split(view, "\n")
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+
+	line := utils.StripANSI(lines[y])
+	for _, loc := range urlPattern.FindAllStringIndex(line, -1) {
+		// The match is in bytes, the mouse in terminal cells
+		start := runewidth.StringWidth(line[:loc[0]])
+		end := start + runewidth.StringWidth(line[loc[0]:loc[1]])
+		if x >= start && x < end {
+			return strings.TrimRight(line[loc[0]:loc[1]], ".,;:!?")
+		}
+	}
+	return ""
+}
+
+// openURL returns the command sending OpenURLMsg for the link clicked in a
+// view, or nil if no link was clicked
+func openURL(view string, msg tea.MouseMsg) tea.Cmd {
+	link := urlAt(view, msg.X, msg.Y)
+	if link == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		return OpenURLMsg{URL: link}
+	}
+}
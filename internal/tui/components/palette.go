@@ -0,0 +1,253 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// Kinds of command palette entries
+const (
+	PaletteView   = "view"
+	PaletteAction = "action"
+	PaletteServer = "server"
+	PaletteJob    = "job"
+	PaletteBuild  = "build"
+)
+
+const (
+	// maxPaletteRows is how many matches the palette lists at once
+	maxPaletteRows = 12
+	// maxPaletteWidth is the widest the palette grows
+	maxPaletteWidth = 90
+)
+
+// PaletteEntry is a job, build, view or action the command palette can open
+// or run
+type PaletteEntry struct {
+	Kind     string
+	Title    string  // Matched against the query, e.g. "team/app#12"
+	Detail   string  // Shown after the title
+	Target   string  // Job, view, action or server the entry is about
+	Build    int     // Build number of build entries
+	Frecency float64 // How often and how recently the entry was chosen
+}
+
+// ID identifies the entry across runs, to remember how often it is chosen
+func (e PaletteEntry) ID() string {
+	return e.Kind + ":" + e.Title
+}
+
+// PaletteMsg reports the entry chosen in the command palette
+type PaletteMsg struct {
+	Entry PaletteEntry
+}
+
+// paletteMatch is an entry matching the query
+type paletteMatch struct {
+	entry     int
+	score     float64
+	positions []int // Matched runes of the title
+}
+
+// PaletteComponent is a pop-up that fuzzy-matches a query against jobs,
+// builds, views and actions, ranks them by the quality of the match and by
+// frecency, and reports the entry chosen. While it is open it takes every key
+// press.
+type PaletteComponent struct {
+	input   textinput.Model
+	entries []PaletteEntry
+	matches []paletteMatch
+	cursor  int
+	active  bool
+	width   int
+	height  int
+}
+
+// NewPalette creates a closed command palette
+func NewPalette() PaletteComponent {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "jobs, job#build, views and actions"
+
+	return PaletteComponent{input: input}
+}
+
+// Open shows the palette with an empty query over the given entries
+func (p PaletteComponent) Open(entries []PaletteEntry) PaletteComponent {
+	p.entries = entries
+	p.input.SetValue("")
+	p.input.Focus()
+	p.active = true
+	return p.match()
+}
+
+// Active reports whether the palette is open
+func (p PaletteComponent) Active() bool {
+	return p.active
+}
+
+// close hides the palette
+func (p PaletteComponent) close() PaletteComponent {
+	p.active = false
+	p.input.Blur()
+	p.entries = nil
+	p.matches = nil
+	return p
+}
+
+// match ranks the entries matching the query. Without a query the most
+// frecent entries come first, otherwise frecency only adds to the score of
+// the match.
+func (p PaletteComponent) match() PaletteComponent {
+	query := strings.TrimSpace(p.input.Value())
+
+	p.matches = p.matches[:0]
+	for i, entry := range p.entries {
+		score, positions, ok := utils.FuzzyMatch(query, entry.Title)
+		if !ok {
+			continue
+		}
+		rank := float64(score) + 4*math.Log1p(entry.Frecency)
+		if query == "" {
+			rank = entry.Frecency
+		}
+		p.matches = append(p.matches, paletteMatch{entry: i, score: rank, positions: positions})
+	}
+
+	sort.SliceStable(p.matches, func(i, j int) bool {
+		return p.matches[i].score > p.matches[j].score
+	})
+	p.cursor = 0
+	return p
+}
+
+// Update handles messages
+func (p PaletteComponent) Update(msg tea.Msg) (PaletteComponent, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		return p, nil
+
+	case tea.KeyMsg:
+		if !p.active {
+			return p, nil
+		}
+
+		switch msg.String() {
+		case "esc", "ctrl+p":
+			return p.close(), nil
+		case "enter":
+			if len(p.matches) == 0 {
+				return p, nil
+			}
+			entry := p.entries[p.matches[p.cursor].entry]
+			return p.close(), func() tea.Msg {
+				return PaletteMsg{Entry: entry}
+			}
+		case "up", "ctrl+k":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "down", "ctrl+j", "tab":
+			if p.cursor < len(p.matches)-1 {
+				p.cursor++
+			}
+			return p, nil
+		}
+
+		query := p.input.Value()
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		if p.input.Value() != query {
+			p = p.match()
+		}
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+// View renders the palette at the top of the screen
+func (p PaletteComponent) View() string {
+	width := min(max(p.width-8, 30), maxPaletteWidth)
+
+	var sb strings.Builder
+	sb.WriteString(p.input.View())
+	sb.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		sb.WriteString(utils.MutedText.Render("No matches"))
+	}
+
+	// Keep the cursor within the listed rows
+	offset := 0
+	if p.cursor >= maxPaletteRows {
+		offset = p.cursor - maxPaletteRows + 1
+	}
+	end := min(offset+maxPaletteRows, len(p.matches))
+	for i := offset; i < end; i++ {
+		sb.WriteString(p.row(p.matches[i], i == p.cursor, width))
+		if i < end-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(p.matches) > maxPaletteRows {
+		sb.WriteString("\n\n" + utils.MutedText.Render(fmt.Sprintf("%d of %d", p.cursor+1, len(p.matches))))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(utils.ColorPrimary).
+		Padding(0, 1).
+		Width(width).
+		Render(sb.String())
+
+	return lipgloss.PlaceHorizontal(max(p.width, lipgloss.Width(box)), lipgloss.Center, box)
+}
+
+// row renders a match with its matched characters highlighted, and its kind
+// aligned to the right
+func (p PaletteComponent) row(match paletteMatch, current bool, width int) string {
+	entry := p.entries[match.entry]
+
+	matched := make(map[int]bool, len(match.positions))
+	for _, pos := range match.positions {
+		matched[pos] = true
+	}
+
+	var title strings.Builder
+	for i, r := range []rune(entry.Title) {
+		if matched[i] {
+			title.WriteString(utils.MatchStyle.Render(string(r)))
+		} else {
+			title.WriteRune(r)
+		}
+	}
+
+	prefix := "  "
+	if current {
+		prefix = utils.BoldText.Render("▸ ")
+	}
+
+	// The box's padding takes a column on each side
+	inner := width - 2
+	kind := utils.MutedText.Render(entry.Kind)
+	line := prefix + title.String()
+	used := 2 + runewidth.StringWidth(entry.Title) + runewidth.StringWidth(entry.Kind) + 1
+	if entry.Detail != "" && used+runewidth.StringWidth(entry.Detail)+2 < inner {
+		line += "  " + utils.MutedText.Render(entry.Detail)
+		used += runewidth.StringWidth(entry.Detail) + 2
+	}
+	return line + strings.Repeat(" ", max(inner-used+1, 1)) + kind
+}
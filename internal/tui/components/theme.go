@@ -0,0 +1,48 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// newItemDelegate returns a list delegate styled with the current theme
+func newItemDelegate() list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+
+	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Foreground(utils.ColorWhite)
+	delegate.Styles.NormalDesc = delegate.Styles.NormalDesc.Foreground(utils.ColorMuted)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(utils.ColorHighlight).
+		BorderForeground(utils.ColorHighlight)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(utils.ColorMuted).
+		BorderForeground(utils.ColorHighlight)
+	delegate.Styles.DimmedTitle = delegate.Styles.DimmedTitle.Foreground(utils.ColorMuted)
+	delegate.Styles.DimmedDesc = delegate.Styles.DimmedDesc.Foreground(utils.ColorLightGray)
+
+	return delegate
+}
+
+// styleList applies the current theme to a list
+func styleList(l *list.Model) {
+	l.SetDelegate(newItemDelegate())
+	fitListTitle(l)
+}
+
+// fitListTitle boxes the title of a list when there is room for it. The list
+// cuts its title to the width of all its lines put together, so a narrow
+// list, such as one in a split pane, gets its title on a single line.
+func fitListTitle(l *list.Model) {
+	l.Styles.Title = utils.TitleStyle
+
+	width := 0
+	for _, line := range strings.Split(utils.TitleStyle.Render(l.Title), "\n") {
+		width += lipgloss.Width(line)
+	}
+	if l.Width() > 0 && width >= l.Width() {
+		l.Styles.Title = utils.TitleStyle.Copy().UnsetBorderStyle().MarginBottom(0)
+	}
+}
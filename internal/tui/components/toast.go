@@ -0,0 +1,132 @@
+package components
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// ToastLevel sets the colour of a toast
+type ToastLevel int
+
+const (
+	ToastInfo ToastLevel = iota
+	ToastSuccess
+	ToastWarning
+	ToastError
+)
+
+const (
+	// toastDuration is how long a toast stays on screen
+	toastDuration = 5 * time.Second
+	// maxToasts is how many toasts are shown at once; older ones make way
+	maxToasts = 3
+)
+
+// toast is a notification shown until it expires
+type toast struct {
+	id    int
+	text  string
+	level ToastLevel
+}
+
+// toastExpiredMsg removes a toast once its time is up
+type toastExpiredMsg struct {
+	id int
+}
+
+// ToastsComponent shows short-lived notifications above the status bar
+type ToastsComponent struct {
+	toasts []toast
+	nextID int
+	width  int
+}
+
+// NewToasts creates a component without any toasts
+func NewToasts() ToastsComponent {
+	return ToastsComponent{}
+}
+
+// Push shows a new toast; the returned command removes it when it expires.
+// The same toast still on screen is moved down and shown for longer rather
+// than repeated.
+func (t ToastsComponent) Push(text string, level ToastLevel) (ToastsComponent, tea.Cmd) {
+	t.nextID++
+	id := t.nextID
+
+	toasts := make([]toast, 0, len(t.toasts)+1)
+	for _, shown := range t.toasts {
+		if shown.text != text || shown.level != level {
+			toasts = append(toasts, shown)
+		}
+	}
+	t.toasts = append(toasts, toast{id: id, text: text, level: level})
+	if len(t.toasts) > maxToasts {
+		t.toasts = t.toasts[len(t.toasts)-maxToasts:]
+	}
+
+	return t, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// Init initializes the toasts component
+func (t ToastsComponent) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (t ToastsComponent) Update(msg tea.Msg) (ToastsComponent, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width = msg.Width
+
+	case toastExpiredMsg:
+		for i, toast := range t.toasts {
+			if toast.id == msg.id {
+				t.toasts = append(t.toasts[:i:i], t.toasts[i+1:]...)
+				break
+			}
+		}
+	}
+	return t, nil
+}
+
+// View renders the toasts, newest last, right-aligned with each other and
+// no wider than the screen
+func (t ToastsComponent) View() string {
+	if len(t.toasts) == 0 {
+		return ""
+	}
+
+	var rows []string
+	for _, toast := range t.toasts {
+		box := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(toastColor(toast.level)).
+			Foreground(utils.ColorWhite).
+			Padding(0, 1)
+		if t.width > 0 {
+			box = box.MaxWidth(t.width)
+		}
+		rows = append(rows, box.Render(toast.text))
+	}
+
+	return strings.TrimRight(lipgloss.JoinVertical(lipgloss.Right, rows...), "\n")
+}
+
+// toastColor returns the theme colour of a toast level
+func toastColor(level ToastLevel) lipgloss.Color {
+	switch level {
+	case ToastSuccess:
+		return utils.ColorSuccess
+	case ToastWarning:
+		return utils.ColorWarning
+	case ToastError:
+		return utils.ColorSecondary
+	}
+	return utils.ColorPrimary
+}
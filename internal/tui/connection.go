@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+	"go.uber.org/zap"
+)
+
+// Waits between attempts to reconnect, doubling from the first to the last
+const (
+	firstRetryDelay = time.Second
+	maxRetryDelay   = time.Minute
+)
+
+// ConnState is the state of the connection to the Jenkins server
+type ConnState int
+
+const (
+	StateConnecting ConnState = iota // Not connected yet
+	StateConnected                   // Requests get answers
+	StateDegraded                    // The server is reachable but failing
+	StateOffline                     // The server cannot be reached
+	StateAuthFailed                  // The server rejects the credentials
+)
+
+// String returns the name of the state shown in the status bar
+func (c ConnState) String() string {
+	switch c {
+	case StateConnected:
+		return "connected"
+	case StateDegraded:
+		return "degraded"
+	case StateOffline:
+		return "offline"
+	case StateAuthFailed:
+		return "auth failed"
+	}
+	return "connecting"
+}
+
+// Online reports whether requests are sent to the server in this state
+func (c ConnState) Online() bool {
+	return c == StateConnected || c == StateDegraded
+}
+
+// useClient makes the service send its requests with a client to a server,
+// identified in the cache by its URL. The outcomes of the requests set the
+// state of the connection, and they are traced in the log in debug mode.
+// Outcomes of the requests of a client replaced since are ignored.
+func (s *JenkinsService) useClient(client *api.JenkinsClient, server string) {
+	client.Observe(func(outcome api.Outcome) {
+		s.observe(client, outcome)
+	})
+	client.Trace(utils.GetLogger())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.session = session{id: s.session.id + 1, client: client, server: server}
+	s.serverInfo = nil
+	s.lastRefresh = time.Time{}
+	s.state = StateConnecting
+	s.lastContact = time.Time{}
+}
+
+// observe moves the connection to the state the outcome of a request shows
+func (s *JenkinsService) observe(client *api.JenkinsClient, outcome api.Outcome) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if client != s.session.client {
+		return
+	}
+
+	state := s.state
+	switch outcome {
+	case api.OutcomeOK:
+		state = StateConnected
+		s.lastContact = time.Now()
+	case api.OutcomeServerError:
+		state = StateDegraded
+		s.lastContact = time.Now()
+	case api.OutcomeUnauthorized:
+		state = StateAuthFailed
+	case api.OutcomeUnreachable:
+		state = StateOffline
+	}
+	s.setState(state)
+}
+
+// setState moves the connection to a state, logging the change; the caller
+// holds mutex
+func (s *JenkinsService) setState(state ConnState) {
+	if state != s.state {
+		utils.GetLogger().Info("connection state changed",
+			zap.String("from", s.state.String()), zap.String("to", state.String()))
+	}
+	s.state = state
+}
+
+// connectFailed settles the state after a failed connection attempt whose
+// requests did not show what is wrong, such as an answer that could not be
+// parsed, unless the server was switched since
+func (s *JenkinsService) connectFailed(sess session) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sess.id != s.session.id {
+		return
+	}
+	switch s.state {
+	case StateConnecting:
+		s.setState(StateOffline)
+	case StateConnected:
+		s.setState(StateDegraded)
+	}
+}
+
+// State returns the state of the connection and when the server last
+// answered, which is when the data shown was fetched while it is offline
+func (s *JenkinsService) State() (ConnState, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.state, s.lastContact
+}
+
+// checkOnline returns why no request can be sent in the current state, or
+// nil if one can
+func (s *JenkinsService) checkOnline() error {
+	state, _ := s.State()
+	switch state {
+	case StateOffline:
+		return fmt.Errorf("Jenkins server cannot be reached")
+	case StateAuthFailed:
+		return fmt.Errorf("credentials rejected by Jenkins server")
+	case StateConnecting:
+		return fmt.Errorf("not connected to Jenkins server")
+	}
+	return nil
+}
+
+// reconnectMsg is sent when it is time to try to reconnect; only the
+// attempt planned last is made
+type reconnectMsg struct {
+	id int
+}
+
+// retryDelay returns how long to wait to reconnect after a number of failed
+// attempts in a row
+func retryDelay(failures int) time.Duration {
+	delay := firstRetryDelay << min(failures, 10)
+	return min(delay, maxRetryDelay)
+}
+
+// scheduleReconnect plans the next attempt to reconnect, waiting longer
+// after each failed one
+func (m Model) scheduleReconnect() (Model, tea.Cmd) {
+	delay := retryDelay(m.retries)
+	m.retries++
+	m.retryID++
+	m.retryAt = time.Now().Add(delay)
+
+	id := m.retryID
+	return m, tea.Tick(delay, func(time.Time) tea.Msg {
+		return reconnectMsg{id: id}
+	})
+}
+
+// cancelReconnect forgets the attempt to reconnect planned, if any
+func (m Model) cancelReconnect() Model {
+	m.retries = 0
+	m.retryID++
+	m.retryAt = time.Time{}
+	return m
+}
+
+// reconnect makes the attempt to reconnect planned last
+func (m Model) reconnect(msg reconnectMsg) (Model, tea.Cmd) {
+	if msg.id != m.retryID {
+		return m, nil
+	}
+	m.retryAt = time.Time{}
+	return m, m.Connect()
+}
+
+// syncConnection catches up with the state of the connection, which every
+// request changes, telling the user when it changes and planning to
+// reconnect once the server is out of reach
+func (m Model) syncConnection() (Model, tea.Cmd) {
+	state, lastContact := m.service.State()
+	m.dashboard = m.dashboard.WithConnection(state.String(), lastContact)
+	if state == m.conn {
+		return m, nil
+	}
+	from := m.conn
+	m.conn = state
+
+	var cmds []tea.Cmd
+	push := func(text string, level components.ToastLevel) {
+		var cmd tea.Cmd
+		m.toasts, cmd = m.toasts.Push(text, level)
+		cmds = append(cmds, cmd)
+	}
+
+	switch state {
+	case StateConnected:
+		m = m.cancelReconnect()
+		if from != StateConnecting {
+			push("Connection to Jenkins restored", components.ToastSuccess)
+		}
+	case StateDegraded:
+		push("Jenkins server is failing requests", components.ToastWarning)
+	case StateOffline:
+		push("Jenkins server is offline: showing the last data fetched", components.ToastWarning)
+		if m.retryAt.IsZero() {
+			var cmd tea.Cmd
+			m, cmd = m.scheduleReconnect()
+			cmds = append(cmds, cmd)
+		}
+	case StateAuthFailed:
+		// Trying again with the same credentials is no use
+		m = m.cancelReconnect()
+		m.errorMsg = fmt.Sprintf("Jenkins server rejected the credentials: check the username and token in %s", m.service.configPath)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// connectionStatus renders the state of the connection for the status bar,
+// with when the next attempt to reconnect is due
+func (m Model) connectionStatus() string {
+	text := "● " + m.conn.String()
+	if !m.retryAt.IsZero() {
+		text += fmt.Sprintf(", retry in %s", max(time.Until(m.retryAt).Round(time.Second), 0))
+	}
+
+	switch m.conn {
+	case StateConnected:
+		return utils.SuccessText.Render(text)
+	case StateDegraded:
+		return utils.WarningText.Render(text)
+	case StateConnecting:
+		return utils.MutedText.Render(text)
+	}
+	return utils.FailureText.Render(text)
+}
+
+// staleNote renders when the data shown was fetched, while the server is
+// not answering, or nothing when it is
+func (m Model) staleNote() string {
+	if m.conn == StateConnected || m.conn == StateConnecting {
+		return ""
+	}
+	_, lastContact := m.service.State()
+	if lastContact.IsZero() {
+		return ""
+	}
+	return utils.WarningText.Render(fmt.Sprintf("stale: data from %s", lastContact.Format("15:04:05")))
+}
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+)
+
+// actionResultMsg reports the outcome of stopping or deleting a job or build
+type actionResultMsg struct {
+	action string
+	job    string
+	build  int
+	method api.StopMethod // How the build was stopped
+	err    error
+}
+
+// buildKey identifies a build of a job
+func buildKey(job string, build int) string {
+	return fmt.Sprintf("%s#%d", job, build)
+}
+
+// contextBuild returns the build the current view is about, or 0 if none
+func (m Model) contextBuild() int {
+	switch m.currentView {
+	case BuildLogView:
+		return m.selectedBuild
+	case JobDetailView:
+		if selected := m.jobDetail.GetSelectedBuild(); selected != nil {
+			return selected.Number
+		}
+	case BuildChartView:
+		if selected := m.buildChart.Selected(); selected != nil {
+			return selected.Number
+		}
+	}
+	return 0
+}
+
+// buildRunning reports whether a build is known to be running
+func (m Model) buildRunning(job string, build int) bool {
+	if m.runningBuild(job) == build {
+		return true
+	}
+	if job != m.selectedJob {
+		return false
+	}
+	info := m.jobDetail.Build(build)
+	return info != nil && info.Status == string(api.StatusRunning)
+}
+
+// nextStopMethod returns how to stop a build: abort it first, then
+// terminate it and then kill it if it keeps running
+func (m Model) nextStopMethod(job string, build int) api.StopMethod {
+	switch m.stopRequests[buildKey(job, build)] {
+	case api.StopAbort:
+		return api.StopTerminate
+	case api.StopTerminate, api.StopKill:
+		return api.StopKill
+	}
+	return api.StopAbort
+}
+
+// confirmStop asks to stop the running build in view, the build selected
+// in the job or the running build of the job under the cursor, more
+// forcefully each time it is asked for the same build
+func (m Model) confirmStop() Model {
+	job, build := m.contextJob(), m.contextBuild()
+	if job == "" {
+		m.statusMessage = "No job selected"
+		return m
+	}
+	if build == 0 || !m.buildRunning(job, build) {
+		build = m.runningBuild(job)
+	}
+	if build == 0 {
+		m.statusMessage = fmt.Sprintf("%s is not running", job)
+		return m
+	}
+
+	method := m.nextStopMethod(job, build)
+	switch method {
+	case api.StopAbort:
+		m.confirm = m.confirm.Open(fmt.Sprintf("Stop build #%d of %s?", build, job), nil)
+	case api.StopTerminate:
+		m.confirm = m.confirm.Open(fmt.Sprintf("Build #%d of %s is still running: terminate it?", build, job), []string{
+			"It was asked to abort already.",
+			"Terminating a pipeline skips what is left of its cleanup.",
+		}).WithDanger()
+	case api.StopKill:
+		m.confirm = m.confirm.Open(fmt.Sprintf("Build #%d of %s is still running: kill it?", build, job), []string{
+			"It was asked to terminate already.",
+			"Killing a pipeline stops it at once, without any cleanup.",
+		}).WithDanger()
+	}
+
+	m.pendingAction = func() tea.Msg {
+		err := m.service.StopBuild(job, build, method)
+		return actionResultMsg{action: actionStop, job: job, build: build, method: method, err: err}
+	}
+	return m
+}
+
+// confirmDeleteJob asks to delete the job in view or under the cursor; on a
+// protected server its name has to be typed
+func (m Model) confirmDeleteJob() Model {
+	job := m.contextJob()
+	if job == "" {
+		m.statusMessage = "No job selected"
+		return m
+	}
+
+	m.confirm = m.confirm.Open(fmt.Sprintf("Delete job %s?", job), []string{
+		"The job, its configuration and all its builds are deleted.",
+		"This cannot be undone.",
+	}).WithDanger()
+	if m.service.Protected() {
+		m.confirm = m.confirm.WithRequiredText(job)
+	}
+
+	m.pendingAction = func() tea.Msg {
+		return actionResultMsg{action: actionDeleteJob, job: job, err: m.service.DeleteJob(job)}
+	}
+	return m
+}
+
+// confirmDeleteBuild asks to delete the build in view or selected in the
+// job; on a protected server the name of the job has to be typed
+func (m Model) confirmDeleteBuild() Model {
+	job, build := m.contextJob(), m.contextBuild()
+	if job == "" || build == 0 {
+		m.statusMessage = "No build selected"
+		return m
+	}
+
+	m.confirm = m.confirm.Open(fmt.Sprintf("Delete build #%d of %s?", build, job), []string{
+		"Its log, artifacts and test results are deleted.",
+		"This cannot be undone.",
+	}).WithDanger()
+	if m.service.Protected() {
+		m.confirm = m.confirm.WithRequiredText(job)
+	}
+
+	m.pendingAction = func() tea.Msg {
+		return actionResultMsg{action: actionDeleteBuild, job: job, build: build, err: m.service.DeleteBuild(job, build)}
+	}
+	return m
+}
+
+// actionDone updates the views once a job or build was stopped or deleted
+func (m Model) actionDone(msg actionResultMsg) (Model, tea.Cmd) {
+	text, level := msg.message()
+	var cmd tea.Cmd
+	m.toasts, cmd = m.toasts.Push(text, level)
+	cmds := []tea.Cmd{cmd}
+
+	if msg.err != nil {
+		return m.logError(text, msg.err.Error()), tea.Batch(cmds...)
+	}
+	m.statusMessage = text
+
+	switch msg.action {
+	case actionStop:
+		// Remember how far it went, to go further if the build keeps running
+		requests := make(map[string]api.StopMethod, len(m.stopRequests)+1)
+		for key, method := range m.stopRequests {
+			requests[key] = method
+		}
+		requests[buildKey(msg.job, msg.build)] = msg.method
+		m.stopRequests = requests
+
+	case actionDeleteJob:
+		m.history = m.history.without(m.serverName(), msg.job, 0)
+		if msg.job == m.selectedJob {
+			m.selectedJob, m.selectedBuild = "", 0
+			if m.currentView != DashboardView && m.currentView != HelpView && m.currentView != ErrorLogView {
+				m.currentView = JobListView
+			}
+		}
+
+	case actionDeleteBuild:
+		m.history = m.history.without(m.serverName(), msg.job, msg.build)
+		if msg.job == m.selectedJob {
+			if msg.build == m.selectedBuild && (m.currentView == BuildLogView || m.currentView == LogDiffView) {
+				m.currentView = JobDetailView
+			}
+			if m.canFetch() {
+				cmds = append(cmds, m.FetchJobDetail(msg.job))
+			}
+		}
+	}
+
+	if m.canFetch() {
+		cmds = append(cmds, m.FetchJobs())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// message summarises the outcome for a toast
+func (r actionResultMsg) message() (string, components.ToastLevel) {
+	var text string
+	switch r.action {
+	case actionStop:
+		verbs := map[api.StopMethod]string{api.StopAbort: "Stopped", api.StopTerminate: "Terminated", api.StopKill: "Killed"}
+		text = fmt.Sprintf("%s build #%d of %s", verbs[r.method], r.build, r.job)
+		if r.err != nil {
+			text = fmt.Sprintf("Failed to stop build #%d of %s", r.build, r.job)
+		}
+	case actionDeleteJob:
+		text = fmt.Sprintf("Deleted job %s", r.job)
+		if r.err != nil {
+			text = fmt.Sprintf("Failed to delete job %s", r.job)
+		}
+	case actionDeleteBuild:
+		text = fmt.Sprintf("Deleted build #%d of %s", r.build, r.job)
+		if r.err != nil {
+			text = fmt.Sprintf("Failed to delete build #%d of %s", r.build, r.job)
+		}
+	}
+
+	if r.err != nil {
+		return text, components.ToastError
+	}
+	return text, components.ToastSuccess
+}
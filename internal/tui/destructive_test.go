@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+)
+
+func TestNextStopMethod(t *testing.T) {
+	tests := []struct {
+		name  string
+		asked map[string]api.StopMethod // Stop requests sent so far
+		want  api.StopMethod
+	}{
+		{"first time", nil, api.StopAbort},
+		{"aborted", map[string]api.StopMethod{"app#7": api.StopAbort}, api.StopTerminate},
+		{"terminated", map[string]api.StopMethod{"app#7": api.StopTerminate}, api.StopKill},
+		{"killed", map[string]api.StopMethod{"app#7": api.StopKill}, api.StopKill},
+		{"another build aborted", map[string]api.StopMethod{"app#6": api.StopAbort, "web#7": api.StopTerminate}, api.StopAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{stopRequests: tt.asked}
+			if got := m.nextStopMethod("app", 7); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// maxHistory is the number of places remembered to go back to
+const maxHistory = 100
+
+// place is somewhere the user has been: a view of a job or build, and where
+// they were within it
+type place struct {
+	server string
+	view   ViewType
+	job    string
+	build  int    // Build whose log is open
+	diff   [2]int // Builds compared
+
+	// Positions within the views
+	jobCursor   string // Job under the cursor in the job list
+	buildCursor int    // Build under the cursor in the job's builds
+	logLine     int    // Log line at the top of the build log
+}
+
+// history holds the places to go back and forward to, most recent last
+type history struct {
+	back    []place
+	forward []place
+}
+
+// visited records leaving a place for a new one, which forgets the places
+// to go forward to
+func (h history) visited(p place) history {
+	back := append(h.back[:len(h.back):len(h.back)], p)
+	if len(back) > maxHistory {
+		back = back[len(back)-maxHistory:]
+	}
+	return history{back: back}
+}
+
+// without forgets the places showing a deleted build, or a deleted job when
+// build is 0, so that going back or forward cannot return to them. The
+// places left that had it selected no longer do.
+func (h history) without(server, job string, build int) history {
+	forget := func(places []place) []place {
+		kept := make([]place, 0, len(places))
+		for _, p := range places {
+			if p.server == server && p.job == job {
+				if p.shows(build) {
+					continue
+				}
+				if build == 0 {
+					p.job, p.build, p.buildCursor = "", 0, 0
+				} else if p.build == build {
+					p.build, p.logLine = 0, 0
+				}
+			}
+			kept = append(kept, p)
+		}
+		return kept
+	}
+	return history{back: forget(h.back), forward: forget(h.forward)}
+}
+
+// shows reports whether a place shows a build of its job, or the job itself
+// when build is 0
+func (p place) shows(build int) bool {
+	switch p.view {
+	case JobDetailView, BuildChartView:
+		return build == 0
+	case BuildLogView:
+		return build == 0 || p.build == build
+	case LogDiffView:
+		return build == 0 || p.diff[0] == build || p.diff[1] == build
+	}
+	return false
+}
+
+// location returns where the user is, without the positions within the view.
+// Only the parts of it shown by the view count, so that refreshing the job
+// in view, for instance, is not a move.
+func (m Model) location() place {
+	p := place{server: m.serverName(), view: m.currentView}
+	switch m.currentView {
+	case JobDetailView, BuildChartView:
+		p.job = m.selectedJob
+	case BuildLogView:
+		p.job, p.build = m.selectedJob, m.selectedBuild
+	case LogDiffView:
+		p.job = m.selectedJob
+		p.diff[0], p.diff[1] = m.logDiff.Builds()
+	}
+	return p
+}
+
+// here returns where the user is, positions within the views included
+func (m Model) here() place {
+	p := m.location()
+	p.job, p.build = m.selectedJob, m.selectedBuild
+	if selected := m.jobList.GetSelected(); selected != nil {
+		p.jobCursor = selected.Name
+	}
+	if selected := m.jobDetail.GetSelectedBuild(); selected != nil {
+		p.buildCursor = selected.Number
+	}
+	if m.buildLog.Shows(m.selectedJob, m.selectedBuild) {
+		p.logLine = m.buildLog.TopLine()
+	}
+	return p
+}
+
+// serverName returns the name of the server connected to
+func (m Model) serverName() string {
+	_, current := m.service.Servers()
+	return current
+}
+
+// recordMove remembers where the user was before a message took them
+// somewhere else. Going back or forward is not a move, and neither is
+// switching servers, which forgets the places of the previous one.
+func (m Model) recordMove(before Model) Model {
+	if m.travelled {
+		m.travelled = false
+		return m
+	}
+
+	from := before.location()
+	if from == m.location() || from.server != m.serverName() {
+		return m
+	}
+	m.history = m.history.visited(before.here())
+	return m
+}
+
+// goBack returns to the previous place, or to the parent of the view when
+// there is none
+func (m Model) goBack() (Model, tea.Cmd) {
+	n := len(m.history.back)
+	if n == 0 {
+		return m.parentView(), nil
+	}
+
+	to := m.history.back[n-1]
+	m.history.back = m.history.back[:n-1]
+	m.history.forward = append(m.history.forward[:len(m.history.forward):len(m.history.forward)], m.here())
+	return m.travel(to)
+}
+
+// goForward returns to the place left by going back
+func (m Model) goForward() (Model, tea.Cmd) {
+	n := len(m.history.forward)
+	if n == 0 {
+		m.statusMessage = "Nowhere to go forward to"
+		return m, nil
+	}
+
+	to := m.history.forward[n-1]
+	m.history.forward = m.history.forward[:n-1]
+	m.history.back = append(m.history.back[:len(m.history.back):len(m.history.back)], m.here())
+	return m.travel(to)
+}
+
+// travel takes the user back to a place, fetching the job and build shown
+// there when they are not the ones in view
+func (m Model) travel(to place) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	m.travelled = true
+
+	if to.job != m.selectedJob && to.job != "" {
+		m.jobDetail = m.jobDetail.WithWatched(m.isWatched(to.job))
+		if m.canFetch() {
+			cmds = append(cmds, m.FetchJobDetail(to.job))
+		}
+	}
+	m.selectedJob, m.selectedBuild = to.job, to.build
+
+	// The log is fetched again unless it is still the one shown
+	logShown := to.view == BuildLogView || (m.layout.shows(to.view) && m.layout.shows(BuildLogView))
+	if logShown && to.build > 0 && !m.buildLog.Shows(to.job, to.build) && m.canFetch() {
+		cmds = append(cmds, m.FetchBuildLog(to.job, to.build))
+	}
+
+	if to.view == LogDiffView {
+		if oldBuild, newBuild := m.logDiff.Builds(); [2]int{oldBuild, newBuild} != to.diff {
+			m.logDiff = m.logDiff.WithBuilds(to.job, to.diff[0], to.diff[1])
+			if m.canFetch() {
+				cmds = append(cmds, m.FetchLogDiff(to.job, to.diff[0], to.diff[1]))
+			}
+		}
+	}
+
+	// Put the cursors and the log back where they were
+	m.jobList = m.jobList.SelectJob(to.jobCursor)
+	m.jobDetail = m.jobDetail.SelectBuild(to.buildCursor)
+	var cmd tea.Cmd
+	m.buildLog, cmd = m.buildLog.WithTopLine(to.job, to.build, to.logLine).LoadMore()
+	cmds = append(cmds, cmd)
+
+	m.currentView = to.view
+	m.statusMessage = m.viewStatus(to.view)
+	return m, tea.Batch(cmds...)
+}
+
+// parentView goes up from the current view: from a build or the job's
+// charts to the job, from the job to the job list and from help or the
+// error log to the dashboard
+func (m Model) parentView() Model {
+	switch m.currentView {
+	case JobDetailView:
+		m.currentView = JobListView
+	case BuildLogView, LogDiffView, BuildChartView:
+		m.currentView = JobDetailView
+	case HelpView, ErrorLogView:
+		m.currentView = DashboardView
+	}
+	m.statusMessage = m.viewStatus(m.currentView)
+	return m
+}
+
+// viewStatus returns the status message shown on opening a view
+func (m Model) viewStatus(view ViewType) string {
+	switch view {
+	case DashboardView:
+		return "Dashboard View"
+	case JobListView:
+		return "Job List View"
+	case JobDetailView:
+		return fmt.Sprintf("Job: %s", m.selectedJob)
+	case BuildLogView:
+		return fmt.Sprintf("Build #%d Logs", m.selectedBuild)
+	case LogDiffView:
+		oldBuild, newBuild := m.logDiff.Builds()
+		return fmt.Sprintf("Comparing builds #%d and #%d", oldBuild, newBuild)
+	case BuildChartView:
+		return fmt.Sprintf("Build Durations: %s", m.selectedJob)
+	case HelpView:
+		return "Help View"
+	case ErrorLogView:
+		return "Error Log"
+	}
+	return ""
+}
+
+// breadcrumbs renders where the user is, from the server down to the view,
+// such as prod › platform/deploy-api › #412 › log
+func (m Model) breadcrumbs() string {
+	var crumbs []string
+	if server := m.serverName(); server != "" {
+		crumbs = append(crumbs, server)
+	}
+	switch m.currentView {
+	case DashboardView:
+		crumbs = append(crumbs, "dashboard")
+	case JobListView:
+		crumbs = append(crumbs, "jobs")
+	case JobDetailView:
+		crumbs = append(crumbs, m.selectedJob)
+	case BuildLogView:
+		crumbs = append(crumbs, m.selectedJob, fmt.Sprintf("#%d", m.selectedBuild), "log")
+	case LogDiffView:
+		oldBuild, newBuild := m.logDiff.Builds()
+		crumbs = append(crumbs, m.selectedJob, fmt.Sprintf("#%d → #%d", oldBuild, newBuild), "diff")
+	case BuildChartView:
+		crumbs = append(crumbs, m.selectedJob, "durations")
+	case HelpView:
+		crumbs = append(crumbs, "help")
+	case ErrorLogView:
+		crumbs = append(crumbs, "errors")
+	}
+
+	last := len(crumbs) - 1
+	if last == 0 {
+		return utils.HeaderText.Render(crumbs[last])
+	}
+	return utils.MutedText.Render(strings.Join(crumbs[:last], " › ")+" › ") + utils.HeaderText.Render(crumbs[last])
+}
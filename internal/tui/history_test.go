@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistoryVisited(t *testing.T) {
+	jobs := place{server: "ci", view: JobListView}
+	app := place{server: "ci", view: JobDetailView, job: "app"}
+	web := place{server: "ci", view: JobDetailView, job: "web"}
+
+	h := history{back: []place{jobs}, forward: []place{web}}
+	h = h.visited(app)
+	if !reflect.DeepEqual(h.back, []place{jobs, app}) || len(h.forward) != 0 {
+		t.Errorf("back %v forward %v, want app visited and nothing to go forward to", h.back, h.forward)
+	}
+
+	// Visiting from the same history twice does not share its places
+	first, second := h.visited(web), h.visited(jobs)
+	if first.back[2] != web || second.back[2] != jobs {
+		t.Errorf("visits overwrote each other: %v, %v", first.back, second.back)
+	}
+
+	// Only the most recent places are kept
+	for i := 0; i < maxHistory+10; i++ {
+		h = h.visited(place{server: "ci", view: BuildLogView, job: "app", build: i})
+	}
+	if len(h.back) != maxHistory || h.back[len(h.back)-1].build != maxHistory+9 {
+		t.Errorf("%d places kept, the last for build %d", len(h.back), h.back[len(h.back)-1].build)
+	}
+}
+
+func TestHistoryWithout(t *testing.T) {
+	jobs := place{server: "ci", view: JobListView, jobCursor: "app"}
+	selected := place{server: "ci", view: JobListView, job: "app", build: 7, buildCursor: 3}
+	detail := place{server: "ci", view: JobDetailView, job: "app", buildCursor: 3}
+	log7 := place{server: "ci", view: BuildLogView, job: "app", build: 7, logLine: 40}
+	log6 := place{server: "ci", view: BuildLogView, job: "app", build: 6}
+	diff := place{server: "ci", view: LogDiffView, job: "app", diff: [2]int{6, 7}}
+	chart := place{server: "ci", view: BuildChartView, job: "app"}
+	web := place{server: "ci", view: BuildLogView, job: "web", build: 7}
+	other := place{server: "other", view: BuildLogView, job: "app", build: 7}
+
+	h := history{
+		back:    []place{jobs, selected, detail, log7, diff},
+		forward: []place{log6, chart, web, other},
+	}
+
+	tests := []struct {
+		name        string
+		job         string
+		build       int
+		wantBack    []place
+		wantForward []place
+	}{
+		{
+			name:        "build deleted",
+			job:         "app",
+			build:       7,
+			wantBack:    []place{jobs, {server: "ci", view: JobListView, job: "app", buildCursor: 3}, detail},
+			wantForward: []place{log6, chart, web, other},
+		},
+		{
+			name:        "job deleted",
+			job:         "app",
+			wantBack:    []place{jobs, {server: "ci", view: JobListView}},
+			wantForward: []place{web, other},
+		},
+		{
+			name:        "nothing shown",
+			job:         "app",
+			build:       5,
+			wantBack:    h.back,
+			wantForward: h.forward,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.without("ci", tt.job, tt.build)
+			if !reflect.DeepEqual(got.back, tt.wantBack) {
+				t.Errorf("back %+v, want %+v", got.back, tt.wantBack)
+			}
+			if !reflect.DeepEqual(got.forward, tt.wantForward) {
+				t.Errorf("forward %+v, want %+v", got.forward, tt.wantForward)
+			}
+		})
+	}
+}
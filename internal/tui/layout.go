@@ -0,0 +1,311 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// footerHeight is the number of rows below the view: the status bar, the
+// help and the error, each after a blank line but the error
+const footerHeight = 5
+
+// Pane sizes, in percent of the view area
+const (
+	minPaneSize = 15
+	resizeStep  = 5
+)
+
+// paneNames are the views that can be split, by the name used in the config
+var paneNames = []string{"jobs", "job", "log"}
+
+// paneViews maps the names in paneNames to their views
+var paneViews = map[string]ViewType{"jobs": JobListView, "job": JobDetailView, "log": BuildLogView}
+
+// pane is the area given to a view in the split, border included
+type pane struct {
+	view          ViewType
+	x, y          int
+	width, height int
+}
+
+// layout arranges the view area between the tab bar and the status bar:
+// one view at a time, or the views of the split side by side (or stacked) in
+// bordered panes while one of them is open
+type layout struct {
+	split bool
+	names []string
+	sizes []int // Percent of the view area, adding up to 100
+	stack bool
+
+	width, height int
+}
+
+// newLayout validates the layout settings
+func newLayout(settings config.Layout) (layout, error) {
+	l := layout{split: settings.Split, stack: settings.Stack, names: settings.Panes}
+	if len(l.names) == 0 {
+		l.names = paneNames
+	}
+	if len(l.names) < 2 {
+		return l, fmt.Errorf("ui.layout.panes: a split needs two or more views")
+	}
+
+	seen := map[string]bool{}
+	for i, name := range l.names {
+		if _, ok := paneViews[name]; !ok {
+			return l, fmt.Errorf("ui.layout.panes[%d]: unknown view %q (valid views: %s)", i, name, strings.Join(paneNames, ", "))
+		}
+		if seen[name] {
+			return l, fmt.Errorf("ui.layout.panes[%d]: %q is listed twice", i, name)
+		}
+		seen[name] = true
+	}
+
+	switch {
+	case len(settings.Sizes) == 0:
+		l.sizes = make([]int, len(l.names))
+		for i := range l.sizes {
+			l.sizes[i] = 100 / len(l.names)
+		}
+	case len(settings.Sizes) != len(l.names):
+		return l, fmt.Errorf("ui.layout.sizes: give one size per pane (%d panes, %d sizes)", len(l.names), len(settings.Sizes))
+	default:
+		total := 0
+		for i, size := range settings.Sizes {
+			if size <= 0 {
+				return l, fmt.Errorf("ui.layout.sizes[%d]: size must be positive", i)
+			}
+			total += size
+		}
+		// Sizes are relative, so 2, 1, 1 works as well as 50, 25, 25
+		l.sizes = make([]int, len(settings.Sizes))
+		for i, size := range settings.Sizes {
+			if l.sizes[i] = size * 100 / total; l.sizes[i] < minPaneSize {
+				return l, fmt.Errorf("ui.layout.sizes[%d]: every pane needs at least %d%% of the screen", i, minPaneSize)
+			}
+		}
+	}
+	l.sizes = fillSizes(l.sizes)
+	return l, nil
+}
+
+// fillSizes gives the percent left over by rounding to the last pane
+func fillSizes(sizes []int) []int {
+	total := 0
+	for _, size := range sizes[:len(sizes)-1] {
+		total += size
+	}
+	sizes[len(sizes)-1] = 100 - total
+	return sizes
+}
+
+// arrangement returns the split as arranged, to be remembered between runs
+func (l layout) arrangement() config.PaneLayout {
+	return config.PaneLayout{Split: l.split, Sizes: l.sizes}
+}
+
+// restore takes over a split as last arranged; sizes that no longer fit the
+// panes, because the config file changed since, are left as configured
+func (l layout) restore(saved config.PaneLayout) layout {
+	l.split = saved.Split
+	if len(saved.Sizes) != len(l.sizes) {
+		return l
+	}
+	total := 0
+	for _, size := range saved.Sizes {
+		if size < minPaneSize {
+			return l
+		}
+		total += size
+	}
+	if total == 100 {
+		l.sizes = append([]int(nil), saved.Sizes...)
+	}
+	return l
+}
+
+// withSize sets the size of the view area
+func (l layout) withSize(width, height int) layout {
+	l.width = width
+	l.height = height
+	return l
+}
+
+// toggle turns the split on or off
+func (l layout) toggle() layout {
+	l.split = !l.split
+	return l
+}
+
+// shows reports whether a view is shown in the split
+func (l layout) shows(view ViewType) bool {
+	_, ok := l.pane(view)
+	return ok
+}
+
+// pane returns the pane of a view while the split is on
+func (l layout) pane(view ViewType) (pane, bool) {
+	for _, p := range l.panes() {
+		if p.view == view {
+			return p, true
+		}
+	}
+	return pane{}, false
+}
+
+// panes returns the panes of the split in order, or none when it is off
+func (l layout) panes() []pane {
+	if !l.split {
+		return nil
+	}
+
+	total := l.width
+	if l.stack {
+		total = l.height
+	}
+
+	panes := make([]pane, len(l.names))
+	start := 0
+	for i, name := range l.names {
+		size := total * l.sizes[i] / 100
+		if i == len(l.names)-1 {
+			size = total - start
+		}
+
+		p := pane{view: paneViews[name], x: start, width: size, height: l.height}
+		if l.stack {
+			p = pane{view: paneViews[name], y: start, width: l.width, height: size}
+		}
+		panes[i] = p
+		start += size
+	}
+	return panes
+}
+
+// paneAt returns the pane at a position in the view area
+func (l layout) paneAt(x, y int) (pane, bool) {
+	for _, p := range l.panes() {
+		if x >= p.x && x < p.x+p.width && y >= p.y && y < p.y+p.height {
+			return p, true
+		}
+	}
+	return pane{}, false
+}
+
+// next returns the view of the pane after (or before, for a negative step)
+// the one of view
+func (l layout) next(view ViewType, step int) ViewType {
+	panes := l.panes()
+	for i, p := range panes {
+		if p.view == view {
+			return panes[(i+step+len(panes))%len(panes)].view
+		}
+	}
+	return view
+}
+
+// resize grows the pane of a view by delta percent, taking the room from the
+// pane after it, or before it for the last pane. It reports whether both
+// panes keep their minimum size.
+func (l layout) resize(view ViewType, delta int) (layout, bool) {
+	i := -1
+	for j, name := range l.names {
+		if paneViews[name] == view {
+			i = j
+		}
+	}
+	if !l.split || i < 0 {
+		return l, false
+	}
+
+	neighbour := i + 1
+	if neighbour == len(l.names) {
+		neighbour = i - 1
+	}
+	if l.sizes[i]+delta < minPaneSize || l.sizes[neighbour]-delta < minPaneSize {
+		return l, false
+	}
+
+	sizes := append([]int(nil), l.sizes...)
+	sizes[i] += delta
+	sizes[neighbour] -= delta
+	l.sizes = sizes
+	return l, true
+}
+
+// render draws the panes of the split with the content of their views; the
+// focused pane has a highlighted border
+func (l layout) render(content func(ViewType) string, focus ViewType) string {
+	var boxes []string
+	for _, p := range l.panes() {
+		border := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(utils.ColorDarkGray)
+		if p.view == focus {
+			border = border.BorderForeground(utils.ColorPrimary)
+		}
+		boxes = append(boxes, border.Render(fitBlock(content(p.view), p.width-2, p.height-2)))
+	}
+
+	if l.stack {
+		return lipgloss.JoinVertical(lipgloss.Left, boxes...)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+}
+
+// fitBlock cuts or pads text to exactly width columns and height rows,
+// cutting long lines rather than wrapping them
+func fitBlock(text string, width, height int) string {
+	width, height = max(width, 0), max(height, 0)
+	text = lipgloss.NewStyle().MaxWidth(width).MaxHeight(height).Render(text)
+	return lipgloss.NewStyle().Width(width).Height(height).Render(text)
+}
+
+// paneContent renders the view of a pane, or what to open when it has
+// nothing to show yet
+func (m Model) paneContent(view ViewType) string {
+	switch {
+	case view == JobDetailView && m.selectedJob == "":
+		return utils.MutedText.Render("No job open: select one in the job list")
+	case view == BuildLogView && m.selectedBuild == 0:
+		return utils.MutedText.Render("No build open: select one in the job's builds")
+	}
+	return m.viewContent(view)
+}
+
+// focusPane gives the keyboard to the view of a pane
+func (m Model) focusPane(view ViewType) Model {
+	m.currentView = view
+	m.statusMessage = m.viewStatus(view)
+	return m
+}
+
+// resizePane grows the focused pane by delta percent, or shrinks it for a
+// negative delta, and saves the new sizes
+func (m Model) resizePane(delta int) (Model, tea.Cmd) {
+	layout, ok := m.layout.resize(m.currentView, delta)
+	if !ok {
+		m.statusMessage = fmt.Sprintf("Panes take at least %d%% of the screen", minPaneSize)
+		return m, nil
+	}
+
+	m.layout = layout
+	m = m.saveLayout()
+	return m.resize()
+}
+
+// saveLayout remembers the arrangement of the panes in the state file,
+// leaving the config file as the user wrote it
+func (m Model) saveLayout() Model {
+	arrangement := m.layout.arrangement()
+	m.state.Layout = &arrangement
+	if err := m.state.Save(); err != nil {
+		m.errorMsg = fmt.Sprintf("Failed to save layout: %v", err)
+	}
+	return m
+}
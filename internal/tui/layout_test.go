@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.Layout
+		want     []int
+		wantErr  bool
+	}{
+		{"equal by default", config.Layout{}, []int{33, 33, 34}, false},
+		{"relative sizes", config.Layout{Sizes: []int{2, 1, 1}}, []int{50, 25, 25}, false},
+		{"rounding left to the last pane", config.Layout{Panes: []string{"jobs", "log"}, Sizes: []int{1, 2}}, []int{33, 67}, false},
+		{"percent sizes", config.Layout{Sizes: []int{20, 30, 50}}, []int{20, 30, 50}, false},
+		{"smallest pane", config.Layout{Sizes: []int{15, 15, 70}}, []int{15, 15, 70}, false},
+		{"pane too small", config.Layout{Sizes: []int{90, 5, 5}}, nil, true},
+		{"size per pane", config.Layout{Sizes: []int{50, 50}}, nil, true},
+		{"size not positive", config.Layout{Sizes: []int{50, 0, 50}}, nil, true},
+		{"unknown view", config.Layout{Panes: []string{"jobs", "queue"}}, nil, true},
+		{"view listed twice", config.Layout{Panes: []string{"jobs", "jobs"}}, nil, true},
+		{"single view", config.Layout{Panes: []string{"log"}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newLayout(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(l.sizes, tt.want) {
+				t.Errorf("sizes %v, want %v", l.sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutResize(t *testing.T) {
+	tests := []struct {
+		name  string
+		split bool
+		view  ViewType
+		delta int
+		want  []int
+		ok    bool
+	}{
+		{"grow into the next pane", true, JobListView, resizeStep, []int{38, 28, 34}, true},
+		{"shrink giving to the next pane", true, JobDetailView, -resizeStep, []int{33, 28, 39}, true},
+		{"last pane grows into the one before", true, BuildLogView, resizeStep, []int{33, 28, 39}, true},
+		{"neighbour at its minimum", true, JobListView, 20, []int{33, 33, 34}, false},
+		{"pane at its minimum", true, JobDetailView, -20, []int{33, 33, 34}, false},
+		{"split off", false, JobListView, resizeStep, []int{33, 33, 34}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newLayout(config.Layout{Split: tt.split})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, ok := l.resize(tt.view, tt.delta)
+			if ok != tt.ok || !reflect.DeepEqual(l.sizes, tt.want) {
+				t.Errorf("sizes %v ok %v, want %v ok %v", l.sizes, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLayoutRestore(t *testing.T) {
+	tests := []struct {
+		name  string
+		saved config.PaneLayout
+		want  []int
+	}{
+		{"arrangement taken", config.PaneLayout{Split: true, Sizes: []int{50, 15, 35}}, []int{50, 15, 35}},
+		{"no sizes saved", config.PaneLayout{Split: true}, []int{20, 30, 50}},
+		{"panes changed since", config.PaneLayout{Split: true, Sizes: []int{50, 50}}, []int{20, 30, 50}},
+		{"pane too small", config.PaneLayout{Split: true, Sizes: []int{80, 10, 10}}, []int{20, 30, 50}},
+		{"not adding up", config.PaneLayout{Split: true, Sizes: []int{40, 40, 40}}, []int{20, 30, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newLayout(config.Layout{Sizes: []int{20, 30, 50}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l = l.restore(tt.saved)
+			if !l.split || !reflect.DeepEqual(l.sizes, tt.want) {
+				t.Errorf("split %v sizes %v, want the split with %v", l.split, l.sizes, tt.want)
+			}
+		})
+	}
+
+	// The saved sizes are copied, resizing leaves the state alone
+	saved := config.PaneLayout{Split: true, Sizes: []int{40, 30, 30}}
+	l, _ := newLayout(config.Layout{})
+	l, _ = l.restore(saved).resize(JobListView, resizeStep)
+	if !reflect.DeepEqual(saved.Sizes, []int{40, 30, 30}) || !reflect.DeepEqual(l.arrangement().Sizes, []int{45, 25, 30}) {
+		t.Errorf("saved %v, arranged %v", saved.Sizes, l.arrangement().Sizes)
+	}
+}
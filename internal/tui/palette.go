@@ -0,0 +1,324 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// Views and actions offered by the command palette and the command line
+const (
+	actionDashboard   = "dashboard"
+	actionJobs        = "jobs"
+	actionQueue       = "queue"
+	actionHelp        = "help"
+	actionErrors      = "errors"
+	actionTrigger     = "trigger"
+	actionStop        = "stop"
+	actionWatch       = "watch"
+	actionChart       = "chart"
+	actionRefresh     = "refresh"
+	actionTheme       = "theme"
+	actionExportStats = "exportStats"
+	actionDeleteJob   = "deleteJob"
+	actionDeleteBuild = "deleteBuild"
+)
+
+// contextJob returns the job the current view is about, or "" if none
+func (m Model) contextJob() string {
+	switch m.currentView {
+	case JobListView:
+		if selected := m.jobList.GetSelected(); selected != nil {
+			return selected.Name
+		}
+	case JobDetailView, BuildLogView, LogDiffView, BuildChartView:
+		return m.selectedJob
+	}
+	return ""
+}
+
+// paletteEntries lists everything the command palette can open or run:
+// views, actions, other servers, jobs and their recent builds, each scored
+// by how often and how recently it was chosen
+func (m Model) paletteEntries() []components.PaletteEntry {
+	var entries []components.PaletteEntry
+	add := func(entry components.PaletteEntry) {
+		entries = append(entries, entry)
+	}
+
+	add(components.PaletteEntry{Kind: components.PaletteView, Title: "Go to dashboard", Target: actionDashboard})
+	add(components.PaletteEntry{Kind: components.PaletteView, Title: "Go to jobs", Target: actionJobs})
+	add(components.PaletteEntry{Kind: components.PaletteView, Title: "Open build queue", Target: actionQueue})
+	add(components.PaletteEntry{Kind: components.PaletteView, Title: "Show help", Target: actionHelp})
+	add(components.PaletteEntry{Kind: components.PaletteView, Title: "Show error log", Target: actionErrors})
+
+	// Actions on the job in view
+	if job := m.contextJob(); job != "" {
+		add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Trigger build", Detail: job, Target: actionTrigger})
+		if build := m.runningBuild(job); build > 0 {
+			add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Stop running build",
+				Detail: fmt.Sprintf("%s#%d", job, build), Target: actionStop})
+		}
+		add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Watch/unwatch job", Detail: job, Target: actionWatch})
+		if build := m.contextBuild(); build > 0 {
+			add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Delete build",
+				Detail: fmt.Sprintf("%s#%d", job, build), Target: actionDeleteBuild})
+		}
+		add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Delete job", Detail: job, Target: actionDeleteJob})
+	}
+	if m.selectedJob != "" && m.currentView != DashboardView && m.currentView != JobListView {
+		add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Show build durations", Detail: m.selectedJob, Target: actionChart})
+	}
+	add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Refresh", Target: actionRefresh})
+	add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Next theme", Detail: utils.NextThemeName(), Target: actionTheme})
+	add(components.PaletteEntry{Kind: components.PaletteAction, Title: "Export statistics", Detail: "CSV of all jobs", Target: actionExportStats})
+
+	servers, current := m.service.Servers()
+	for _, server := range servers {
+		if server.Name != current {
+			add(components.PaletteEntry{Kind: components.PaletteServer, Title: "Switch server: " + server.Name,
+				Detail: server.URL, Target: server.Name})
+		}
+	}
+
+	for _, job := range m.jobs {
+		add(components.PaletteEntry{Kind: components.PaletteJob, Title: job.Name, Detail: job.Status, Target: job.Name})
+	}
+
+	// Builds: the last one of every job, the history of the job in view and
+	// those opened before
+	seen := map[string]bool{}
+	addBuild := func(job string, number int, detail string) {
+		entry := components.PaletteEntry{Kind: components.PaletteBuild, Title: fmt.Sprintf("%s#%d", job, number),
+			Detail: detail, Target: job, Build: number}
+		if !seen[entry.ID()] {
+			seen[entry.ID()] = true
+			add(entry)
+		}
+	}
+	for _, job := range m.jobs {
+		if job.LastBuild != nil {
+			addBuild(job.Name, job.LastBuild.Number, string(job.LastBuild.Status))
+		}
+	}
+	if m.selectedJob != "" {
+		for _, build := range m.jobDetail.History() {
+			addBuild(m.selectedJob, build.Number, string(build.Status))
+		}
+	}
+
+	visits := m.state.Visits[m.service.serverKey()]
+	for id := range visits {
+		title, ok := strings.CutPrefix(id, components.PaletteBuild+":")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(title, "#")
+		if number, err := strconv.Atoi(title[i+1:]); i > 0 && err == nil {
+			addBuild(title[:i], number, "recent")
+		}
+	}
+
+	now := time.Now()
+	for i := range entries {
+		entries[i].Frecency = visits[entries[i].ID()].Frecency(now)
+	}
+	return entries
+}
+
+// runPaletteEntry opens or runs the entry chosen in the command palette and
+// remembers the choice for ranking
+func (m Model) runPaletteEntry(entry components.PaletteEntry) (Model, tea.Cmd) {
+	m.state.RecordVisit(m.service.serverKey(), entry.ID(), time.Now())
+	if err := m.state.Save(); err != nil {
+		m.errorMsg = err.Error()
+	}
+
+	switch entry.Kind {
+	case components.PaletteJob:
+		return m.openJob(entry.Target)
+
+	case components.PaletteBuild:
+		return m.openBuild(entry.Target, entry.Build)
+
+	case components.PaletteServer:
+		return m.switchServer(entry.Target)
+	}
+
+	return m.runAction(entry.Target)
+}
+
+// runAction opens one of the views or runs one of the actions offered by the
+// command palette and the command line
+func (m Model) runAction(action string) (Model, tea.Cmd) {
+	switch action {
+	case actionDashboard:
+		m.currentView = DashboardView
+		m.statusMessage = "Dashboard View"
+
+	case actionQueue:
+		// The queue is one of the dashboard widgets
+		m.currentView = DashboardView
+		m.statusMessage = "Build queue"
+		if m.conn.Online() {
+			return m, m.FetchLoad()
+		}
+
+	case actionJobs:
+		m.currentView = JobListView
+		m.statusMessage = "Job List View"
+		if m.canFetch() {
+			return m, m.FetchJobs()
+		}
+
+	case actionHelp:
+		m.currentView = HelpView
+		m.statusMessage = "Help View"
+
+	case actionErrors:
+		return m.openErrorLog(), nil
+
+	case actionTrigger:
+		return m.confirmJobs(bulkBuild, []string{m.contextJob()}), nil
+
+	case actionStop:
+		return m.confirmStop(), nil
+
+	case actionDeleteJob:
+		return m.confirmDeleteJob(), nil
+
+	case actionDeleteBuild:
+		return m.confirmDeleteBuild(), nil
+
+	case actionWatch:
+		return m.toggleWatched(), nil
+
+	case actionChart:
+		if m.selectedJob == "" {
+			m.statusMessage = "No job selected"
+			return m, nil
+		}
+		m.currentView = BuildChartView
+		m.statusMessage = fmt.Sprintf("Build Durations: %s", m.selectedJob)
+
+	case actionRefresh:
+		return m, m.Connect()
+
+	case actionTheme:
+		return m.switchTheme(utils.NextThemeName()), nil
+
+	case actionExportStats:
+		if !m.canFetch() || len(m.jobs) == 0 {
+			m.statusMessage = "No jobs to export statistics for"
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Exporting statistics of %d jobs...", len(m.jobs))
+		return m, m.ExportStats()
+	}
+
+	return m, nil
+}
+
+// openJob shows the details of a job
+func (m Model) openJob(name string) (Model, tea.Cmd) {
+	m.selectedJob = name
+	m.currentView = JobDetailView
+	m.jobDetail = m.jobDetail.WithWatched(m.isWatched(name))
+	m.statusMessage = fmt.Sprintf("Job: %s", name)
+
+	if m.canFetch() {
+		return m, m.FetchJobDetail(name)
+	}
+	return m, nil
+}
+
+// openBuild shows the log of a build, with its job's details behind it
+func (m Model) openBuild(jobName string, buildNumber int) (Model, tea.Cmd) {
+	m.selectedJob = jobName
+	m.selectedBuild = buildNumber
+	m.currentView = BuildLogView
+	m.jobDetail = m.jobDetail.WithWatched(m.isWatched(jobName))
+	m.statusMessage = fmt.Sprintf("Build #%d Logs", buildNumber)
+
+	if m.canFetch() {
+		return m, tea.Batch(m.FetchJobDetail(jobName), m.FetchBuildLog(jobName, buildNumber))
+	}
+	return m, nil
+}
+
+// canOpenSelected reports whether the current view has a list whose
+// selection enter opens
+func (m Model) canOpenSelected() bool {
+	switch m.currentView {
+	case JobListView, JobDetailView, BuildChartView:
+		return true
+	}
+	return false
+}
+
+// openSelected opens the selection of the current view: the job selected in
+// the job list, or the log of the build selected in the job's builds or its
+// duration chart
+func (m Model) openSelected() (Model, tea.Cmd) {
+	var buildNumber int
+	switch m.currentView {
+	case JobListView:
+		if selected := m.jobList.GetSelected(); selected != nil {
+			return m.openJob(selected.Name)
+		}
+	case JobDetailView:
+		if selected := m.jobDetail.GetSelectedBuild(); selected != nil {
+			buildNumber = selected.Number
+		}
+	case BuildChartView:
+		if selected := m.buildChart.Selected(); selected != nil {
+			buildNumber = selected.Number
+		}
+	}
+	if buildNumber == 0 {
+		return m, nil
+	}
+
+	m.selectedBuild = buildNumber
+	m.currentView = BuildLogView
+	m.statusMessage = fmt.Sprintf("Build #%d Logs", buildNumber)
+	if m.canFetch() && m.selectedJob != "" {
+		return m, m.FetchBuildLog(m.selectedJob, buildNumber)
+	}
+	return m, nil
+}
+
+// switchServer connects to another configured server, forgetting everything
+// shown of the current one
+func (m Model) switchServer(name string) (Model, tea.Cmd) {
+	if err := m.service.SwitchServer(name); err != nil {
+		m.errorMsg = fmt.Sprintf("Failed to switch server: %v", err)
+		return m, nil
+	}
+
+	m.conn = StateConnecting
+	m = m.cancelReconnect()
+	m.serverURL = ""
+	m.errorMsg = ""
+	m.jobs = nil
+	m.jobStates = nil
+	m.selectedJob = ""
+	m.selectedBuild = 0
+	m.history = history{}
+	m.stopRequests = nil
+	m.jobList = m.jobList.ClearMarks()
+	m = m.showJobs()
+	m.dashboard = m.dashboard.
+		WithServerInfo(components.ServerInfo{}).
+		WithQueue(nil).
+		WithExecutors(0, 0)
+
+	m.currentView = DashboardView
+	m.statusMessage = fmt.Sprintf("Connecting to %s...", name)
+	return m, m.Connect()
+}
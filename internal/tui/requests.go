@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+	"go.uber.org/zap"
+)
+
+// requestStartedMsg and requestDoneMsg bracket a request to the Jenkins
+// server; the second carries the result of the request
+type requestStartedMsg struct {
+	label string
+}
+
+type requestDoneMsg struct {
+	label  string
+	result tea.Msg
+}
+
+// request runs a call to the server in the background, showing a spinner
+// and what it is doing in the status bar until it returns
+func request(label string, call tea.Cmd) tea.Cmd {
+	return tea.Sequence(
+		func() tea.Msg { return requestStartedMsg{label: label} },
+		func() tea.Msg { return requestDoneMsg{label: label, result: call()} },
+	)
+}
+
+// fetchedMsg carries the result of a request for data of the server that
+// was current when the request was made
+type fetchedMsg struct {
+	session int
+	result  tea.Msg
+}
+
+// fetch runs a request for data of the current server; its result is
+// dropped if the server is switched before it returns, so that nothing of
+// the previous server shows up on the next
+func (m Model) fetch(label string, call tea.Cmd) tea.Cmd {
+	session := m.service.Session()
+	return request(label, func() tea.Msg {
+		return fetchedMsg{session: session, result: call()}
+	})
+}
+
+// newSpinner returns the spinner shown while requests are in flight
+func newSpinner() spinner.Model {
+	return spinner.New(
+		spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(utils.ColorPrimary)),
+	)
+}
+
+// requestStarted counts a request in, starting the spinner for the first
+func (m Model) requestStarted(label string) (Model, tea.Cmd) {
+	m.requests = append(m.requests[:len(m.requests):len(m.requests)], label)
+	if m.spinning {
+		return m, nil
+	}
+	m.spinning = true
+	return m, m.spinner.Tick
+}
+
+// requestDone counts a request out
+func (m Model) requestDone(label string) Model {
+	for i, l := range m.requests {
+		if l == label {
+			m.requests = append(m.requests[:i:i], m.requests[i+1:]...)
+			break
+		}
+	}
+	return m
+}
+
+// spin moves the spinner on while requests are in flight and lets it stop
+// once they are all done
+func (m Model) spin(msg spinner.TickMsg) (Model, tea.Cmd) {
+	if len(m.requests) == 0 {
+		m.spinning = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// requestStatus renders the spinner and the oldest request in flight, or
+// nothing when there is none
+func (m Model) requestStatus() string {
+	if len(m.requests) == 0 {
+		return ""
+	}
+	status := fmt.Sprintf("%s %s…", m.spinner.View(), m.requests[0])
+	if len(m.requests) > 1 {
+		status += utils.MutedText.Render(fmt.Sprintf(" (+%d)", len(m.requests)-1))
+	}
+	return status
+}
+
+// reportError shows the error the last message set, if any, in a toast and
+// adds it to the error log
+func (m Model) reportError() (Model, tea.Cmd) {
+	if m.errorMsg == "" {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.toasts, cmd = m.toasts.Push(m.errorMsg, components.ToastError)
+	summary, details, _ := strings.Cut(m.errorMsg, ": ")
+	m = m.logError(summary, details)
+	m.errorMsg = ""
+	return m, cmd
+}
+
+// logError adds an error to the error log, and to the log file, for errors
+// whose toast is shown already
+func (m Model) logError(summary, details string) Model {
+	utils.GetLogger().Warn(summary, zap.String("details", details), zap.String("view", m.viewStatus(m.currentView)))
+	m.errorLog = m.errorLog.Add(summary, details, time.Now())
+	return m
+}
+
+// openErrorLog shows the error log, which marks the errors as seen
+func (m Model) openErrorLog() Model {
+	m.currentView = ErrorLogView
+	m.statusMessage = m.viewStatus(ErrorLogView)
+	m.errorLog = m.errorLog.Seen()
+	return m
+}
+
+// errorHint renders the number of errors not seen in the error log yet
+func (m Model) errorHint() string {
+	n := m.errorLog.Unseen()
+	if n == 0 {
+		return ""
+	}
+
+	errors := "errors"
+	if n == 1 {
+		errors = "error"
+	}
+	return utils.FailureText.Render(fmt.Sprintf("⚠ %d new %s", n, errors)) +
+		utils.MutedText.Render(fmt.Sprintf(" (%s to view)", m.activeKeys().ErrorLog.Help().Key))
+}
@@ -5,21 +5,83 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/cache"
 	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/stats"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
 )
 
+// logRangeSize is how much of a build log is fetched at a time; the build
+// log view asks for the next range as it is scrolled towards the end
+const logRangeSize = 4 << 20
+
 // JenkinsService provides high-level Jenkins operations for the UI
 type JenkinsService struct {
-	client      *api.JenkinsClient
-	config      *config.Manager
-	configPath  string
-	connected   bool
+	config     *config.Manager
+	configPath string
+
+	// mutex guards the fields below, which the requests running in commands
+	// read and write
+	mutex       sync.Mutex
+	session     session
 	lastError   error
 	serverInfo  *api.ServerInfo
 	lastRefresh time.Time
+	state       ConnState // Set by the outcome of every request
+	lastContact time.Time // When the server last answered
+
+	cache    *cache.Cache
+	logMutex sync.Mutex
+	openLog  *cachedLog   // Cached log whose ranges are being served
+	download *logDownload // Log being fetched range by range, stored once finished
+}
+
+// session is the server requests are sent to: the client sending them and
+// the key of the server in the cache. A request keeps the session it started
+// with, even if the server is switched before it returns.
+type session struct {
+	id     int // Counts the servers switched to
+	client *api.JenkinsClient
+	server string
+}
+
+// cachedLog is a build log read from the cache
+type cachedLog struct {
+	server      string
+	jobName     string
+	buildNumber int
+	text        string
+}
+
+// logDownload is a build log fetched from the server and served a range at
+// a time. The ranges are given offsets into text; serverNext is the offset
+// into the log on the server to fetch more of it from, which also counts the
+// annotations stripped from the text.
+type logDownload struct {
+	server      string
+	jobName     string
+	buildNumber int
+	text        strings.Builder
+	serverNext  int64
+	building    bool
+}
+
+// is reports whether the download is the log of a build
+func (d *logDownload) is(server, jobName string, buildNumber int) bool {
+	return d != nil && d.server == server && d.jobName == jobName && d.buildNumber == buildNumber
+}
+
+// LogRange is a range of the console output of a build; its offsets are
+// offsets into the text of the log
+type LogRange struct {
+	Text     string
+	Next     int64 // Offset of the following range
+	Complete bool  // The range reaches the end of the log as written so far
 }
 
 // NewJenkinsService creates a new JenkinsService
@@ -39,148 +101,481 @@ func NewJenkinsService() (*JenkinsService, error) {
 		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 
+	// Keep the tokens of the servers out of the logs
+	for _, server := range configManager.Config.JenkinsServers {
+		utils.AddSecret(server.Token)
+	}
+
 	// Create the Jenkins client
 	client, err := api.NewClient(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Jenkins client: %v", err)
 	}
 
-	return &JenkinsService{
-		client:     client,
+	service := &JenkinsService{
 		config:     configManager,
 		configPath: configPath,
-		connected:  false,
-	}, nil
+	}
+	service.useClient(client, serverURL(configManager.GetCurrentServer()))
+
+	// Run without a cache rather than fail when it cannot be opened
+	settings := configManager.Config.Cache
+	if !settings.Disabled {
+		dir := settings.Dir
+		if dir == "" {
+			dir = filepath.Join(homeDir, ".jenkins-tui", "cache")
+		}
+		service.cache, err = cache.New(dir, int64(settings.MaxSize)<<20)
+		if err != nil {
+			service.setError(err)
+		}
+	}
+
+	return service, nil
 }
 
 // Connect establishes a connection to the Jenkins server
 func (s *JenkinsService) Connect() error {
+	sess := s.current()
 	ctx := context.Background()
 
 	// Get the server info to check connection
-	info, err := s.client.GetServerInfo(ctx)
+	info, err := sess.client.GetServerInfo(ctx)
 	if err != nil {
-		s.connected = false
-		s.lastError = err
+		s.setError(err)
+		s.connectFailed(sess)
 		return err
 	}
 
 	// Get the nodes
-	nodes, err := s.client.GetNodes(ctx)
+	nodes, err := sess.client.GetNodes(ctx)
 	if err != nil {
 		// Log the error but don't fail the connection
-		s.lastError = err
+		s.setError(err)
 	} else {
 		// Add nodes to server info
 		info.Nodes = nodes
 	}
 
-	s.connected = true
-	s.serverInfo = info
-	s.lastRefresh = time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sess.id == s.session.id {
+		s.serverInfo = info
+		s.lastRefresh = time.Now()
+	}
 	return nil
 }
 
-// IsConnected returns the connection status
+// IsConnected reports whether requests are sent to the server
 func (s *JenkinsService) IsConnected() bool {
-	return s.connected
+	state, _ := s.State()
+	return state.Online()
+}
+
+// HasCache reports whether finished builds are cached on disk, so that
+// recently viewed jobs and builds can be browsed without a connection
+func (s *JenkinsService) HasCache() bool {
+	return s.cache != nil
+}
+
+// serverKey identifies the current server in the cache
+func (s *JenkinsService) serverKey() string {
+	return s.current().server
+}
+
+// serverURL returns the URL of a server, which identifies it in the cache
+func serverURL(server *config.JenkinsServer) string {
+	if server == nil {
+		return ""
+	}
+	return server.URL
+}
+
+// current returns the session requests are sent with
+func (s *JenkinsService) current() session {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.session
+}
+
+// Session identifies the server requests are sent to; it changes whenever
+// the server is switched
+func (s *JenkinsService) Session() int {
+	return s.current().id
+}
+
+// setError records the last error encountered
+func (s *JenkinsService) setError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastError = err
+}
+
+// GetConfig returns the loaded application configuration
+func (s *JenkinsService) GetConfig() *config.Config {
+	return s.config.Config
+}
+
+// Servers returns the configured Jenkins servers and the name of the
+// current one
+func (s *JenkinsService) Servers() ([]config.JenkinsServer, string) {
+	current := ""
+	if server := s.config.GetCurrentServer(); server != nil {
+		current = server.Name
+	}
+	return s.config.Config.JenkinsServers, current
+}
+
+// SwitchServer makes the named server the current one for this session,
+// leaving the config file as it is. The service is connecting until the
+// next Connect.
+func (s *JenkinsService) SwitchServer(name string) error {
+	client, err := api.NewServerClient(s.configPath, name)
+	if err != nil {
+		return err
+	}
+
+	s.logMutex.Lock()
+	s.openLog = nil
+	s.download = nil
+	s.logMutex.Unlock()
+
+	s.config.Config.Current = name
+	s.useClient(client, serverURL(s.config.GetCurrentServer()))
+	return nil
 }
 
 // GetServerInfo returns information about the Jenkins server
 func (s *JenkinsService) GetServerInfo() *api.ServerInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	return s.serverInfo
 }
 
 // GetNodes returns a list of all Jenkins nodes
 func (s *JenkinsService) GetNodes() ([]api.Node, error) {
-	if !s.connected {
-		return nil, fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
-	nodes, err := s.client.GetNodes(ctx)
+	nodes, err := sess.client.GetNodes(ctx)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return nil, err
 	}
 
 	return nodes, nil
 }
 
-// GetJobs returns a list of all Jenkins jobs
+// GetExecutorUsage returns how many build executors are busy
+func (s *JenkinsService) GetExecutorUsage() (*api.ExecutorUsage, error) {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	usage, err := sess.client.GetExecutorUsage(ctx)
+	if err != nil {
+		s.setError(err)
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// GetQueue returns the builds waiting in the build queue
+func (s *JenkinsService) GetQueue() ([]api.QueueItem, error) {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	queue, err := sess.client.GetQueue(ctx)
+	if err != nil {
+		s.setError(err)
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// GetJobs returns a list of all Jenkins jobs; without a connection the
+// list last fetched is returned from the cache
 func (s *JenkinsService) GetJobs() ([]api.Job, error) {
-	if !s.connected {
-		return nil, fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		var jobs []api.Job
+		if s.cache != nil && s.cache.Get(sess.server, "", 0, "jobs", &jobs) {
+			return jobs, nil
+		}
+		return nil, err
 	}
 
 	ctx := context.Background()
-	jobs, err := s.client.GetJobs(ctx)
+	jobs, err := sess.client.GetJobs(ctx)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return nil, err
 	}
 
+	if s.cache != nil {
+		s.cache.Put(sess.server, "", 0, "jobs", jobs)
+	}
+
 	return jobs, nil
 }
 
-// GetJobDetails returns detailed information about a specific job
+// GetJobDetails returns detailed information about a specific job; without
+// a connection the details last fetched are returned from the cache
 func (s *JenkinsService) GetJobDetails(jobName string) (*api.JobDetail, error) {
-	if !s.connected {
-		return nil, fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		var jobDetail api.JobDetail
+		if s.cache != nil && s.cache.Get(sess.server, jobName, 0, "job", &jobDetail) {
+			return &jobDetail, nil
+		}
+		return nil, err
 	}
 
 	ctx := context.Background()
-	jobDetail, err := s.client.GetJobDetails(ctx, jobName)
+	jobDetail, err := sess.client.GetJobDetails(ctx, jobName)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return nil, err
 	}
 
+	if s.cache != nil {
+		s.cache.Put(sess.server, jobName, 0, "job", jobDetail)
+	}
+
 	return jobDetail, nil
 }
 
-// GetBuildDetails returns detailed information about a specific build
+// GetBuildHistory returns the builds of a job started since a time, or all
+// of them for the zero time, most recent first, fetched a page at a time up
+// to stats.MaxHistory builds. It reports whether they reach back to the
+// job's first build.
+func (s *JenkinsService) GetBuildHistory(jobName string, since time.Time) ([]api.Build, bool, error) {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return nil, false, err
+	}
+
+	ctx := context.Background()
+	var builds []api.Build
+	for len(builds) < stats.MaxHistory {
+		page, err := sess.client.GetBuildHistory(ctx, jobName, len(builds), len(builds)+stats.HistoryLimit)
+		if err != nil {
+			s.setError(err)
+			return nil, false, err
+		}
+		builds = append(builds, page...)
+
+		if len(page) < stats.HistoryLimit {
+			return builds, true, nil
+		}
+		if oldest := page[len(page)-1]; !since.IsZero() && time.UnixMilli(oldest.StartTime).Before(since) {
+			break
+		}
+	}
+
+	return builds, false, nil
+}
+
+// GetBuildDetails returns detailed information about a specific build;
+// finished builds never change and are served from the cache
 func (s *JenkinsService) GetBuildDetails(jobName string, buildNumber int) (*api.BuildDetail, error) {
-	if !s.connected {
-		return nil, fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	var cached api.BuildDetail
+	if s.cache != nil && s.cache.Get(sess.server, jobName, buildNumber, "build", &cached) {
+		return &cached, nil
+	}
+
+	if err := s.checkOnline(); err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
-	buildDetail, err := s.client.GetBuildDetails(ctx, jobName, buildNumber)
+	buildDetail, err := sess.client.GetBuildDetails(ctx, jobName, buildNumber)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return nil, err
 	}
 
+	if s.cache != nil && !buildDetail.Building {
+		s.cache.Put(sess.server, jobName, buildNumber, "build", buildDetail)
+	}
+
 	return buildDetail, nil
 }
 
-// GetBuildLog returns the console output for a specific build
+// GetBuildLog returns the console output for a specific build; the logs of
+// finished builds are cached and served from the cache
 func (s *JenkinsService) GetBuildLog(jobName string, buildNumber int) (string, error) {
-	if !s.connected {
-		return "", fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if s.cache != nil {
+		if log, ok := s.cache.Log(sess.server, jobName, buildNumber); ok {
+			return log, nil
+		}
+	}
+
+	if err := s.checkOnline(); err != nil {
+		return "", err
 	}
 
+	// The progressive API tells whether the build is still running
 	ctx := context.Background()
-	log, err := s.client.GetBuildLog(ctx, jobName, buildNumber)
+	chunk, err := sess.client.GetBuildLogRange(ctx, jobName, buildNumber, 0)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return "", err
 	}
 
-	return log, nil
+	if s.cache != nil && !chunk.Building {
+		s.cache.PutLog(sess.server, jobName, buildNumber, chunk.Text)
+	}
+
+	return chunk.Text, nil
+}
+
+// GetBuildLogRange returns the part of the console output of a build that
+// starts at offset start of its text, at most logRangeSize bytes of it. The
+// logs of finished builds are served from the cache; others are fetched
+// into memory, from where they are served a range at a time, and cached
+// once the build has finished. The log of a running build is fetched again
+// from where it stopped when it is read to the end or reloaded.
+func (s *JenkinsService) GetBuildLogRange(jobName string, buildNumber int, start int64) (*LogRange, error) {
+	sess := s.current()
+	r, from := s.downloadedLogRange(sess.server, jobName, buildNumber, start)
+	if r != nil {
+		return r, nil
+	}
+	if from == 0 {
+		if r := s.cachedLogRange(sess.server, jobName, buildNumber, start); r != nil {
+			return r, nil
+		}
+	}
+	if err := s.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	for {
+		chunk, err := sess.client.GetBuildLogRange(ctx, jobName, buildNumber, from)
+		if err != nil {
+			s.setError(err)
+			return nil, err
+		}
+		if r := s.collectLogRange(sess.server, jobName, buildNumber, start, from, chunk); r != nil {
+			return r, nil
+		}
+		// Another log was fetched in the meantime: start over
+		from = 0
+	}
+}
+
+// downloadedLogRange returns a range of the log in memory, or nil and the
+// offset of the log on the server to fetch from
+func (s *JenkinsService) downloadedLogRange(server, jobName string, buildNumber int, start int64) (*LogRange, int64) {
+	s.logMutex.Lock()
+	defer s.logMutex.Unlock()
+
+	download := s.download
+	if !download.is(server, jobName, buildNumber) {
+		return nil, 0
+	}
+	size := int64(download.text.Len())
+	switch {
+	case start > size:
+		return nil, 0
+	case download.building && (start == 0 || start == size):
+		return nil, download.serverNext
+	}
+	return logRangeAt(download.text.String(), start), 0
+}
+
+// collectLogRange adds the text fetched from offset from of the log on the
+// server to the log in memory and returns the range at start of its text,
+// or nil when the log in memory was replaced while the text was fetched.
+// The log is cached once the build has finished.
+func (s *JenkinsService) collectLogRange(server, jobName string, buildNumber int, start, from int64, chunk *api.LogChunk) *LogRange {
+	s.logMutex.Lock()
+	defer s.logMutex.Unlock()
+
+	download := s.download
+	if from == 0 {
+		download = &logDownload{server: server, jobName: jobName, buildNumber: buildNumber}
+		s.download = download
+	} else if !download.is(server, jobName, buildNumber) || download.serverNext != from {
+		return nil
+	}
+
+	download.text.WriteString(chunk.Text)
+	download.serverNext = chunk.Next
+	download.building = chunk.Building
+	if s.cache != nil && !chunk.Building {
+		s.cache.PutLog(server, jobName, buildNumber, download.text.String())
+	}
+	return logRangeAt(download.text.String(), start)
+}
+
+// cachedLogRange returns a range of a cached log, or nil when the log is not
+// cached. The first range asked for reads the log from disk and keeps it in
+// memory for the following ones.
+func (s *JenkinsService) cachedLogRange(server, jobName string, buildNumber int, start int64) *LogRange {
+	if s.cache == nil {
+		return nil
+	}
+
+	s.logMutex.Lock()
+	defer s.logMutex.Unlock()
+
+	open := s.openLog
+	if open == nil || open.server != server || open.jobName != jobName || open.buildNumber != buildNumber {
+		text, ok := s.cache.Log(server, jobName, buildNumber)
+		if !ok {
+			return nil
+		}
+		open = &cachedLog{server: server, jobName: jobName, buildNumber: buildNumber, text: text}
+		s.openLog = open
+	}
+
+	return logRangeAt(open.text, start)
+}
+
+// logRangeAt returns the range of a log's text that starts at offset start,
+// at most logRangeSize bytes of it ending on a line break
+func logRangeAt(text string, start int64) *LogRange {
+	if start >= int64(len(text)) {
+		return &LogRange{Next: start, Complete: true}
+	}
+	rest := text[start:]
+	if len(rest) <= logRangeSize {
+		return &LogRange{Text: rest, Next: int64(len(text)), Complete: true}
+	}
+
+	rest = rest[:logRangeSize]
+	if i := strings.LastIndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i+1]
+	}
+	return &LogRange{Text: rest, Next: start + int64(len(rest))}
 }
 
 // TriggerBuild starts a build for a specific job
 func (s *JenkinsService) TriggerBuild(jobName string, parameters map[string]string) error {
-	if !s.connected {
-		return fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return err
 	}
 
 	ctx := context.Background()
-	err := s.client.TriggerBuild(ctx, jobName, parameters)
+	err := sess.client.TriggerBuild(ctx, jobName, parameters)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return err
 	}
 
@@ -189,30 +584,93 @@ func (s *JenkinsService) TriggerBuild(jobName string, parameters map[string]stri
 
 // DeleteJob deletes a job from the Jenkins server
 func (s *JenkinsService) DeleteJob(jobName string) error {
-	if !s.connected {
-		return fmt.Errorf("not connected to Jenkins server")
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return err
 	}
 
 	ctx := context.Background()
-	err := s.client.DeleteJob(ctx, jobName)
+	err := sess.client.DeleteJob(ctx, jobName)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return err
 	}
 
+	s.forget(sess.server, jobName, 0)
 	return nil
 }
 
 // StopBuild stops a running build
-func (s *JenkinsService) StopBuild(jobName string, buildNumber int) error {
-	if !s.connected {
-		return fmt.Errorf("not connected to Jenkins server")
+func (s *JenkinsService) StopBuild(jobName string, buildNumber int, method api.StopMethod) error {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	err := sess.client.StopBuild(ctx, jobName, buildNumber, method)
+	if err != nil {
+		s.setError(err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBuild deletes a build of a job
+func (s *JenkinsService) DeleteBuild(jobName string, buildNumber int) error {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	err := sess.client.DeleteBuild(ctx, jobName, buildNumber)
+	if err != nil {
+		s.setError(err)
+		return err
+	}
+
+	s.forget(sess.server, jobName, buildNumber)
+	return nil
+}
+
+// forget drops what is kept of a deleted build, or of a deleted job and all
+// its builds when buildNumber is 0, so that a job created again under the
+// same name starts afresh
+func (s *JenkinsService) forget(server, jobName string, buildNumber int) {
+	s.logMutex.Lock()
+	if open := s.openLog; open != nil && open.server == server && open.jobName == jobName && (buildNumber == 0 || open.buildNumber == buildNumber) {
+		s.openLog = nil
+	}
+	if download := s.download; download != nil && download.server == server && download.jobName == jobName && (buildNumber == 0 || download.buildNumber == buildNumber) {
+		s.download = nil
+	}
+	s.logMutex.Unlock()
+
+	if s.cache != nil {
+		s.cache.Remove(server, jobName, buildNumber)
+	}
+}
+
+// Protected reports whether the current server is marked protected, which
+// makes deletions ask for the name of the job
+func (s *JenkinsService) Protected() bool {
+	server := s.config.GetCurrentServer()
+	return server != nil && server.Protected
+}
+
+// SetJobEnabled enables or disables a job
+func (s *JenkinsService) SetJobEnabled(jobName string, enabled bool) error {
+	sess := s.current()
+	if err := s.checkOnline(); err != nil {
+		return err
 	}
 
 	ctx := context.Background()
-	err := s.client.StopBuild(ctx, jobName, buildNumber)
+	err := sess.client.SetJobEnabled(ctx, jobName, enabled)
 	if err != nil {
-		s.lastError = err
+		s.setError(err)
 		return err
 	}
 
@@ -221,6 +679,8 @@ func (s *JenkinsService) StopBuild(jobName string, buildNumber int) error {
 
 // GetLastError returns the last error encountered
 func (s *JenkinsService) GetLastError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	return s.lastError
 }
 
@@ -240,5 +700,7 @@ func (s *JenkinsService) ShouldRefresh() bool {
 		refreshInterval = 30
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	return time.Since(s.lastRefresh) > time.Duration(refreshInterval)*time.Second
 }
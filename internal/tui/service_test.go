@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+)
+
+func TestLogRangeAt(t *testing.T) {
+	long := strings.Repeat("x", logRangeSize-2) + "\nyy\nzz"
+
+	tests := []struct {
+		name  string
+		text  string
+		start int64
+		want  LogRange
+	}{
+		{"whole log", "a\nb", 0, LogRange{Text: "a\nb", Next: 3, Complete: true}},
+		{"rest of the log", "a\nb", 2, LogRange{Text: "b", Next: 3, Complete: true}},
+		{"past the end", "a\nb", 5, LogRange{Next: 5, Complete: true}},
+		{"cut on a line break", long, 0, LogRange{Text: long[:logRangeSize-1], Next: logRangeSize - 1}},
+		{"after the cut", long, logRangeSize - 1, LogRange{Text: "yy\nzz", Next: int64(len(long)), Complete: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logRangeAt(tt.text, tt.start); *got != tt.want {
+				t.Errorf("range %q next %d complete %v, want %q next %d complete %v",
+					got.Text, got.Next, got.Complete, tt.want.Text, tt.want.Next, tt.want.Complete)
+			}
+		})
+	}
+}
+
+func TestLogDownloadOffsets(t *testing.T) {
+	s := &JenkinsService{}
+
+	// The server counts annotations the text leaves out
+	r := s.collectLogRange("ci", "app", 7, 0, 0, &api.LogChunk{Text: "one\ntwo\n", Next: 120, Building: true})
+	if r.Text != "one\ntwo\n" || r.Next != 8 || !r.Complete {
+		t.Fatalf("first range %+v", r)
+	}
+
+	// Ranges within the text are served from memory
+	if r, _ := s.downloadedLogRange("ci", "app", 7, 4); r == nil || r.Text != "two\n" {
+		t.Errorf("range at 4 is %+v, want the second line", r)
+	}
+
+	// Reading on or reloading a running build's log resumes at the server's
+	// offset, not at the length of the text
+	for _, start := range []int64{0, 8} {
+		if r, from := s.downloadedLogRange("ci", "app", 7, start); r != nil || from != 120 {
+			t.Errorf("range at %d fetches from %d, want 120", start, from)
+		}
+	}
+	r = s.collectLogRange("ci", "app", 7, 8, 120, &api.LogChunk{Text: "three\n", Next: 150})
+	if r.Text != "three\n" || r.Next != 14 || !r.Complete {
+		t.Errorf("range after resuming %+v", r)
+	}
+
+	// Text fetched for a log that was replaced in the meantime is dropped
+	if r := s.collectLogRange("ci", "app", 8, 0, 150, &api.LogChunk{Text: "x\n"}); r != nil {
+		t.Errorf("range of another log %+v", r)
+	}
+
+	// A finished build's log is not fetched again
+	if r, from := s.downloadedLogRange("ci", "app", 7, 0); r == nil || r.Text != "one\ntwo\nthree\n" {
+		t.Errorf("finished log fetched again from %d", from)
+	}
+	if r, from := s.downloadedLogRange("ci", "app", 7, 40); r != nil || from != 0 {
+		t.Errorf("offset past the text fetches from %d, want a new download", from)
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/stats"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// statsExportMsg reports the outcome of exporting the statistics of all jobs
+type statsExportMsg struct {
+	path   string
+	jobs   int // Jobs exported
+	failed int // Jobs whose build history could not be fetched
+	err    error
+}
+
+// ExportStats fetches the build history of every job and writes their
+// reliability statistics over all windows to a CSV file; windows the
+// history fetched does not cover are marked truncated
+func (m Model) ExportStats() tea.Cmd {
+	jobs := make([]string, len(m.jobs))
+	for i, job := range m.jobs {
+		jobs[i] = job.Name
+	}
+	dir := m.service.GetConfig().Stats.ExportDir
+
+	return request("Exporting statistics", func() tea.Msg {
+		now := time.Now()
+		reports := make(map[string][]stats.Report, len(jobs))
+		failed := 0
+		for _, job := range jobs {
+			history, whole, err := m.service.GetBuildHistory(job, time.Time{})
+			if err != nil {
+				failed++
+				continue
+			}
+			for _, window := range stats.Windows {
+				reports[job] = append(reports[job], stats.Compute(history, whole, window, now))
+			}
+		}
+		if len(reports) == 0 {
+			return statsExportMsg{failed: failed, err: fmt.Errorf("no job history could be fetched")}
+		}
+
+		path, err := utils.ExpandPath(filepath.Join(dir, fmt.Sprintf("jenkins-stats-%s.csv", now.Format("20060102-150405"))))
+		if err != nil {
+			return statsExportMsg{err: err}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return statsExportMsg{path: path, err: fmt.Errorf("failed to create directory: %v", err)}
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return statsExportMsg{path: path, err: fmt.Errorf("failed to create file: %v", err)}
+		}
+		defer file.Close()
+
+		if err := stats.WriteCSV(file, reports); err != nil {
+			return statsExportMsg{path: path, err: err}
+		}
+		return statsExportMsg{path: path, jobs: len(reports), failed: failed}
+	})
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/utils"
+)
+
+// headerHeight is the number of rows above the view: the tab bar and the
+// breadcrumbs
+const headerHeight = 2
+
+// tab is one of the top-level views shown in the tab bar
+type tab struct {
+	title string
+	view  ViewType
+}
+
+// tabs returns the tabs shown: the dashboard, the jobs, the job and build
+// in view if any, and help
+func (m Model) tabs() []tab {
+	tabs := []tab{{"Dashboard", DashboardView}, {"Jobs", JobListView}}
+	if m.selectedJob != "" {
+		tabs = append(tabs, tab{m.selectedJob, JobDetailView})
+		if m.selectedBuild > 0 {
+			tabs = append(tabs, tab{fmt.Sprintf("#%d log", m.selectedBuild), BuildLogView})
+		}
+	}
+	return append(tabs, tab{"Help", HelpView})
+}
+
+// tabView returns the view whose tab is active: the diff and the duration
+// chart belong to the job
+func (m Model) tabView() ViewType {
+	switch m.currentView {
+	case LogDiffView, BuildChartView:
+		return JobDetailView
+	}
+	return m.currentView
+}
+
+// tabBar renders the tabs, the active one highlighted
+func (m Model) tabBar() string {
+	var rendered []string
+	for _, t := range m.tabs() {
+		rendered = append(rendered, m.renderTab(t))
+	}
+	return strings.Join(rendered, " ")
+}
+
+// renderTab renders a single tab
+func (m Model) renderTab(t tab) string {
+	if t.view == m.tabView() {
+		return utils.ActiveTab.Render(t.title)
+	}
+	return utils.InactiveTab.Render(t.title)
+}
+
+// tabAt returns the tab shown at column x of the tab bar
+func (m Model) tabAt(x int) (tab, bool) {
+	start := 0
+	for _, t := range m.tabs() {
+		end := start + lipgloss.Width(m.renderTab(t))
+		if x >= start && x < end {
+			return t, true
+		}
+		start = end + 1
+	}
+	return tab{}, false
+}
+
+// openTab switches to the view of a tab
+func (m Model) openTab(t tab) (Model, tea.Cmd) {
+	switch t.view {
+	case DashboardView:
+		return m.runAction(actionDashboard)
+	case JobListView:
+		return m.runAction(actionJobs)
+	case HelpView:
+		return m.runAction(actionHelp)
+	}
+	return m.focusPane(t.view), nil
+}
@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/tui/components"
+)
+
+// watchCommandTimeout bounds how long a notification command may run
+const watchCommandTimeout = 30 * time.Second
+
+// bellDuration is how long the bell stays in the view, long enough for the
+// renderer to draw it once
+const bellDuration = 100 * time.Millisecond
+
+// Changes in the state of a watched job that are notified
+const (
+	watchStarted   = "started"
+	watchFailed    = "failed"
+	watchRecovered = "recovered"
+)
+
+// watchEvent is a change in the state of a watched job
+type watchEvent struct {
+	kind string
+	job  api.Job
+}
+
+// watchCommandMsg reports the outcome of a notification command
+type watchCommandMsg struct {
+	err error
+}
+
+// bellMsg asks for the bell to be rung, and bellDoneMsg takes it out of the
+// view again; only the bell rung last is taken out
+type bellMsg struct{}
+
+type bellDoneMsg struct {
+	id int
+}
+
+// watchEvents compares the jobs of a refresh with their previous state and
+// returns what happened to the watched ones. Jobs seen for the first time
+// have nothing to compare with and raise no event.
+func watchEvents(previous map[string]api.Job, jobs []api.Job, watched func(job string) bool) []watchEvent {
+	var events []watchEvent
+	for _, job := range jobs {
+		prev, ok := previous[job.Name]
+		if !ok || !watched(job.Name) {
+			continue
+		}
+
+		// A new build may have started and finished between two refreshes
+		newBuild := lastBuildNumber(job) != lastBuildNumber(prev)
+		if job.InProgress && (!prev.InProgress || newBuild) {
+			events = append(events, watchEvent{kind: watchStarted, job: job})
+		}
+
+		finished := prev.InProgress && !job.InProgress || newBuild && !job.InProgress
+		switch {
+		case isFailing(job.Status) && (finished || !isFailing(prev.Status)):
+			events = append(events, watchEvent{kind: watchFailed, job: job})
+		case job.Status == "success" && isFailing(prev.Status):
+			events = append(events, watchEvent{kind: watchRecovered, job: job})
+		}
+	}
+	return events
+}
+
+// lastBuildNumber returns the number of a job's last build, 0 if it has none
+func lastBuildNumber(job api.Job) int {
+	if job.LastBuild == nil {
+		return 0
+	}
+	return job.LastBuild.Number
+}
+
+// isFailing reports whether a job status is a failed or unstable build
+func isFailing(status string) bool {
+	return status == "failure" || status == "unstable"
+}
+
+// message describes the event for a toast
+func (e watchEvent) message() string {
+	build := ""
+	if number := lastBuildNumber(e.job); number > 0 {
+		build = fmt.Sprintf(" #%d", number)
+	}
+
+	switch e.kind {
+	case watchStarted:
+		return fmt.Sprintf("▶ %s%s started", e.job.Name, build)
+	case watchFailed:
+		return fmt.Sprintf("✗ %s%s %s", e.job.Name, build, e.job.Status)
+	}
+	return fmt.Sprintf("✓ %s%s recovered", e.job.Name, build)
+}
+
+// level returns the toast colour of the event
+func (e watchEvent) level() components.ToastLevel {
+	switch e.kind {
+	case watchFailed:
+		return components.ToastError
+	case watchRecovered:
+		return components.ToastSuccess
+	}
+	return components.ToastInfo
+}
+
+// ringBell rings the terminal bell. The program owns the terminal, so the
+// bell is drawn with the view rather than written from a command.
+func ringBell() tea.Cmd {
+	return func() tea.Msg { return bellMsg{} }
+}
+
+// ring puts the bell in the view until the renderer has drawn it
+func (m Model) ring() (Model, tea.Cmd) {
+	m.bellID++
+	m.ringing = true
+
+	id := m.bellID
+	return m, tea.Tick(bellDuration, func(time.Time) tea.Msg {
+		return bellDoneMsg{id: id}
+	})
+}
+
+// bellDone takes the bell rung last out of the view
+func (m Model) bellDone(msg bellDoneMsg) Model {
+	if msg.id == m.bellID {
+		m.ringing = false
+	}
+	return m
+}
+
+// runWatchCommand runs the user's notification command through the shell,
+// passing the event in JENKINS_* environment variables
+func runWatchCommand(command, server string, e watchEvent) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), watchCommandTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Env = append(os.Environ(),
+			"JENKINS_SERVER="+server,
+			"JENKINS_JOB="+e.job.Name,
+			"JENKINS_JOB_URL="+e.job.URL,
+			"JENKINS_BUILD="+strconv.Itoa(lastBuildNumber(e.job)),
+			"JENKINS_EVENT="+e.kind,
+			"JENKINS_RESULT="+e.job.Status,
+		)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return watchCommandMsg{err: fmt.Errorf("%v: %s", err, output)}
+		}
+		return watchCommandMsg{}
+	}
+}
+
+// isWatched reports whether a job of the current server is on the watchlist
+func (m Model) isWatched(job string) bool {
+	return m.state.IsWatched(m.service.serverKey(), job)
+}
+
+// notify rings the bell and runs the configured command for an event
+func (m Model) notify(e watchEvent) []tea.Cmd {
+	settings := m.service.GetConfig().Watch
+
+	var cmds []tea.Cmd
+	if !settings.NoBell {
+		cmds = append(cmds, ringBell())
+	}
+	if settings.Command != "" {
+		cmds = append(cmds, runWatchCommand(settings.Command, m.service.serverKey(), e))
+	}
+	return cmds
+}
+
+// showJobs fills the job list and the dashboard from the jobs of the last
+// refresh
+func (m Model) showJobs() Model {
+	var jobItems []components.JobListItem
+	var dashboardJobs []components.DashboardJob
+	for _, job := range m.jobs {
+		jobItem := components.JobListItem{
+			Name:    job.Name,
+			Status:  string(job.Status),
+			JobDesc: job.Description,
+			URL:     job.URL,
+			Watched: m.isWatched(job.Name),
+			Running: job.InProgress,
+			Health:  job.Health,
+		}
+		if build := job.LastSuccess; build != nil {
+			jobItem.LastSuccess = time.UnixMilli(build.StartTime)
+		}
+		if build := job.LastFailure; build != nil {
+			jobItem.LastFailure = time.UnixMilli(build.StartTime)
+		}
+
+		dashboardJob := components.DashboardJob{
+			Name:       job.Name,
+			Status:     job.Status,
+			InProgress: job.InProgress,
+			Watched:    jobItem.Watched,
+		}
+		if build := job.LastBuild; build != nil {
+			jobItem.LastBuild = time.UnixMilli(build.StartTime)
+			jobItem.BuildNumber = build.Number
+			jobItem.LastDuration = time.Duration(build.Duration) * time.Millisecond
+			dashboardJob.LastBuild = build.Number
+			dashboardJob.BuildStatus = build.Status
+			dashboardJob.Started = time.UnixMilli(build.StartTime)
+			dashboardJob.Duration = time.Duration(build.Duration) * time.Millisecond
+			if build.EstimatedDuration > 0 {
+				dashboardJob.Estimate = time.Duration(build.EstimatedDuration) * time.Millisecond
+			}
+		}
+
+		jobItems = append(jobItems, jobItem)
+		dashboardJobs = append(dashboardJobs, dashboardJob)
+	}
+
+	m.jobList = m.jobList.WithJobs(jobItems)
+	m.dashboard = m.dashboard.WithJobs(dashboardJobs)
+	m.jobDetail = m.jobDetail.WithWatched(m.isWatched(m.selectedJob))
+	return m
+}
+
+// toggleWatched adds the selected job to the watchlist or removes it
+func (m Model) toggleWatched() Model {
+	job := m.selectedJob
+	if m.currentView == JobListView {
+		selected := m.jobList.GetSelected()
+		if selected == nil {
+			return m
+		}
+		job = selected.Name
+	}
+	if job == "" {
+		return m
+	}
+
+	if m.state.ToggleWatched(m.service.serverKey(), job) {
+		m.statusMessage = fmt.Sprintf("Watching %s", job)
+	} else {
+		m.statusMessage = fmt.Sprintf("Stopped watching %s", job)
+	}
+	if err := m.state.Save(); err != nil {
+		m.errorMsg = err.Error()
+	}
+
+	return m.showJobs()
+}
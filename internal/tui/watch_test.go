@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/api"
+)
+
+// watchedJob returns the state of job "app" at its last build
+func watchedJob(build int, status string, inProgress bool) api.Job {
+	job := api.Job{Name: "app", Status: status, InProgress: inProgress}
+	if build > 0 {
+		job.LastBuild = &api.Build{Number: build}
+	}
+	return job
+}
+
+func TestWatchEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		prev api.Job
+		job  api.Job
+		want []string
+	}{
+		{"unchanged", watchedJob(3, "success", false), watchedJob(3, "success", false), nil},
+		{"started", watchedJob(3, "success", false), watchedJob(4, "success", true), []string{watchStarted}},
+		{"first build started", watchedJob(0, "notbuilt", false), watchedJob(1, "notbuilt", true), []string{watchStarted}},
+		{"still running", watchedJob(4, "success", true), watchedJob(4, "success", true), nil},
+		{"failed", watchedJob(4, "success", true), watchedJob(4, "failure", false), []string{watchFailed}},
+		{"unstable", watchedJob(4, "success", true), watchedJob(4, "unstable", false), []string{watchFailed}},
+		{"failed again", watchedJob(4, "failure", true), watchedJob(4, "failure", false), []string{watchFailed}},
+		{"recovered", watchedJob(4, "failure", true), watchedJob(4, "success", false), []string{watchRecovered}},
+		{"succeeded", watchedJob(4, "success", true), watchedJob(4, "success", false), nil},
+		{"started and failed between refreshes", watchedJob(3, "success", false), watchedJob(4, "failure", false), []string{watchFailed}},
+		{"started and recovered between refreshes", watchedJob(3, "failure", false), watchedJob(4, "success", false), []string{watchRecovered}},
+		{"finished and the next one started", watchedJob(3, "success", true), watchedJob(4, "failure", true), []string{watchStarted, watchFailed}},
+		{"failing build still running", watchedJob(4, "failure", true), watchedJob(4, "failure", true), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := map[string]api.Job{"app": tt.prev}
+			var got []string
+			for _, e := range watchEvents(previous, []api.Job{tt.job}, func(string) bool { return true }) {
+				got = append(got, e.kind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchEventsSkipsJobs(t *testing.T) {
+	previous := map[string]api.Job{"app": watchedJob(3, "success", true)}
+	jobs := []api.Job{watchedJob(3, "failure", false), {Name: "web", Status: "failure"}}
+
+	if events := watchEvents(previous, jobs, func(string) bool { return false }); len(events) != 0 {
+		t.Errorf("unwatched job notified: %+v", events)
+	}
+	if events := watchEvents(previous, jobs, func(string) bool { return true }); len(events) != 1 || events[0].job.Name != "app" {
+		t.Errorf("events %+v, want only app failing, web is seen for the first time", events)
+	}
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ansiReset ends every SGR sequence so colours never bleed into the UI
+const ansiReset = "\x1b[0m"
+
+// StripANSI removes all escape sequences and control characters from a line
+func StripANSI(line string) string {
+	plain, _ := scanANSI(line, false)
+	return plain
+}
+
+// SanitizeANSI keeps the colour (SGR) sequences of a line, such as those
+// emitted by the Jenkins AnsiColor plugin, and drops everything that could
+// move the cursor or otherwise corrupt the UI. It reports whether the line
+// carries any colour.
+func SanitizeANSI(line string) (string, bool) {
+	return scanANSI(line, true)
+}
+
+// scanANSI walks a line, copying text and optionally SGR sequences.
+// Carriage returns keep only the text written after the last one, as a
+// terminal would show for progress bars.
+func scanANSI(line string, keepSGR bool) (string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+
+	// Fast path for the common case of plain text
+	if !strings.ContainsAny(line, "\x1b\x00\x07\x08\x0b\x0c\x7f") {
+		return line, false
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(line))
+	hasSGR := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\x1b' && i+1 < len(line) && line[i+1] == '[':
+			// CSI: parameters and intermediates up to a final byte in @-~
+			j := i + 2
+			for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+				j++
+			}
+			if j < len(line) && line[j] == 'm' && keepSGR {
+				sb.WriteString(line[i : j+1])
+				hasSGR = true
+			}
+			i = j
+
+		case c == '\x1b' && i+1 < len(line) && line[i+1] == ']':
+			// OSC: terminated by BEL or ST (ESC \)
+			j := i + 2
+			for j < len(line) && line[j] != '\x07' && !(line[j] == '\x1b' && j+1 < len(line) && line[j+1] == '\\') {
+				j++
+			}
+			if j < len(line) && line[j] == '\x1b' {
+				j++
+			}
+			i = j
+
+		case c == '\x1b':
+			// Two-byte escape such as ESC 7; skip the following byte
+			i++
+
+		case c == '\t' || c >= 0x20 && c != 0x7f:
+			sb.WriteByte(c)
+		}
+	}
+
+	if hasSGR {
+		sb.WriteString(ansiReset)
+	}
+	return sb.String(), hasSGR
+}
+
+// ANSIToHTML converts lines containing SGR colour sequences into a
+// standalone HTML document that preserves their colours
+func ANSIToHTML(lines []string, title string) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>")
+	sb.WriteString(html.EscapeString(title))
+	sb.WriteString("</title>\n<style>body{background:#1e1e1e;color:#d4d4d4;}" +
+		"pre{font-family:monospace;white-space:pre-wrap;}</style>\n</head>\n<body>\n<pre>")
+
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		writeHTMLLine(&sb, line)
+	}
+
+	sb.WriteString("</pre>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+// sgrState is the text attribute state while converting SGR sequences
+type sgrState struct {
+	fg, bg                  string
+	bold, italic, underline bool
+}
+
+// css renders the state as an inline style, empty when unstyled
+func (s sgrState) css() string {
+	var parts []string
+	if s.fg != "" {
+		parts = append(parts, "color:"+s.fg)
+	}
+	if s.bg != "" {
+		parts = append(parts, "background:"+s.bg)
+	}
+	if s.bold {
+		parts = append(parts, "font-weight:bold")
+	}
+	if s.italic {
+		parts = append(parts, "font-style:italic")
+	}
+	if s.underline {
+		parts = append(parts, "text-decoration:underline")
+	}
+	return strings.Join(parts, ";")
+}
+
+// writeHTMLLine converts one line, opening a span whenever the style changes
+func writeHTMLLine(sb *strings.Builder, line string) {
+	var state sgrState
+	open := false
+	text := 0
+
+	flush := func(end int) {
+		if end > text {
+			sb.WriteString(html.EscapeString(line[text:end]))
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		if line[i] != '\x1b' || i+1 >= len(line) || line[i+1] != '[' {
+			continue
+		}
+		j := i + 2
+		for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+			j++
+		}
+		if j >= len(line) {
+			break
+		}
+
+		flush(i)
+		if line[j] == 'm' {
+			state = applySGR(state, line[i+2:j])
+			if open {
+				sb.WriteString("</span>")
+				open = false
+			}
+			if css := state.css(); css != "" {
+				sb.WriteString(`<span style="` + css + `">`)
+				open = true
+			}
+		}
+		i = j
+		text = j + 1
+	}
+
+	flush(len(line))
+	if open {
+		sb.WriteString("</span>")
+	}
+}
+
+// applySGR updates the state with the parameters of one SGR sequence
+func applySGR(state sgrState, params string) sgrState {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		n, _ := strconv.Atoi(codes[i])
+		switch {
+		case n == 0:
+			state = sgrState{}
+		case n == 1:
+			state.bold = true
+		case n == 3:
+			state.italic = true
+		case n == 4:
+			state.underline = true
+		case n == 22:
+			state.bold = false
+		case n == 23:
+			state.italic = false
+		case n == 24:
+			state.underline = false
+		case n >= 30 && n <= 37:
+			state.fg = xtermColor(n - 30)
+		case n >= 90 && n <= 97:
+			state.fg = xtermColor(n - 90 + 8)
+		case n == 39:
+			state.fg = ""
+		case n >= 40 && n <= 47:
+			state.bg = xtermColor(n - 40)
+		case n >= 100 && n <= 107:
+			state.bg = xtermColor(n - 100 + 8)
+		case n == 49:
+			state.bg = ""
+		case n == 38 || n == 48:
+			var color string
+			color, i = extendedColor(codes, i)
+			if n == 38 {
+				state.fg = color
+			} else {
+				state.bg = color
+			}
+		}
+	}
+	return state
+}
+
+// extendedColor parses a 256-colour (5;n) or truecolour (2;r;g;b) argument
+// starting after codes[i] and returns the colour and the last index used
+func extendedColor(codes []string, i int) (string, int) {
+	arg := func(k int) int {
+		if k < len(codes) {
+			n, _ := strconv.Atoi(codes[k])
+			return n
+		}
+		return 0
+	}
+
+	switch arg(i + 1) {
+	case 5:
+		return xtermColor(arg(i + 2)), i + 2
+	case 2:
+		return fmt.Sprintf("#%02x%02x%02x", arg(i+2), arg(i+3), arg(i+4)), i + 4
+	}
+	return "", i + 1
+}
+
+// xtermColor returns the hex value of an xterm 256-colour palette entry
+func xtermColor(n int) string {
+	base := []string{
+		"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+		"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+	}
+
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return base[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		gray := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
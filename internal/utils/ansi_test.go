@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"plain", "hello world", "hello world"},
+		{"colour", "\x1b[31mred\x1b[0m text", "red text"},
+		{"256 colour", "\x1b[38;5;208morange\x1b[m", "orange"},
+		{"cursor movement", "\x1b[2Kcleared\x1b[1A", "cleared"},
+		{"osc title", "\x1b]0;title\x07after", "after"},
+		{"osc hyperlink", "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"two byte escape", "\x1b7saved\x1b8", "saved"},
+		{"progress bar", "10%\r50%\r100%", "100%"},
+		{"trailing carriage return", "done\r", "done"},
+		{"control characters", "a\x00b\x08c\x7fd", "abcd"},
+		{"tabs kept", "a\tb", "a\tb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripANSI(tt.line); got != tt.want {
+				t.Errorf("StripANSI(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeANSI(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		want        string
+		wantColored bool
+	}{
+		{"plain", "hello", "hello", false},
+		{"colour kept and reset", "\x1b[1;32mok", "\x1b[1;32mok\x1b[0m", true},
+		{"cursor movement dropped", "\x1b[2K\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m\x1b[0m", true},
+		{"only movement", "\x1b[2Kline", "line", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, colored := SanitizeANSI(tt.line)
+			if got != tt.want || colored != tt.wantColored {
+				t.Errorf("SanitizeANSI(%q) = %q, %v; want %q, %v", tt.line, got, colored, tt.want, tt.wantColored)
+			}
+		})
+	}
+}
+
+func TestANSIToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string // The line as it appears inside <pre>
+	}{
+		{"plain", "a < b & c", "a &lt; b &amp; c"},
+		{"foreground", "\x1b[31mred\x1b[0m plain", `<span style="color:#cd0000">red</span> plain`},
+		{"bright and bold", "\x1b[1;92mok", `<span style="color:#00ff00;font-weight:bold">ok</span>`},
+		{"256 colour", "\x1b[38;5;196mx", `<span style="color:#ff0000">x</span>`},
+		{"grey ramp", "\x1b[38;5;232mx", `<span style="color:#080808">x</span>`},
+		{"truecolour background", "\x1b[48;2;1;2;3mx", `<span style="background:#010203">x</span>`},
+		{"attributes switched off", "\x1b[1;4mab\x1b[22mc", `<span style="font-weight:bold;text-decoration:underline">ab</span><span style="text-decoration:underline">c</span>`},
+		{"default colour", "\x1b[31ma\x1b[39mb", `<span style="color:#cd0000">a</span>b`},
+		{"other sequences dropped", "\x1b[2Kx", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := ANSIToHTML([]string{tt.line}, "job #1")
+			start := strings.Index(out, "<pre>") + len("<pre>")
+			end := strings.Index(out, "</pre>")
+			if got := out[start:end]; got != tt.want {
+				t.Errorf("ANSIToHTML(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+
+	out := ANSIToHTML([]string{"one", "two"}, "<job> #1")
+	if !strings.Contains(out, "<title>&lt;job&gt; #1</title>") || !strings.Contains(out, "<pre>one\ntwo</pre>") {
+		t.Errorf("unexpected document %q", out)
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+// defaultFailureSignatures recognise common causes of failed builds. They
+// are tried in order, so more specific signatures come first.
+var defaultFailureSignatures = []config.FailureSignature{
+	{Name: "Out of memory", Pattern: `OutOfMemoryError|JavaScript heap out of memory|\bOOMKilled\b|Cannot allocate memory|exit code 137\b|^Killed$`},
+	{Name: "Timeout", Pattern: `Timeout has been exceeded|Build timed out|context deadline exceeded|(?i)(error|exception|fatal).*\btimed out\b|(?i)\btimed out\b.*(error|exception|fatal)`},
+	{Name: "Compiler error", Pattern: `\.(java|kt|scala|groovy):(\[\d+,\d+\]|\d+: error)|\.(c|cc|cpp|h|hpp|rs|cs|swift):\d+(:\d+)?: (fatal )?error|^\S+\.go:\d+:\d+: |error TS\d+:|^error\[E\d+\]|COMPILATION ERROR`},
+	{Name: "Test failure", Pattern: `Tests run: \d+, Failures: ([1-9]|\d+, Errors: [1-9])|^--- FAIL: |^FAIL\s|\b\d+ failing\b|\[\s*FAILED\s*\]|There (was|were) \d+ failures?|^FAILED |> Task \S*[tT]est\S* FAILED`},
+	{Name: "Stack trace", Pattern: `^Exception in thread |^(Caused by: )?([\w$]+\.)+[\w$]*(Exception|Error)(: |$)|^Traceback \(most recent call last\)|^panic: |^goroutine \d+ \[running\]|^\s+at [\w$.<>]+\(`},
+	{Name: "Error step", Pattern: `^ERROR: |^\[ERROR\] |^npm ERR! |^FATAL: |^(error|Error): `},
+}
+
+// maxFailureHits bounds the summary of logs that fail on almost every line
+const maxFailureHits = 500
+
+// FailureHit is a run of adjacent log lines matching the same signature
+type FailureHit struct {
+	Signature string
+	Line      int    // Index of the first matching line
+	Text      string // Text of the first matching line
+	Count     int    // Number of matching lines in the run
+}
+
+// failureSignature is a compiled FailureSignature
+type failureSignature struct {
+	name   string
+	re     *regexp.Regexp
+	filter literalFilter
+}
+
+// FailureAnalyzer scans build logs for known failure signatures
+type FailureAnalyzer struct {
+	signatures []failureSignature
+}
+
+// NewFailureAnalyzer compiles the configured signatures, followed by the
+// built-in ones unless they are disabled
+func NewFailureAnalyzer(cfg config.FailureAnalysis) (*FailureAnalyzer, error) {
+	signatures := append([]config.FailureSignature{}, cfg.Signatures...)
+	if !cfg.NoDefaultSignatures {
+		signatures = append(signatures, defaultFailureSignatures...)
+	}
+
+	a := &FailureAnalyzer{}
+	for i, sig := range signatures {
+		if sig.Name == "" {
+			return nil, fmt.Errorf("failure signature %d: missing name", i+1)
+		}
+		re, err := regexp.Compile(sig.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failure signature %q: invalid pattern %q: %v", sig.Name, sig.Pattern, err)
+		}
+		a.signatures = append(a.signatures, failureSignature{name: sig.Name, re: re, filter: newLiteralFilter(sig.Pattern)})
+	}
+
+	return a, nil
+}
+
+// Analyze returns the failure signatures found in plain-text log lines, in
+// log order. Each line counts for the first signature it matches, and lines
+// next to each other with the same signature, such as the frames of a
+// stack trace, are reported as one hit.
+func (a *FailureAnalyzer) Analyze(lines []string) []FailureHit {
+	return a.AnalyzeFrom(nil, lines, 0)
+}
+
+// AnalyzeFrom continues an analysis that found hits in lines[:from] with
+// the lines appended since, so that a log can be analysed as it arrives
+func (a *FailureAnalyzer) AnalyzeFrom(hits []FailureHit, lines []string, from int) []FailureHit {
+	return a.AnalyzeRange(hits, lines[from:], from)
+}
+
+// AnalyzeRange continues an analysis with lines that start at line first
+// of the log, so that a log can be analysed a range at a time without
+// keeping the lines analysed before
+func (a *FailureAnalyzer) AnalyzeRange(hits []FailureHit, lines []string, first int) []FailureHit {
+	last := -2
+	if n := len(hits); n > 0 {
+		last = hits[n-1].Line + hits[n-1].Count - 1
+	}
+
+	for j, line := range lines {
+		i := first + j
+		for _, sig := range a.signatures {
+			// Most lines contain none of a signature's literals
+			if !sig.filter.mayMatch(line) || !sig.re.MatchString(line) {
+				continue
+			}
+			if n := len(hits); n > 0 && last == i-1 && hits[n-1].Signature == sig.name {
+				hits[n-1].Count++
+			} else {
+				if len(hits) == maxFailureHits {
+					return hits
+				}
+				hits = append(hits, FailureHit{Signature: sig.name, Line: i, Text: strings.TrimSpace(line), Count: 1})
+			}
+			last = i
+			break
+		}
+	}
+
+	return hits
+}
+
+// SummarizeFailures counts hits per signature in order of first appearance,
+// e.g. "Compiler error ×2 · Test failure"
+func SummarizeFailures(hits []FailureHit) string {
+	var names []string
+	counts := map[string]int{}
+	for _, hit := range hits {
+		if counts[hit.Signature] == 0 {
+			names = append(names, hit.Signature)
+		}
+		counts[hit.Signature]++
+	}
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name
+		if counts[name] > 1 {
+			parts[i] = fmt.Sprintf("%s ×%d", name, counts[name])
+		}
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+func TestFailureSignatures(t *testing.T) {
+	analyzer, err := NewFailureAnalyzer(config.FailureAnalysis{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line string
+		want string // Signature matched, empty for none
+	}{
+		{"java.lang.OutOfMemoryError: Java heap space", "Out of memory"},
+		{"Killed", "Out of memory"},
+		{"script returned exit code 137", "Out of memory"},
+		{"Build timed out (after 10 minutes). Marking the build as failed.", "Timeout"},
+		{"Error: context deadline exceeded", "Timeout"},
+		{"ERROR: connection to registry timed out", "Timeout"},
+		{"java.net.SocketTimeoutException: Read timed out", "Timeout"},
+		{"Request timed out, giving up: FATAL", "Timeout"},
+		{"Waiting for the lock timed out after 5s, retrying", ""},
+		{"[INFO] 3 requests timed out and were retried", ""},
+		{"src/Main.java:[12,8] cannot find symbol", "Compiler error"},
+		{"main.go:10:2: undefined: foo", "Compiler error"},
+		{"error TS2304: Cannot find name 'x'.", "Compiler error"},
+		{"Tests run: 12, Failures: 2, Errors: 0, Skipped: 0", "Test failure"},
+		{"Tests run: 12, Failures: 0, Errors: 0, Skipped: 0", ""},
+		{"--- FAIL: TestParse (0.00s)", "Test failure"},
+		{"  3 failing", "Test failure"},
+		{"Exception in thread \"main\" java.lang.IllegalStateException", "Stack trace"},
+		{"\tat com.example.App.main(App.java:10)", "Stack trace"},
+		{"panic: runtime error: index out of range", "Stack trace"},
+		{"ERROR: script returned exit code 1", "Error step"},
+		{"npm ERR! code ELIFECYCLE", "Error step"},
+		{"[INFO] BUILD SUCCESS", ""},
+		{"Finished: SUCCESS", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got := ""
+			if hits := analyzer.Analyze([]string{tt.line}); len(hits) > 0 {
+				got = hits[0].Signature
+			}
+			if got != tt.want {
+				t.Errorf("got signature %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMergesRuns(t *testing.T) {
+	analyzer, err := NewFailureAnalyzer(config.FailureAnalysis{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []string{
+		"[INFO] Compiling",
+		"Exception in thread \"main\" java.lang.NullPointerException",
+		"\tat com.example.App.run(App.java:20)",
+		"\tat com.example.App.main(App.java:10)",
+		"[INFO] Cleaning up",
+		"ERROR: script returned exit code 1",
+	}
+	want := []FailureHit{
+		{Signature: "Stack trace", Line: 1, Text: lines[1], Count: 3},
+		{Signature: "Error step", Line: 5, Text: lines[5], Count: 1},
+	}
+	if hits := analyzer.Analyze(lines); !reflect.DeepEqual(hits, want) {
+		t.Errorf("got %+v, want %+v", hits, want)
+	}
+
+	// Analysing the log as it arrives finds the same hits
+	var hits []FailureHit
+	from := 0
+	for _, n := range []int{2, 3, 6} {
+		hits = analyzer.AnalyzeFrom(hits, lines[:n], from)
+		from = n
+	}
+	if !reflect.DeepEqual(hits, want) {
+		t.Errorf("incremental: got %+v, want %+v", hits, want)
+	}
+
+	// So does analysing it a range at a time, with a run across two ranges
+	hits = nil
+	from = 0
+	for _, n := range []int{3, 5, 6} {
+		hits = analyzer.AnalyzeRange(hits, lines[from:n], from)
+		from = n
+	}
+	if !reflect.DeepEqual(hits, want) {
+		t.Errorf("by range: got %+v, want %+v", hits, want)
+	}
+
+	if got := SummarizeFailures(append(want, want[0])); got != "Stack trace ×2 · Error step" {
+		t.Errorf("summary %q", got)
+	}
+}
+
+func TestFailureAnalyzerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.FailureAnalysis
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "custom signature first",
+			cfg:  config.FailureAnalysis{Signatures: []config.FailureSignature{{Name: "Registry", Pattern: `registry .* timed out`}}},
+			line: "ERROR: registry push timed out",
+			want: "Registry",
+		},
+		{
+			name: "defaults disabled",
+			cfg:  config.FailureAnalysis{NoDefaultSignatures: true},
+			line: "ERROR: script returned exit code 1",
+		},
+		{
+			name:    "missing name",
+			cfg:     config.FailureAnalysis{Signatures: []config.FailureSignature{{Pattern: `x`}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			cfg:     config.FailureAnalysis{Signatures: []config.FailureSignature{{Name: "Broken", Pattern: `(`}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer, err := NewFailureAnalyzer(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := ""
+			if hits := analyzer.Analyze([]string{tt.line}); len(hits) > 0 {
+				got = hits[0].Signature
+			}
+			if got != tt.want {
+				t.Errorf("got signature %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy match scoring
+const (
+	fuzzyMatchScore  = 16 // Every matched character
+	fuzzyBoundary    = 12 // Matched character starting a word
+	fuzzyConsecutive = 8  // Matched character following the previous match
+	fuzzyMaxGap      = 8  // Most a gap between two matches costs
+)
+
+// FuzzyMatch reports whether the characters of pattern appear in text in
+// order, ignoring case. It scores the match, higher being better, favouring
+// characters that start words or follow each other, and returns the rune
+// positions of the matched characters in text.
+func FuzzyMatch(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	lower := make([]rune, len(t))
+	for i, r := range t {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Find where the first complete match ends
+	end, pi := -1, 0
+	for i := 0; i < len(lower) && pi < len(p); i++ {
+		if lower[i] == p[pi] {
+			pi++
+			end = i
+		}
+	}
+	if pi < len(p) {
+		return 0, nil, false
+	}
+
+	// Walk back from there to the shortest match ending at the same place
+	start := end
+	pi = len(p) - 1
+	for i := end; i >= 0; i-- {
+		if lower[i] == p[pi] {
+			if pi == 0 {
+				start = i
+				break
+			}
+			pi--
+		}
+	}
+
+	positions := make([]int, 0, len(p))
+	pi = 0
+	for i := start; i <= end && pi < len(p); i++ {
+		if lower[i] == p[pi] {
+			positions = append(positions, i)
+			pi++
+		}
+	}
+
+	// A run of consecutive matches keeps the bonus of its first character,
+	// so that a whole word beats the same letters scattered over words
+	score, runBonus := 0, 0
+	for k, pos := range positions {
+		bonus := 0
+		if pos == 0 || isWordStart(t[pos-1], t[pos]) {
+			bonus = fuzzyBoundary
+		}
+		if k > 0 {
+			if gap := pos - positions[k-1] - 1; gap == 0 {
+				bonus = max(bonus, runBonus, fuzzyConsecutive)
+			} else {
+				score -= min(gap, fuzzyMaxGap)
+			}
+		}
+		if k == 0 || pos != positions[k-1]+1 {
+			runBonus = bonus
+		}
+		score += fuzzyMatchScore + bonus
+	}
+
+	// Prefer shorter texts among equally good matches
+	score -= len(t) / 16
+	return score, positions, true
+}
+
+// isWordStart reports whether r starts a word when it follows prev
+func isWordStart(prev, r rune) bool {
+	switch prev {
+	case '/', '-', '_', ' ', '.', '#', ':':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatchPositions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		want    []int // Rune positions, nil when there is no match
+		ok      bool
+	}{
+		{"", "build", nil, true},
+		{"bld", "build", []int{0, 3, 4}, true},
+		{"APP", "team/app", []int{5, 6, 7}, true},
+		{"ab", "a-a-b", []int{2, 4}, true},
+		{"é", "café", []int{3}, true},
+		{"xyz", "app", nil, false},
+		{"ppa", "app", nil, false},
+		{"apps", "app", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" in "+tt.text, func(t *testing.T) {
+			_, positions, ok := FuzzyMatch(tt.pattern, tt.text)
+			if ok != tt.ok || !reflect.DeepEqual(positions, tt.want) {
+				t.Errorf("positions %v ok %v, want %v ok %v", positions, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		better, worse string
+	}{
+		{"consecutive letters", "dep", "deploy", "develop"},
+		{"start of a word", "ci", "team/ci-build", "docs-inline"},
+		{"camel case word", "bs", "buildStatus", "jobs"},
+		{"whole word over scattered letters", "app", "team/app", "a-p-p-e"},
+		{"shorter text", "app", "app", "app-integration-tests-nightly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better, _, ok1 := FuzzyMatch(tt.pattern, tt.better)
+			worse, _, ok2 := FuzzyMatch(tt.pattern, tt.worse)
+			if !ok1 || !ok2 {
+				t.Fatalf("%q does not match both %q and %q", tt.pattern, tt.better, tt.worse)
+			}
+			if better <= worse {
+				t.Errorf("%q scores %d in %q, not more than %d in %q", tt.pattern, better, tt.better, worse, tt.worse)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -18,21 +23,22 @@ func CountFreeNodes(nodes []api.Node) int {
 	return count
 }
 
-// GetStatusColor returns the appropriate color for a job status
+// GetStatusColor returns the current theme's color for a job status
 func GetStatusColor(status string) string {
+	t := CurrentTheme()
 	switch strings.ToLower(status) {
 	case "success":
-		return "42" // Green
+		return t.Success
 	case "failed", "failure":
-		return "196" // Red
+		return t.Failure
+	case "unstable":
+		return t.Warning
 	case "aborted":
-		return "208" // Orange
+		return t.Aborted
 	case "running":
-		return "33" // Blue
-	case "waiting":
-		return "247" // Gray
+		return t.Running
 	default:
-		return "247" // Gray for unknown status
+		return t.Unknown
 	}
 }
 
@@ -43,24 +49,57 @@ func ColorizeLogOutput(log string) string {
 
 	// Process each line
 	for i, line := range lines {
-		// Colorize error lines
-		if strings.Contains(strings.ToLower(line), "error") ||
-			strings.Contains(strings.ToLower(line), "exception") ||
-			strings.Contains(strings.ToLower(line), "failed") {
-			lines[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(line)
-		} else if strings.Contains(strings.ToLower(line), "warning") {
-			// Colorize warning lines
-			lines[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render(line)
-		} else if strings.HasPrefix(line, "+") || strings.HasPrefix(line, ">") {
-			// Colorize command execution lines
-			lines[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Render(line)
-		} else if strings.Contains(strings.ToLower(line), "success") ||
-			strings.Contains(strings.ToLower(line), "passed") ||
-			strings.Contains(strings.ToLower(line), "completed") {
-			// Colorize success lines
-			lines[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render(line)
-		}
+		lines[i] = ColorizeLogLine(line)
 	}
 
 	return strings.Join(lines, "\n")
 }
+
+// ColorizeLogLine colors a single log line with the highlighting rules.
+// Lines that already carry ANSI colours keep them where they are set.
+func ColorizeLogLine(line string) string {
+	if sanitized, colored := SanitizeANSI(line); colored {
+		return activeHighlighter.RenderANSI(sanitized)
+	}
+	return activeHighlighter.Render(StripANSI(line))
+}
+
+// LogLineStyle returns the style for a plain log line and whether it has one
+func LogLineStyle(line string) (lipgloss.Style, bool) {
+	return activeHighlighter.LineStyle(line)
+}
+
+// ExpandPath resolves a leading ~ to the user's home directory
+func ExpandPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("no file name given")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %v", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	return filepath.Abs(path)
+}
+
+// OpenURL opens a link in the default browser without waiting for it
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %v", url, err)
+	}
+	go cmd.Wait()
+	return nil
+}
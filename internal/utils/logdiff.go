@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+// defaultNormalizeRules mask content that differs between otherwise
+// identical builds
+var defaultNormalizeRules = []config.NormalizeRule{
+	// Timestamps: ISO 8601, syslog style and bare times of day
+	{Pattern: `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}([.,]\d+)?(Z|[+-]\d{2}:?\d{2})?`, Replace: "<timestamp>"},
+	{Pattern: `\b(Mon|Tue|Wed|Thu|Fri|Sat|Sun),? \w{3} \d{1,2},? (\d{4} )?\d{1,2}:\d{2}:\d{2}( [AP]M)?`, Replace: "<timestamp>"},
+	{Pattern: `\b\d{1,2}:\d{2}:\d{2}([.,]\d+)?\b`, Replace: "<time>"},
+	// Build numbers and the paths and URLs derived from them
+	{Pattern: `#\d+\b`, Replace: "#<build>"},
+	{Pattern: `\b(BUILD_NUMBER|BUILD_ID)=\d+`, Replace: "$1=<build>"},
+	{Pattern: `/(job/[^/\s]+|builds?)/\d+\b`, Replace: "/$1/<build>"},
+	// Temporary files and directories
+	{Pattern: `(/private)?/var/folders/[^\s/]+/[^\s/]+/T/[^\s:'"]*`, Replace: "<tmp>"},
+	{Pattern: `/(var/)?tmp/[^\s:'"]*`, Replace: "<tmp>"},
+	{Pattern: `(?i)[a-z]:\\[^\s]*\\Temp\\[^\s:'"]*`, Replace: "<tmp>"},
+	{Pattern: `[\w.-]*@tmp\b`, Replace: "<tmp>"},
+	// Durations such as "1.23 s", "450ms", "Took 2 min 3 sec" and "01:23 min"
+	{Pattern: `\b\d+(:\d{2}){1,2} min\b`, Replace: "<duration>"},
+	{Pattern: `\b\d+(\.\d+)? ?(ms|s|sec|secs|seconds?|m|min|mins|minutes?|h|hr|hours?)\b`, Replace: "<duration>"},
+	// Commit hashes, container IDs and other long hexadecimal identifiers
+	{Pattern: `\b[0-9a-f]{12,64}\b`, Replace: "<id>"},
+}
+
+// normalizeRule is a compiled NormalizeRule
+type normalizeRule struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// LogNormalizer rewrites volatile content such as timestamps so that two
+// build logs can be compared line by line
+type LogNormalizer struct {
+	rules []normalizeRule
+}
+
+// NewLogNormalizer compiles the configured rules, followed by the built-in
+// ones unless they are disabled
+func NewLogNormalizer(cfg config.LogDiff) (*LogNormalizer, error) {
+	rules := append([]config.NormalizeRule{}, cfg.Normalize...)
+	if !cfg.NoDefaultNormalize {
+		rules = append(rules, defaultNormalizeRules...)
+	}
+
+	n := &LogNormalizer{}
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("log diff rule %d: invalid pattern %q: %v", i+1, rule.Pattern, err)
+		}
+		n.rules = append(n.rules, normalizeRule{re: re, replace: rule.Replace})
+	}
+
+	return n, nil
+}
+
+// Normalize applies every rule to a plain-text line in order
+func (n *LogNormalizer) Normalize(line string) string {
+	for _, rule := range n.rules {
+		line = rule.re.ReplaceAllString(line, rule.replace)
+	}
+	return line
+}
+
+// DiffKind tells how a line changed between two logs
+type DiffKind int
+
+const (
+	DiffEqual  DiffKind = iota // Present in both logs
+	DiffDelete                 // Only in the old log
+	DiffInsert                 // Only in the new log
+)
+
+// DiffOp is one line of a diff. OldLine and NewLine are indexes into the
+// compared slices, -1 when the line is absent on that side.
+type DiffOp struct {
+	Kind    DiffKind
+	OldLine int
+	NewLine int
+}
+
+// maxDiffEdits bounds the work spent on very different logs: a part of the
+// logs that needs more edits is reported as replaced
+const maxDiffEdits = 4000
+
+// DiffLines computes a shortest line diff between a and b using Myers'
+// algorithm, after trimming their common prefix and suffix
+func DiffLines(a, b []string) []DiffOp {
+	var ops []DiffOp
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, DiffOp{DiffEqual, prefix, prefix})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, DiffOp{DiffEqual, len(a) - i, len(b) - i})
+	}
+	return ops
+}
+
+// myersDiff diffs a and b, whose first lines are at offsets aOff and bOff
+// of the original slices
+func myersDiff(a, b []string, aOff, bOff int) []DiffOp {
+	// Compare small integers rather than strings in the inner loops
+	ids := map[string]int{}
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+
+	d := differ{x: intern(a), y: intern(b), aOff: aOff, bOff: bOff}
+	d.diff(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// differ runs the linear space variant of Myers' algorithm: it finds the
+// middle of a shortest edit path with searches from both ends, then diffs
+// the halves on either side of it, so that only the furthest points reached
+// on each diagonal are kept rather than a copy of them per edit
+type differ struct {
+	x, y       []int
+	aOff, bOff int
+	ops        []DiffOp
+}
+
+// diff appends the operations turning x[i0:i1] into y[j0:j1]
+func (d *differ) diff(i0, i1, j0, j1 int) {
+	for i0 < i1 && j0 < j1 && d.x[i0] == d.y[j0] {
+		d.ops = append(d.ops, DiffOp{DiffEqual, d.aOff + i0, d.bOff + j0})
+		i0++
+		j0++
+	}
+	suffix := 0
+	for i0 < i1-suffix && j0 < j1-suffix && d.x[i1-1-suffix] == d.y[j1-1-suffix] {
+		suffix++
+	}
+	i1, j1 = i1-suffix, j1-suffix
+
+	if i0 == i1 || j0 == j1 {
+		d.ops = append(d.ops, replaceOps(i0, i1, j0, j1, d.aOff, d.bOff)...)
+	} else if i, j, ok := d.middle(i0, i1, j0, j1); ok {
+		d.diff(i0, i, j0, j)
+		d.diff(i, i1, j, j1)
+	} else {
+		d.ops = append(d.ops, replaceOps(i0, i1, j0, j1, d.aOff, d.bOff)...)
+	}
+
+	for k := 0; k < suffix; k++ {
+		d.ops = append(d.ops, DiffOp{DiffEqual, d.aOff + i1 + k, d.bOff + j1 + k})
+	}
+}
+
+// middle returns a point on a shortest edit path from (i0, j0) to (i1, j1),
+// found where the forward and backward searches meet, or false when the
+// path takes more than maxDiffEdits edits
+func (d *differ) middle(i0, i1, j0, j1 int) (int, int, bool) {
+	n, m := i1-i0, j1-j0
+	delta := n - m
+	odd := delta%2 != 0
+	limit := min((n+m+1)/2, maxDiffEdits/2)
+
+	// forward[k+off] is the furthest x reached on diagonal k from the start,
+	// backward[k+off] the furthest from the end, -1 when not reached yet
+	off := limit + 1
+	forward := make([]int, 2*off+1)
+	backward := make([]int, 2*off+1)
+	for k := range forward {
+		forward[k], backward[k] = -1, -1
+	}
+	forward[off+1], backward[off+1] = 0, 0
+
+	// Diagonals that left the grid are not searched again
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for e := 0; e < limit; e++ {
+		for k := -e + fStart; k <= e-fEnd; k += 2 {
+			var x int
+			if k == -e || (k != e && forward[off+k-1] < forward[off+k+1]) {
+				x = forward[off+k+1]
+			} else {
+				x = forward[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.x[i0+x] == d.y[j0+y] {
+				x++
+				y++
+			}
+			forward[off+k] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if kb := off + delta - k; kb >= 0 && kb < len(backward) && backward[kb] != -1 && x >= n-backward[kb] {
+					return i0 + x, j0 + y, true
+				}
+			}
+		}
+
+		for k := -e + bStart; k <= e-bEnd; k += 2 {
+			var x int
+			if k == -e || (k != e && backward[off+k-1] < backward[off+k+1]) {
+				x = backward[off+k+1]
+			} else {
+				x = backward[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.x[i1-1-x] == d.y[j1-1-y] {
+				x++
+				y++
+			}
+			backward[off+k] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if kf := off + delta - k; kf >= 0 && kf < len(forward) && forward[kf] != -1 {
+					fx := forward[kf]
+					if fx >= n-x {
+						return i0 + fx, j0 + fx - (kf - off), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// replaceOps reports a[i0:i1] as deleted and b[j0:j1] as inserted
+func replaceOps(i0, i1, j0, j1, aOff, bOff int) []DiffOp {
+	var ops []DiffOp
+	for i := i0; i < i1; i++ {
+		ops = append(ops, DiffOp{DiffDelete, aOff + i, -1})
+	}
+	for j := j0; j < j1; j++ {
+		ops = append(ops, DiffOp{DiffInsert, -1, bOff + j})
+	}
+	return ops
+}
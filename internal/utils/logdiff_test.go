@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+// applyDiff rebuilds both sides of a diff, checking that the operations
+// walk through a and b in order
+func applyDiff(t *testing.T, a, b []string, ops []DiffOp) (edits int) {
+	t.Helper()
+	i, j := 0, 0
+	for _, op := range ops {
+		switch op.Kind {
+		case DiffEqual:
+			if op.OldLine != i || op.NewLine != j || a[i] != b[j] {
+				t.Fatalf("equal op %+v at old %d, new %d does not match", op, i, j)
+			}
+			i++
+			j++
+		case DiffDelete:
+			if op.OldLine != i || op.NewLine != -1 {
+				t.Fatalf("delete op %+v at old %d", op, i)
+			}
+			i++
+			edits++
+		case DiffInsert:
+			if op.NewLine != j || op.OldLine != -1 {
+				t.Fatalf("insert op %+v at new %d", op, j)
+			}
+			j++
+			edits++
+		}
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("diff covers %d of %d old and %d of %d new lines", i, len(a), j, len(b))
+	}
+	return edits
+}
+
+// lcsEdits returns the number of edits of a shortest diff, by dynamic
+// programming
+func lcsEdits(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return len(a) + len(b) - 2*prev[len(b)]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{"both empty", "", "", 0},
+		{"identical", "a b c", "a b c", 0},
+		{"all inserted", "", "a b", 2},
+		{"all deleted", "a b", "", 2},
+		{"line changed", "a b c", "a x c", 2},
+		{"line inserted", "a b c", "a b x c", 1},
+		{"line deleted", "a b c d", "a c d", 1},
+		{"moved line", "a b c d", "b c d a", 2},
+		{"nothing in common", "a b c", "x y z", 6},
+		{"interleaved", "a b c a b b a", "c b a b a c", 5},
+		{"repeated lines", "x x x y x x", "x y x x x x", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+			if edits := applyDiff(t, a, b, DiffLines(a, b)); edits != tt.edits {
+				t.Errorf("got %d edits, want %d", edits, tt.edits)
+			}
+		})
+	}
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	lines := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = string(rune('a' + random.Intn(4)))
+		}
+		return out
+	}
+
+	for n := 0; n < 300; n++ {
+		a, b := lines(random.Intn(40)), lines(random.Intn(40))
+		if edits, want := applyDiff(t, a, b, DiffLines(a, b)), lcsEdits(a, b); edits != want {
+			t.Fatalf("%v -> %v: got %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffLinesTooDifferent(t *testing.T) {
+	// Past maxDiffEdits the lines left are reported as replaced, which is
+	// still a valid diff
+	n := maxDiffEdits * 2
+	a, b := make([]string, n), make([]string, n)
+	for i := range a {
+		a[i] = fmt.Sprintf("old %d", i)
+		b[i] = fmt.Sprintf("new %d", i)
+	}
+	a[n/2], b[n/2] = "same", "same"
+
+	applyDiff(t, a, b, DiffLines(a, b))
+}
+
+func TestLogNormalizer(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.LogDiff
+		line string
+		want string
+	}{
+		{"iso timestamp", config.LogDiff{}, "2024-03-01T12:34:56.789Z Started", "<timestamp> Started"},
+		{"time of day", config.LogDiff{}, "[12:34:56] Building", "[<time>] Building"},
+		{"build number", config.LogDiff{}, "Started build #412", "Started build #<build>"},
+		{"build variable", config.LogDiff{}, "BUILD_NUMBER=412", "BUILD_NUMBER=<build>"},
+		{"build url", config.LogDiff{}, "see /job/deploy/412/console", "see /job/deploy/<build>/console"},
+		{"temporary path", config.LogDiff{}, "wrote /tmp/build-x1/out.txt", "wrote <tmp>"},
+		{"workspace tmp", config.LogDiff{}, "cd ws@tmp", "cd <tmp>"},
+		{"duration", config.LogDiff{}, "Took 1.23 s", "Took <duration>"},
+		{"minutes", config.LogDiff{}, "Total time: 01:23 min", "Total time: <duration>"},
+		{"commit hash", config.LogDiff{}, "Checking out 3f2a9c1d4e5b6a7f", "Checking out <id>"},
+		{"plain text", config.LogDiff{}, "BUILD SUCCESS", "BUILD SUCCESS"},
+		{
+			"custom rule first",
+			config.LogDiff{Normalize: []config.NormalizeRule{{Pattern: `port \d+`, Replace: "port <port>"}}},
+			"listening on port 8080",
+			"listening on port <port>",
+		},
+		{
+			"defaults disabled",
+			config.LogDiff{NoDefaultNormalize: true},
+			"Started build #412",
+			"Started build #412",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewLogNormalizer(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := n.Normalize(tt.line); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogNormalizerInvalidRule(t *testing.T) {
+	cfg := config.LogDiff{Normalize: []config.NormalizeRule{{Pattern: `(`}}}
+	if _, err := NewLogNormalizer(cfg); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -28,50 +28,44 @@ const (
 	ErrorLevel LogLevel = "error"
 )
 
-// GetLogger returns a singleton zap logger instance
+const (
+	// maxLogSize is the size at which the log file is moved aside for a new one
+	maxLogSize = 5 << 20
+	// maxLogBackups is how many of the files moved aside are kept
+	maxLogBackups = 3
+)
+
+// GetLogger returns a singleton zap logger instance, logging at info level
+// unless InitLogger set another level first
 func GetLogger() *zap.Logger {
+	return InitLogger(InfoLevel)
+}
+
+// InitLogger sets up the logger returned by GetLogger at the given level.
+// Only the first call counts, so it is called at startup before anything
+// logs.
+func InitLogger(level LogLevel) *zap.Logger {
 	once.Do(func() {
-		logger = initLogger(InfoLevel)
+		logger = initLogger(level)
 	})
 	return logger
 }
 
-// GetLoggerWithLevel returns a logger with the specified log level
-func GetLoggerWithLevel(level LogLevel) *zap.Logger {
-	return initLogger(level)
-}
-
-// initLogger initializes a new zap logger
+// initLogger initializes a new zap logger writing to
+// ~/.jenkins-tui/logs/jenkins-tui.log. Nothing is written to the terminal,
+// which belongs to the UI, so without a log file nothing is logged.
 func initLogger(level LogLevel) *zap.Logger {
-	// Create log directory if it doesn't exist
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		// Fall back to console-only logging
-		return createConsoleLogger(level)
+		return zap.NewNop()
 	}
 
+	// Create log directory if it doesn't exist
 	logDir := filepath.Join(homeDir, ".jenkins-tui", "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return createConsoleLogger(level)
+		return zap.NewNop()
 	}
 
-	// Configure logging
-	logFile := filepath.Join(logDir, "jenkins-tui.log")
-
-	// Create encoders for console and file logging
-	consoleEncoder := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalColorLevelEncoder,
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.StringDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-	})
-
 	fileEncoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -81,14 +75,14 @@ func initLogger(level LogLevel) *zap.Logger {
 		LineEnding:     zapcore.DefaultLineEnding,
 		EncodeLevel:    zapcore.LowercaseLevelEncoder,
 		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	})
 
-	// Open log file
-	logFileWriter, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Open the log file, which is rotated once it reaches its cap
+	logFile, err := openRotatingFile(filepath.Join(logDir, "jenkins-tui.log"), maxLogSize, maxLogBackups)
 	if err != nil {
-		return createConsoleLogger(level)
+		return zap.NewNop()
 	}
 
 	// Convert LogLevel to zapcore.Level
@@ -106,38 +100,13 @@ func initLogger(level LogLevel) *zap.Logger {
 		zapLevel = zapcore.InfoLevel
 	}
 
-	// Create core for both console and file
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapLevel),
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(logFileWriter), zapLevel),
-	)
+	// Credentials are redacted from everything written
+	core := redactingCore{zapcore.NewCore(fileEncoder, logFile, zapLevel)}
 
 	// Create logger
 	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 }
 
-// createConsoleLogger creates a logger that only logs to the console
-func createConsoleLogger(level LogLevel) *zap.Logger {
-	var zapLevel zapcore.Level
-	switch level {
-	case DebugLevel:
-		zapLevel = zapcore.DebugLevel
-	case InfoLevel:
-		zapLevel = zapcore.InfoLevel
-	case WarnLevel:
-		zapLevel = zapcore.WarnLevel
-	case ErrorLevel:
-		zapLevel = zapcore.ErrorLevel
-	default:
-		zapLevel = zapcore.InfoLevel
-	}
-
-	config := zap.NewDevelopmentConfig()
-	config.Level = zap.NewAtomicLevelAt(zapLevel)
-	logger, _ := config.Build()
-	return logger
-}
-
 // Sugar returns a sugared logger for more convenient logging
 func Sugar() *zap.SugaredLogger {
 	return GetLogger().Sugar()
@@ -0,0 +1,359 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+// Highlight rule scopes
+const (
+	ScopeLine  = "line"
+	ScopeMatch = "match"
+)
+
+// logPresets are the built-in highlighting rules, keyed by preset name
+var logPresets = map[string][]config.HighlightRule{
+	"generic": {
+		{Pattern: `^Finished: SUCCESS`, Style: "success", Bold: true},
+		{Pattern: `^Finished: UNSTABLE`, Style: "warning", Bold: true},
+		{Pattern: `^Finished: (FAILURE|ABORTED|NOT_BUILT)`, Style: "error", Bold: true},
+		{Pattern: `^\[Pipeline\] `, Style: "muted"},
+		{Pattern: `^[+>] `, Style: "info"},
+		{Pattern: `\b(ERROR|FATAL|FAILED|FAILURE)\b|(?i)^\s*error\b|(?i)\berror:`, Style: "error"},
+		{Pattern: `^\s+at [\w$.<>]+\(|^(Caused by: )?([\w$]+\.)+[\w$]*(Exception|Error)\b`, Style: "error"},
+		{Pattern: `\b(WARN|WARNING)\b|(?i)\bwarning:`, Style: "warning"},
+		{Pattern: `\b(SUCCESS|PASSED)\b`, Style: "success"},
+	},
+	"maven": {
+		{Pattern: `^\[INFO\] BUILD SUCCESS`, Style: "success", Bold: true},
+		{Pattern: `^\[INFO\] BUILD FAILURE`, Style: "error", Bold: true},
+		{Pattern: `^\[ERROR\]`, Style: "error"},
+		{Pattern: `^\[WARN(ING)?\]`, Style: "warning"},
+		{Pattern: `^\[INFO\] -{20,}`, Style: "muted"},
+		{Pattern: `Tests run: \d+, Failures: [1-9]\d*|Errors: [1-9]\d*`, Style: "error", Scope: ScopeMatch},
+		{Pattern: `^\[INFO\] --- [\w.:-]+`, Style: "info", Scope: ScopeMatch},
+	},
+	"gradle": {
+		{Pattern: `^BUILD SUCCESSFUL`, Style: "success", Bold: true},
+		{Pattern: `^BUILD FAILED|^FAILURE: `, Style: "error", Bold: true},
+		{Pattern: `^> Task \S+ FAILED`, Style: "error"},
+		{Pattern: `^\* (What went wrong|Try):`, Style: "error"},
+		{Pattern: `^> Task \S+`, Style: "info", Scope: ScopeMatch},
+		{Pattern: `\b(UP-TO-DATE|FROM-CACHE|NO-SOURCE|SKIPPED)$`, Style: "muted", Scope: ScopeMatch},
+	},
+	"npm": {
+		{Pattern: `^npm (ERR!|error)`, Style: "error"},
+		{Pattern: `^npm (WARN|warn)`, Style: "warning"},
+		{Pattern: `\b\d+ (failing|failed)\b`, Style: "error", Scope: ScopeMatch},
+		{Pattern: `\b\d+ passing\b|found 0 vulnerabilities`, Style: "success", Scope: ScopeMatch},
+		{Pattern: `✓|✔`, Style: "success", Scope: ScopeMatch},
+		{Pattern: `✗|✖`, Style: "error", Scope: ScopeMatch},
+	},
+	"go": {
+		{Pattern: `^(--- FAIL:|FAIL\b|panic:)`, Style: "error"},
+		{Pattern: `^(--- PASS:|ok\s)`, Style: "success"},
+		{Pattern: `^--- SKIP:`, Style: "warning"},
+		{Pattern: `^=== (RUN|PAUSE|CONT)`, Style: "muted"},
+		{Pattern: `^\S+\.go:\d+(:\d+)?: `, Style: "error", Scope: ScopeMatch},
+	},
+	"docker": {
+		{Pattern: `^Successfully (built|tagged) `, Style: "success"},
+		{Pattern: `^#\d+ ERROR|^ERROR: failed to solve`, Style: "error"},
+		{Pattern: `^Step \d+/\d+ : `, Style: "info", Bold: true},
+		{Pattern: `^ ---> `, Style: "muted"},
+		{Pattern: `^#\d+ (CACHED|DONE [\d.]+s)$`, Style: "muted"},
+		{Pattern: `^#\d+ \[[^\]]+\]`, Style: "info", Scope: ScopeMatch},
+	},
+}
+
+// presetOrder is the order in which presets are applied when none are configured
+var presetOrder = []string{"maven", "gradle", "npm", "go", "docker", "generic"}
+
+// highlightRule is a compiled HighlightRule
+type highlightRule struct {
+	re    *regexp.Regexp
+	style string
+	bold  bool
+	match bool
+}
+
+// LogHighlighter colours build log lines with an ordered list of rules.
+// The first matching line rule colours the whole line; match rules colour
+// every non-overlapping match on top of it, earlier rules winning.
+type LogHighlighter struct {
+	rules []highlightRule
+
+	// Styles resolved against the theme they were built for
+	styles    []lipgloss.Style
+	themeName string
+}
+
+// activeHighlighter is used by ColorizeLogOutput and friends
+var activeHighlighter = mustHighlighter(NewLogHighlighter(config.LogHighlighting{}))
+
+// LogPresetNames returns the names of the built-in highlighting presets
+func LogPresetNames() []string {
+	names := make([]string, 0, len(logPresets))
+	for name := range logPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewLogHighlighter compiles the configured rules followed by the rules of
+// the configured presets
+func NewLogHighlighter(cfg config.LogHighlighting) (*LogHighlighter, error) {
+	presets := cfg.Presets
+	if presets == nil {
+		presets = presetOrder
+	}
+
+	rules := append([]config.HighlightRule{}, cfg.Rules...)
+	for _, name := range presets {
+		preset, ok := logPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown log highlighting preset %q (available: %s)",
+				name, strings.Join(LogPresetNames(), ", "))
+		}
+		rules = append(rules, preset...)
+	}
+
+	h := &LogHighlighter{}
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("log highlighting rule %d: invalid pattern %q: %v", i+1, rule.Pattern, err)
+		}
+
+		switch rule.Scope {
+		case "", ScopeLine, ScopeMatch:
+		default:
+			return nil, fmt.Errorf("log highlighting rule %d: scope must be %q or %q, not %q",
+				i+1, ScopeLine, ScopeMatch, rule.Scope)
+		}
+		if rule.Style == "" {
+			return nil, fmt.Errorf("log highlighting rule %d: missing style", i+1)
+		}
+
+		h.rules = append(h.rules, highlightRule{
+			re:    re,
+			style: rule.Style,
+			bold:  rule.Bold,
+			match: rule.Scope == ScopeMatch,
+		})
+	}
+
+	return h, nil
+}
+
+// SetLogHighlighter makes h the highlighter used for all build logs
+func SetLogHighlighter(h *LogHighlighter) {
+	activeHighlighter = h
+}
+
+// highlightSpan is a match of a match rule and the style it is rendered in
+type highlightSpan struct {
+	start, end int
+	style      lipgloss.Style
+}
+
+// highlights finds the style of a plain-text line and the matches of the
+// match rules in it, in order
+func (h *LogHighlighter) highlights(line string) (lipgloss.Style, bool, []highlightSpan) {
+	styles := h.resolvedStyles()
+	base, hasBase := h.lineStyle(line, styles)
+
+	var spans []highlightSpan
+	for i, rule := range h.rules {
+		if !rule.match {
+			continue
+		}
+		for _, loc := range rule.re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			overlaps := false
+			for _, s := range spans {
+				if loc[0] < s.end && s.start < loc[1] {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				spans = append(spans, highlightSpan{loc[0], loc[1], styles[i].Copy().Inherit(base)})
+			}
+		}
+	}
+
+	sort.Slice(spans, func(a, b int) bool { return spans[a].start < spans[b].start })
+	return base, hasBase, spans
+}
+
+// Render colours a single plain-text log line
+func (h *LogHighlighter) Render(line string) string {
+	base, hasBase, spans := h.highlights(line)
+	if len(spans) == 0 {
+		if hasBase {
+			return base.Render(line)
+		}
+		return line
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for _, s := range spans {
+		if s.start > pos {
+			sb.WriteString(base.Render(line[pos:s.start]))
+		}
+		sb.WriteString(s.style.Render(line[s.start:s.end]))
+		pos = s.end
+	}
+	if pos < len(line) {
+		sb.WriteString(base.Render(line[pos:]))
+	}
+	return sb.String()
+}
+
+// RenderANSI colours a log line that carries colour (SGR) sequences of its
+// own, as SanitizeANSI leaves it. The rules run on the text without the
+// sequences; the text the line colours keeps its colour and the rest is
+// coloured by the rules.
+func (h *LogHighlighter) RenderANSI(line string) string {
+	base, hasBase, spans := h.highlights(StripANSI(line))
+	if !hasBase && len(spans) == 0 {
+		return line
+	}
+
+	// styleAt returns the style of the text at an offset of the plain line,
+	// and how far that style runs
+	styleAt := func(pos int) (lipgloss.Style, bool, int) {
+		for _, s := range spans {
+			if pos < s.start {
+				return base, hasBase, s.start
+			}
+			if pos < s.end {
+				return s.style, true, s.end
+			}
+		}
+		return base, hasBase, -1
+	}
+
+	var sb strings.Builder
+	var state sgrState
+	active := "" // Sequences in effect since the last reset
+	pos := 0
+
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			j := strings.IndexByte(line[i:], 'm')
+			if j < 0 {
+				break
+			}
+			seq := line[i : i+j+1]
+			sb.WriteString(seq)
+			state = applySGR(state, seq[2:len(seq)-1])
+			if state == (sgrState{}) {
+				active = ""
+			} else {
+				active += seq
+			}
+			i += j + 1
+			continue
+		}
+
+		end := strings.IndexByte(line[i:], '\x1b')
+		if end < 0 {
+			end = len(line)
+		} else {
+			end += i
+		}
+		for i < end {
+			style, styled, until := styleAt(pos)
+			n := end - i
+			if until >= 0 {
+				n = min(n, until-pos)
+			}
+			text := line[i : i+n]
+			if styled && state.fg == "" {
+				// Lipgloss resets everything after the text, so the
+				// sequences of the line are set again
+				sb.WriteString(style.Render(text))
+				sb.WriteString(active)
+			} else {
+				sb.WriteString(text)
+			}
+			i += n
+			pos += n
+		}
+	}
+	return sb.String()
+}
+
+// LineStyle returns the style of the first line rule matching line
+func (h *LogHighlighter) LineStyle(line string) (lipgloss.Style, bool) {
+	return h.lineStyle(line, h.resolvedStyles())
+}
+
+// lineStyle finds the first line rule matching line
+func (h *LogHighlighter) lineStyle(line string, styles []lipgloss.Style) (lipgloss.Style, bool) {
+	for i, rule := range h.rules {
+		if !rule.match && rule.re.MatchString(line) {
+			return styles[i], true
+		}
+	}
+	return lipgloss.NewStyle(), false
+}
+
+// resolvedStyles builds the rule styles for the current theme, caching
+// them until the theme changes
+func (h *LogHighlighter) resolvedStyles() []lipgloss.Style {
+	if h.styles != nil && h.themeName == currentTheme.Name {
+		return h.styles
+	}
+
+	h.styles = make([]lipgloss.Style, len(h.rules))
+	for i, rule := range h.rules {
+		h.styles[i] = lipgloss.NewStyle().
+			Foreground(roleColor(rule.style)).
+			Bold(rule.bold)
+	}
+	h.themeName = currentTheme.Name
+	return h.styles
+}
+
+// roleColor maps a theme role to its colour; anything else is used as a
+// literal colour such as "208" or "#FF8700"
+func roleColor(role string) lipgloss.Color {
+	switch strings.ToLower(role) {
+	case "error", "failure":
+		return ColorSecondary
+	case "warning":
+		return ColorWarning
+	case "success":
+		return ColorSuccess
+	case "info", "running":
+		return ColorRunning
+	case "muted":
+		return ColorMuted
+	case "accent":
+		return ColorAccent
+	case "primary":
+		return ColorPrimary
+	default:
+		return lipgloss.Color(role)
+	}
+}
+
+// mustHighlighter panics if the built-in rules fail to compile
+func mustHighlighter(h *LogHighlighter, err error) *LogHighlighter {
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
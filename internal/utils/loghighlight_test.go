@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/sanjaykishor/JenkinsTui.git/internal/config"
+)
+
+func TestLogHighlighterPresets(t *testing.T) {
+	tests := []struct {
+		preset    string
+		line      string
+		wantLine  string // Role of the line rule matching, empty for none
+		wantMatch string // First text of a match rule, empty for none
+	}{
+		{"generic", "Finished: SUCCESS", "success", ""},
+		{"generic", "Finished: FAILURE", "error", ""},
+		{"generic", "[Pipeline] stage", "muted", ""},
+		{"generic", "+ make test", "info", ""},
+		{"generic", "\tat com.example.App.main(App.java:10)", "error", ""},
+		{"generic", "WARNING: deprecated flag", "warning", ""},
+		{"generic", "compiling sources", "", ""},
+		{"maven", "[INFO] BUILD FAILURE", "error", ""},
+		{"maven", "[WARNING] unchecked call", "warning", ""},
+		{"maven", "Tests run: 4, Failures: 1, Errors: 0", "", "Tests run: 4, Failures: 1"},
+		{"maven", "Tests run: 4, Failures: 0, Errors: 0", "", ""},
+		{"gradle", "> Task :app:test FAILED", "error", "> Task :app:test"},
+		{"gradle", "> Task :app:compileJava UP-TO-DATE", "", "> Task :app:compileJava"},
+		{"npm", "npm ERR! code ELIFECYCLE", "error", ""},
+		{"npm", "  12 passing (3s)", "", "12 passing"},
+		{"go", "--- FAIL: TestParse (0.00s)", "error", ""},
+		{"go", "ok  \texample.com/pkg\t0.01s", "success", ""},
+		{"go", "main_test.go:12: got 1", "", "main_test.go:12: "},
+		{"docker", "Step 3/7 : RUN make", "info", ""},
+		{"docker", "#5 [build 2/4] COPY . .", "", "#5 [build 2/4]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset+": "+tt.line, func(t *testing.T) {
+			h, err := NewLogHighlighter(config.LogHighlighting{Presets: []string{tt.preset}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			style, ok := h.LineStyle(tt.line)
+			switch {
+			case ok != (tt.wantLine != ""):
+				t.Errorf("line rule matched %v, want %q", ok, tt.wantLine)
+			case ok && style.GetForeground() != roleColor(tt.wantLine):
+				t.Errorf("line coloured %v, want %s", style.GetForeground(), tt.wantLine)
+			}
+
+			_, _, spans := h.highlights(tt.line)
+			got := ""
+			if len(spans) > 0 {
+				got = tt.line[spans[0].start:spans[0].end]
+			}
+			if got != tt.wantMatch {
+				t.Errorf("first match %q, want %q", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestLogHighlighterConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LogHighlighting
+		wantErr bool
+	}{
+		{"defaults", config.LogHighlighting{}, false},
+		{"no presets", config.LogHighlighting{Presets: []string{}}, false},
+		{"custom rule", config.LogHighlighting{Rules: []config.HighlightRule{{Pattern: `^deploy`, Style: "accent"}}}, false},
+		{"unknown preset", config.LogHighlighting{Presets: []string{"cobol"}}, true},
+		{"invalid pattern", config.LogHighlighting{Rules: []config.HighlightRule{{Pattern: `(`, Style: "error"}}}, true},
+		{"invalid scope", config.LogHighlighting{Rules: []config.HighlightRule{{Pattern: `x`, Style: "error", Scope: "word"}}}, true},
+		{"missing style", config.LogHighlighting{Rules: []config.HighlightRule{{Pattern: `x`}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLogHighlighter(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderANSI(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(profile)
+
+	h, err := NewLogHighlighter(config.LogHighlighting{Rules: []config.HighlightRule{
+		{Pattern: `ERROR`, Style: "error"},
+		{Pattern: `#\d+`, Style: "accent", Scope: ScopeMatch},
+	}, Presets: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errorStyle, _ := h.LineStyle("ERROR")
+	_, _, spans := h.highlights("#1")
+	accent := spans[0].style
+	if !strings.Contains(errorStyle.Render("x"), "\x1b[") {
+		t.Fatal("rule styles render without colour")
+	}
+
+	tests := []struct {
+		name string
+		line string // As SanitizeANSI leaves it
+		want string
+	}{
+		{
+			"no rule",
+			"\x1b[32mok\x1b[0m done\x1b[0m",
+			"\x1b[32mok\x1b[0m done\x1b[0m",
+		},
+		{
+			"colour of the line kept",
+			"\x1b[32mok\x1b[0m ERROR\x1b[0m",
+			"\x1b[32mok\x1b[0m" + errorStyle.Render(" ERROR") + "\x1b[0m",
+		},
+		{
+			"rule found across sequences",
+			"ERR\x1b[1mOR\x1b[0m\x1b[0m",
+			errorStyle.Render("ERR") + "\x1b[1m" + errorStyle.Render("OR") + "\x1b[1m" + "\x1b[0m\x1b[0m",
+		},
+		{
+			"match in coloured text left alone",
+			"build \x1b[33m#12\x1b[0m and #13\x1b[0m",
+			"build \x1b[33m#12\x1b[0m and " + accent.Render("#13") + "\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.RenderANSI(tt.line)
+			if got != tt.want {
+				t.Errorf("RenderANSI(%q)\n got %q\nwant %q", tt.line, got, tt.want)
+			}
+			if StripANSI(got) != StripANSI(tt.line) {
+				t.Errorf("text changed to %q", StripANSI(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// literalFilter holds strings of which every match of a regular expression
+// contains at least one, so that lines without any of them can be skipped
+// without running the expression. A nil filter lets every line through.
+type literalFilter []requiredLiteral
+
+// requiredLiteral is a string a match must contain, compared ignoring case
+// when fold is set
+type requiredLiteral struct {
+	text string
+	fold bool
+}
+
+// newLiteralFilter derives the filter of a pattern; it returns nil when no
+// literal is required, e.g. for `\d+`
+func newLiteralFilter(pattern string) literalFilter {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return requiredLiterals(re)
+}
+
+// requiredLiterals returns literals of which every match of re contains one
+func requiredLiterals(re *syntax.Regexp) literalFilter {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return nil
+		}
+		fold := re.Flags&syntax.FoldCase != 0
+		text := string(re.Rune)
+		if fold {
+			if !asciiFold(re.Rune) {
+				// Lowering the line would not find e.g. "ſ" for "s"
+				return nil
+			}
+			text = strings.ToLower(text)
+		}
+		return literalFilter{{text: text, fold: fold}}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return nil
+		}
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpConcat:
+		// Any part will do; prefer the one whose shortest literal is longest
+		var best literalFilter
+		for _, sub := range re.Sub {
+			if lits := requiredLiterals(sub); lits != nil && (best == nil || lits.shortest() > best.shortest()) {
+				best = lits
+			}
+		}
+		return best
+
+	case syntax.OpAlternate:
+		// Every alternative needs a literal of its own
+		var all literalFilter
+		for _, sub := range re.Sub {
+			lits := requiredLiterals(sub)
+			if lits == nil {
+				return nil
+			}
+			all = append(all, lits...)
+		}
+		return all
+	}
+
+	return nil
+}
+
+// asciiFold reports whether every case variant of the runes is ASCII
+func asciiFold(runes []rune) bool {
+	for _, r := range runes {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f >= utf8.RuneSelf {
+				return false
+			}
+		}
+		if r >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// shortest returns the length of the shortest literal
+func (f literalFilter) shortest() int {
+	n := -1
+	for _, lit := range f {
+		if n < 0 || len(lit.text) < n {
+			n = len(lit.text)
+		}
+	}
+	return n
+}
+
+// mayMatch reports whether line contains one of the literals
+func (f literalFilter) mayMatch(line string) bool {
+	if f == nil {
+		return true
+	}
+
+	lower := ""
+	for _, lit := range f {
+		if !lit.fold {
+			if strings.Contains(line, lit.text) {
+				return true
+			}
+			continue
+		}
+		if lower == "" {
+			lower = strings.ToLower(line)
+		}
+		if strings.Contains(lower, lit.text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLiteralFilter(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    literalFilter
+	}{
+		{`ERROR`, literalFilter{{text: "ERROR"}}},
+		{`(?i)timed out`, literalFilter{{text: "timed out", fold: true}}},
+		{`^\s+at \w+`, literalFilter{{text: "at "}}},
+		{`Tests run: \d+, Failures: [1-9]`, literalFilter{{text: ", Failures: "}}},
+		{`(panic|fatal error):`, literalFilter{{text: "panic"}, {text: "fatal error"}}},
+		{`(x+)abc`, literalFilter{{text: "abc"}}},
+		{`(?:abc){2}`, literalFilter{{text: "abc"}}},
+		{`\d+`, nil},
+		{`(?:abc)?`, nil},
+		{`abc|\d`, nil},
+		{`(?i)kill`, nil}, // "k" also matches the Kelvin sign
+		{`(`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := newLiteralFilter(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newLiteralFilter(%q) = %+v, want %+v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiteralFilterNeverSkipsMatches(t *testing.T) {
+	lines := []string{
+		"",
+		"ERROR: script returned exit code 1",
+		"error: cannot find symbol",
+		"java.net.SocketTimeoutException: Read TIMED OUT",
+		"Tests run: 12, Failures: 2, Errors: 0, Skipped: 0",
+		"--- FAIL: TestParse (0.00s)",
+		"\tat com.example.App.main(App.java:10)",
+		"panic: runtime error: index out of range",
+		"Killed",
+		"[INFO] BUILD SUCCESS",
+	}
+
+	var patterns []string
+	for _, sig := range defaultFailureSignatures {
+		patterns = append(patterns, sig.Pattern)
+	}
+	patterns = append(patterns, `(?i)error`, `FAIL|ok`, `^\[INFO\]`, `\d+ failing`)
+
+	for _, pattern := range patterns {
+		re := regexp.MustCompile(pattern)
+		filter := newLiteralFilter(pattern)
+		for _, line := range lines {
+			if re.MatchString(line) && !filter.mayMatch(line) {
+				t.Errorf("%q skips %q, which it matches", pattern, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// redacted replaces the secrets removed from the logs
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials in log messages and fields: the groups
+// before and after the secret are kept
+var secretPatterns = []*regexp.Regexp{
+	// Authorization headers, with or without their scheme, alone or in the
+	// list of values of a header map
+	regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*\[?["']?(?:basic |bearer |token )?)[^\s"',;\]]+()`),
+	// Tokens, crumbs and passwords in headers, query strings, JSON and YAML
+	regexp.MustCompile(`(?i)((?:api[_-]?token|token|password|passwd|secret|jenkins-crumb|crumb)["']?\s*[:=]\s*["']?)[^\s"',&;]+()`),
+	// Passwords in URLs
+	regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+(@)`),
+}
+
+var (
+	secretsMutex sync.RWMutex
+	secrets      []string
+)
+
+// AddSecret makes the logs redact a value wherever it appears, such as the
+// API token of a server
+func AddSecret(secret string) {
+	if secret == "" {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// Redact removes tokens, crumbs, passwords and Authorization headers from a
+// text
+func Redact(text string) string {
+	secretsMutex.RLock()
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	secretsMutex.RUnlock()
+
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, "${1}"+redacted+"${2}")
+	}
+	return text
+}
+
+// redactingCore redacts the message and the text fields of every entry
+// before it is written
+type redactingCore struct {
+	zapcore.Core
+}
+
+// With implements zapcore.Core
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{c.Core.With(redactFields(fields))}
+}
+
+// Check implements zapcore.Core
+func (c redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// redactFields returns the fields with the text in them redacted; errors
+// and stringers are turned into text to be redacted, and values logged by
+// reflection into JSON
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = Redact(field.String)
+		case zapcore.ErrorType:
+			field = zapcore.Field{Key: field.Key, Type: zapcore.StringType,
+				String: Redact(field.Interface.(error).Error())}
+		case zapcore.StringerType:
+			field = zapcore.Field{Key: field.Key, Type: zapcore.StringType,
+				String: Redact(field.Interface.(fmt.Stringer).String())}
+		case zapcore.ByteStringType:
+			field.Interface = []byte(Redact(string(field.Interface.([]byte))))
+		case zapcore.ReflectType:
+			field = redactReflected(field)
+		}
+		out[i] = field
+	}
+	return out
+}
+
+// redactReflected redacts a value logged by reflection, such as with
+// zap.Any: it is encoded to JSON, which keeps its structure in the log, or
+// formatted as text when it cannot be
+func redactReflected(field zapcore.Field) zapcore.Field {
+	if data, err := json.Marshal(field.Interface); err == nil {
+		if text := Redact(string(data)); json.Valid([]byte(text)) {
+			field.Interface = json.RawMessage(text)
+			return field
+		}
+	}
+	return zapcore.Field{Key: field.Key, Type: zapcore.StringType,
+		String: Redact(fmt.Sprintf("%+v", field.Interface))}
+}
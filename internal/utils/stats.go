@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// Mean returns the average of values, or 0 for none
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Percentile returns the nearest-rank p-th percentile of values, e.g. 90
+// for the value 90% of them do not exceed, or 0 for none
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
@@ -4,49 +4,110 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Colors used throughout the application
+// Colors used throughout the application, derived from the current theme
 var (
-	ColorPrimary   = lipgloss.Color("#0D8AC9") // Jenkins blue
-	ColorSecondary = lipgloss.Color("#CC0000") // Error/failure red
-	ColorSuccess   = lipgloss.Color("#339900") // Success green
-	ColorWarning   = lipgloss.Color("#F0AD4E") // Warning yellow
-	ColorGray      = lipgloss.Color("#666666") // Neutral gray
-	ColorDarkGray  = lipgloss.Color("#333333") // Dark gray for backgrounds
-	ColorLightGray = lipgloss.Color("#CCCCCC") // Light gray for borders
-	ColorWhite     = lipgloss.Color("#FFFFFF") // White
-	ColorBlack     = lipgloss.Color("#000000") // Black
+	ColorPrimary   lipgloss.Color // Jenkins blue
+	ColorSecondary lipgloss.Color // Error/failure red
+	ColorSuccess   lipgloss.Color // Success green
+	ColorWarning   lipgloss.Color // Warning yellow
+	ColorRunning   lipgloss.Color // Running/command blue
+	ColorAccent    lipgloss.Color // Title and help banners
+	ColorMuted     lipgloss.Color // Hints and footers
+	ColorGray      lipgloss.Color // Neutral gray
+	ColorDarkGray  lipgloss.Color // Dark gray for backgrounds
+	ColorLightGray lipgloss.Color // Light gray for borders
+	ColorWhite     lipgloss.Color // Foreground text
+	ColorBlack     lipgloss.Color // Text on primary backgrounds
+	ColorOnAccent  lipgloss.Color // Text on title and help banners
+	ColorHighlight lipgloss.Color // Selected items
 )
 
-// Common Styles
+// Common Styles, derived from the current theme
 var (
 	// Base text styles
+	NormalText lipgloss.Style
+	BoldText   lipgloss.Style
+	HeaderText lipgloss.Style
+	MutedText  lipgloss.Style
+
+	// Status styles
+	SuccessText lipgloss.Style
+	FailureText lipgloss.Style
+	WarningText lipgloss.Style
+
+	// Container styles
+	AppContainer lipgloss.Style
+	Panel        lipgloss.Style
+
+	// Tab styles
+	ActiveTab   lipgloss.Style
+	InactiveTab lipgloss.Style
+
+	// Status bar styles
+	StatusBar lipgloss.Style
+
+	// Help style
+	HelpStyle        lipgloss.Style
+	HelpTitleStyle   lipgloss.Style
+	HelpSectionStyle lipgloss.Style
+
+	// Search match styles
+	MatchStyle        lipgloss.Style
+	CurrentMatchStyle lipgloss.Style
+
+	TitleStyle      lipgloss.Style
+	InfoBlockStyle  lipgloss.Style
+	ServerInfoStyle lipgloss.Style
+	LogStyle        lipgloss.Style
+)
+
+// applyTheme makes t current and rebuilds every colour and style from it
+func applyTheme(t Theme) {
+	currentTheme = t
+
+	ColorPrimary = lipgloss.Color(t.Primary)
+	ColorSecondary = lipgloss.Color(t.Failure)
+	ColorSuccess = lipgloss.Color(t.Success)
+	ColorWarning = lipgloss.Color(t.Warning)
+	ColorRunning = lipgloss.Color(t.Running)
+	ColorAccent = lipgloss.Color(t.Accent)
+	ColorMuted = lipgloss.Color(t.Muted)
+	ColorGray = lipgloss.Color(t.Unknown)
+	ColorDarkGray = lipgloss.Color(t.Surface)
+	ColorLightGray = lipgloss.Color(t.Border)
+	ColorWhite = lipgloss.Color(t.Text)
+	ColorBlack = lipgloss.Color(t.TextInverse)
+	ColorOnAccent = lipgloss.Color(t.AccentText)
+	ColorHighlight = lipgloss.Color(t.Highlight)
+
 	NormalText = lipgloss.NewStyle().
-			Foreground(ColorWhite)
+		Foreground(ColorWhite)
 
 	BoldText = lipgloss.NewStyle().
-			Foreground(ColorWhite).
-			Bold(true)
+		Foreground(ColorWhite).
+		Bold(true)
 
 	HeaderText = lipgloss.NewStyle().
-			Foreground(ColorPrimary).
-			Bold(true)
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	MutedText = lipgloss.NewStyle().
+		Foreground(ColorMuted)
 
-	// Status styles
 	SuccessText = lipgloss.NewStyle().
-			Foreground(ColorSuccess).
-			Bold(true)
+		Foreground(ColorSuccess).
+		Bold(true)
 
 	FailureText = lipgloss.NewStyle().
-			Foreground(ColorSecondary).
-			Bold(true)
+		Foreground(ColorSecondary).
+		Bold(true)
 
 	WarningText = lipgloss.NewStyle().
-			Foreground(ColorWarning).
-			Bold(true)
+		Foreground(ColorWarning).
+		Bold(true)
 
-	// Container styles
 	AppContainer = lipgloss.NewStyle().
-			Padding(1, 2)
+		Padding(1, 2)
 
 	Panel = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -54,63 +115,76 @@ var (
 		Padding(1, 2).
 		Margin(0, 1)
 
-	// Tab styles
 	ActiveTab = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorBlack).
-			Background(ColorPrimary).
-			Padding(0, 3)
+		Bold(true).
+		Foreground(ColorBlack).
+		Background(ColorPrimary).
+		Padding(0, 3)
 
 	InactiveTab = lipgloss.NewStyle().
-			Foreground(ColorWhite).
-			Background(ColorDarkGray).
-			Padding(0, 3)
+		Foreground(ColorWhite).
+		Background(ColorDarkGray).
+		Padding(0, 3)
 
-	// Status bar styles
 	StatusBar = lipgloss.NewStyle().
-			Foreground(ColorWhite).
-			Background(ColorDarkGray).
-			Padding(0, 1)
+		Foreground(ColorWhite).
+		Background(ColorDarkGray).
+		Padding(0, 1)
 
-	// Help style
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(ColorLightGray).
-			MarginLeft(1)
+		Foreground(ColorLightGray).
+		MarginLeft(1)
 
 	HelpTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			Padding(0, 1).
-			MarginBottom(1)
+		Foreground(ColorOnAccent).
+		Background(ColorAccent).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	HelpSectionStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("240")).
-				Padding(1, 2).
-				MarginBottom(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorLightGray).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	MatchStyle = lipgloss.NewStyle().
+		Foreground(ColorBlack).
+		Background(ColorWarning)
+
+	CurrentMatchStyle = lipgloss.NewStyle().
+		Foreground(ColorBlack).
+		Background(ColorHighlight).
+		Bold(true)
 
 	TitleStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(0, 1).
-			Bold(true).
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			MarginBottom(1)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorLightGray).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(ColorOnAccent).
+		Background(ColorAccent).
+		MarginBottom(1)
 
 	InfoBlockStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(1, 2).
-			MarginRight(2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorLightGray).
+		Padding(1, 2).
+		MarginRight(2)
 
 	ServerInfoStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(1, 2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2)
 
+	// Border rather than BorderStyle, so that the viewport counts the
+	// border in its height
 	LogStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(1, 2)
-)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorLightGray).
+		Padding(1, 2)
+}
+
+// StatusStyle returns a foreground style in the theme colour for a status
+func StatusStyle(status string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(GetStatusColor(status)))
+}
@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultThemeName is the theme used when none is configured
+const DefaultThemeName = "default"
+
+// Theme is a named colour palette that all application styles derive from.
+// Colours are lipgloss colour strings: ANSI numbers ("196") or hex ("#CC0000").
+type Theme struct {
+	Name string `yaml:"name"`
+	// Base names a theme to inherit unset colours from (custom themes only)
+	Base string `yaml:"base,omitempty"`
+
+	Primary     string `yaml:"primary"`     // Accents, headers and active borders
+	Accent      string `yaml:"accent"`      // Title and help banners
+	Text        string `yaml:"text"`        // Normal foreground text
+	TextInverse string `yaml:"textInverse"` // Text drawn on Primary backgrounds
+	AccentText  string `yaml:"accentText"`  // Text drawn on Accent banners
+	Muted       string `yaml:"muted"`       // Secondary text, hints and footers
+	Border      string `yaml:"border"`      // Neutral borders
+	Surface     string `yaml:"surface"`     // Status bar and inactive tab backgrounds
+	Highlight   string `yaml:"highlight"`   // Selected list items
+
+	Success string `yaml:"success"`
+	Failure string `yaml:"failure"`
+	Warning string `yaml:"warning"`
+	Aborted string `yaml:"aborted"`
+	Running string `yaml:"running"`
+	Unknown string `yaml:"unknown"`
+}
+
+// themeFile is the on-disk format of a user theme file
+type themeFile struct {
+	Themes []Theme `yaml:"themes"`
+}
+
+// builtinThemes are always available and cannot be overridden by name
+var builtinThemes = []Theme{
+	{
+		Name:        "default",
+		Primary:     "#0D8AC9",
+		Accent:      "#7D56F4",
+		Text:        "#FFFFFF",
+		TextInverse: "#000000",
+		AccentText:  "#FAFAFA",
+		Muted:       "241",
+		Border:      "240",
+		Surface:     "#333333",
+		Highlight:   "#EE6FF8",
+		Success:     "42",
+		Failure:     "196",
+		Warning:     "208",
+		Aborted:     "208",
+		Running:     "33",
+		Unknown:     "247",
+	},
+	{
+		Name:        "dark",
+		Primary:     "#61AFEF",
+		Accent:      "#C678DD",
+		Text:        "#ABB2BF",
+		TextInverse: "#282C34",
+		AccentText:  "#282C34",
+		Muted:       "#5C6370",
+		Border:      "#3E4451",
+		Surface:     "#21252B",
+		Highlight:   "#E5C07B",
+		Success:     "#98C379",
+		Failure:     "#E06C75",
+		Warning:     "#E5C07B",
+		Aborted:     "#D19A66",
+		Running:     "#61AFEF",
+		Unknown:     "#5C6370",
+	},
+	{
+		Name:        "light",
+		Primary:     "#005F87",
+		Accent:      "#5F00AF",
+		Text:        "#1C1C1C",
+		TextInverse: "#FFFFFF",
+		AccentText:  "#FFFFFF",
+		Muted:       "#6C6C6C",
+		Border:      "#A8A8A8",
+		Surface:     "#E4E4E4",
+		Highlight:   "#AF005F",
+		Success:     "#008700",
+		Failure:     "#D70000",
+		Warning:     "#AF5F00",
+		Aborted:     "#875F00",
+		Running:     "#005FD7",
+		Unknown:     "#6C6C6C",
+	},
+	{
+		Name:        "high-contrast",
+		Primary:     "#00FFFF",
+		Accent:      "#FFFF00",
+		Text:        "#FFFFFF",
+		TextInverse: "#000000",
+		AccentText:  "#000000",
+		Muted:       "#D0D0D0",
+		Border:      "#FFFFFF",
+		Surface:     "#000000",
+		Highlight:   "#FFFF00",
+		Success:     "#00FF00",
+		Failure:     "#FF0000",
+		Warning:     "#FFFF00",
+		Aborted:     "#FF8700",
+		Running:     "#00FFFF",
+		Unknown:     "#D0D0D0",
+	},
+	{
+		Name:        "solarized",
+		Primary:     "#268BD2",
+		Accent:      "#6C71C4",
+		Text:        "#93A1A1",
+		TextInverse: "#FDF6E3",
+		AccentText:  "#FDF6E3",
+		Muted:       "#657B83",
+		Border:      "#586E75",
+		Surface:     "#073642",
+		Highlight:   "#D33682",
+		Success:     "#859900",
+		Failure:     "#DC322F",
+		Warning:     "#B58900",
+		Aborted:     "#CB4B16",
+		Running:     "#2AA198",
+		Unknown:     "#657B83",
+	},
+}
+
+var (
+	themes       = map[string]Theme{}
+	currentTheme Theme
+)
+
+func init() {
+	for _, t := range builtinThemes {
+		themes[t.Name] = t
+	}
+
+	honourNoColor()
+	applyTheme(themes[DefaultThemeName])
+}
+
+// honourNoColor follows https://no-color.org: when NO_COLOR is set, text
+// attributes are kept but all colour is dropped
+func honourNoColor() {
+	if os.Getenv("NO_COLOR") != "" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// LoadThemeFile registers the custom themes defined in a YAML file.
+// A missing file is not an error; custom themes may not shadow built-in ones.
+func LoadThemeFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read theme file: %v", err)
+	}
+
+	var file themeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse theme file: %v", err)
+	}
+
+	for _, t := range file.Themes {
+		if t.Name == "" {
+			return fmt.Errorf("theme file %s: theme without a name", path)
+		}
+		if isBuiltinTheme(t.Name) {
+			return fmt.Errorf("theme file %s: %q is a built-in theme", path, t.Name)
+		}
+
+		base := DefaultThemeName
+		if t.Base != "" {
+			base = t.Base
+		}
+		parent, ok := themes[base]
+		if !ok {
+			return fmt.Errorf("theme file %s: theme %q has unknown base %q", path, t.Name, base)
+		}
+
+		themes[t.Name] = mergeTheme(parent, t)
+	}
+
+	return nil
+}
+
+// SetTheme makes the named theme current and rebuilds all styles from it
+func SetTheme(name string) error {
+	if name == "" {
+		name = DefaultThemeName
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	applyTheme(t)
+	return nil
+}
+
+// CurrentTheme returns the active theme
+func CurrentTheme() Theme {
+	return currentTheme
+}
+
+// ThemeNames returns the names of all registered themes, built-ins first
+func ThemeNames() []string {
+	var names, custom []string
+	for _, t := range builtinThemes {
+		names = append(names, t.Name)
+	}
+	for name := range themes {
+		if !isBuiltinTheme(name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// NextThemeName returns the theme that follows the current one, wrapping around
+func NextThemeName() string {
+	names := ThemeNames()
+	for i, name := range names {
+		if name == currentTheme.Name {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// isBuiltinTheme reports whether name refers to a built-in theme
+func isBuiltinTheme(name string) bool {
+	for _, t := range builtinThemes {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeTheme fills the unset colours of t from base
+func mergeTheme(base, t Theme) Theme {
+	pick := func(v, fallback string) string {
+		if v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	return Theme{
+		Name:        t.Name,
+		Base:        t.Base,
+		Primary:     pick(t.Primary, base.Primary),
+		Accent:      pick(t.Accent, base.Accent),
+		Text:        pick(t.Text, base.Text),
+		TextInverse: pick(t.TextInverse, base.TextInverse),
+		AccentText:  pick(t.AccentText, base.AccentText),
+		Muted:       pick(t.Muted, base.Muted),
+		Border:      pick(t.Border, base.Border),
+		Surface:     pick(t.Surface, base.Surface),
+		Highlight:   pick(t.Highlight, base.Highlight),
+		Success:     pick(t.Success, base.Success),
+		Failure:     pick(t.Failure, base.Failure),
+		Warning:     pick(t.Warning, base.Warning),
+		Aborted:     pick(t.Aborted, base.Aborted),
+		Running:     pick(t.Running, base.Running),
+		Unknown:     pick(t.Unknown, base.Unknown),
+	}
+}
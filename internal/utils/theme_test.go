@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// writeThemeFile writes a theme file and forgets the themes it defines once
+// the test is over
+func writeThemeFile(t *testing.T, content string, names ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "themes.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, name := range names {
+			delete(themes, name)
+		}
+	})
+	return path
+}
+
+func TestLoadThemeFileInheritsBase(t *testing.T) {
+	path := writeThemeFile(t, `
+themes:
+  - name: plain
+    primary: "#111111"
+  - name: midnight
+    base: dark
+    accent: "#222222"
+  - name: midnight-red
+    base: midnight
+    failure: "#333333"
+`, "plain", "midnight", "midnight-red")
+	if err := LoadThemeFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	def, dark := themes[DefaultThemeName], themes["dark"]
+	tests := []struct {
+		theme string
+		field string
+		got   string
+		want  string
+	}{
+		{"plain", "primary", themes["plain"].Primary, "#111111"},
+		{"plain", "text", themes["plain"].Text, def.Text},
+		{"plain", "accentText", themes["plain"].AccentText, def.AccentText},
+		{"midnight", "accent", themes["midnight"].Accent, "#222222"},
+		{"midnight", "primary", themes["midnight"].Primary, dark.Primary},
+		{"midnight-red", "failure", themes["midnight-red"].Failure, "#333333"},
+		{"midnight-red", "accent", themes["midnight-red"].Accent, "#222222"},
+		{"midnight-red", "surface", themes["midnight-red"].Surface, dark.Surface},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.theme, tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadThemeFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown base", "themes:\n  - name: broken\n    base: nope\n", `unknown base "nope"`},
+		{"built-in shadowed", "themes:\n  - name: dark\n", `"dark" is a built-in theme`},
+		{"no name", "themes:\n  - primary: \"#111111\"\n", "theme without a name"},
+		{"not yaml", "themes: [", "failed to parse theme file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadThemeFile(writeThemeFile(t, tt.content, "broken"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := LoadThemeFile(filepath.Join(t.TempDir(), "missing.yaml")); err != nil {
+		t.Errorf("missing theme file: %v", err)
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	t.Cleanup(func() { SetTheme(DefaultThemeName) })
+
+	if err := SetTheme("dark"); err != nil {
+		t.Fatal(err)
+	}
+	if CurrentTheme().Name != "dark" || ColorPrimary != lipgloss.Color(themes["dark"].Primary) {
+		t.Errorf("theme %q with primary %q after choosing dark", CurrentTheme().Name, ColorPrimary)
+	}
+
+	err := SetTheme("nope")
+	if err == nil || !strings.Contains(err.Error(), `unknown theme "nope"`) {
+		t.Errorf("error %v for an unknown theme", err)
+	}
+	if CurrentTheme().Name != "dark" {
+		t.Errorf("unknown theme replaced the current one with %q", CurrentTheme().Name)
+	}
+
+	if err := SetTheme(""); err != nil || CurrentTheme().Name != DefaultThemeName {
+		t.Errorf("no theme chose %q (%v), want the default", CurrentTheme().Name, err)
+	}
+	if ColorBlack != "#000000" {
+		t.Errorf("default text on primary is %q, want black", ColorBlack)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	t.Setenv("NO_COLOR", "")
+	honourNoColor()
+	if lipgloss.ColorProfile() != termenv.TrueColor {
+		t.Error("colours dropped without NO_COLOR")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	honourNoColor()
+	if got := StatusStyle("failure").Render("failed"); got != "failed" {
+		t.Errorf("rendered %q with NO_COLOR set", got)
+	}
+}